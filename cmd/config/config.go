@@ -16,7 +16,7 @@ func ConfigCmd() *cobra.Command {
 		Use:   "config",
 		Aliases: []string{"settings", "preferences"},
 		Short: "Configure global tmpo settings",
-		Long:  `Set up global configuration for tmpo including currency, date/time format, and timezone.`,
+		Long:  `Set up global configuration for tmpo including currency, date/time format, first day of the week, and timezone.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			ui.NewlineAbove()
 
@@ -47,6 +47,12 @@ func ConfigCmd() *cobra.Command {
 			}
 			fmt.Printf("  Time format: %s\n", ui.Muted(timeFormatDisplay))
 
+			weekStartDisplay := "(Monday)"
+			if currentConfig.WeekStart != "" {
+				weekStartDisplay = currentConfig.WeekStart
+			}
+			fmt.Printf("  Week start:  %s\n", ui.Muted(weekStartDisplay))
+
 			timezoneDisplay := "(local)"
 			if currentConfig.Timezone != "" {
 				timezoneDisplay = currentConfig.Timezone
@@ -115,6 +121,25 @@ func ConfigCmd() *cobra.Command {
 				timeFormat = currentConfig.TimeFormat
 			}
 
+			// Week start selection
+			fmt.Println()
+			weekStartOptions := []string{"Keep current", "Monday", "Sunday", "Saturday"}
+			weekStartSelect := promptui.Select{
+				Label: "Select the first day of the week",
+				Items: weekStartOptions,
+			}
+
+			_, weekStart, err := weekStartSelect.Run()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			// Keep current day if selected
+			if weekStart == "Keep current" {
+				weekStart = currentConfig.WeekStart
+			}
+
 			// Timezone prompt with validation
 			fmt.Println()
 			fmt.Println(ui.Muted("IANA timezone (e.g., America/New_York, Europe/London, Asia/Tokyo, UTC)"))
@@ -157,14 +182,14 @@ func ConfigCmd() *cobra.Command {
 				exportPath = currentConfig.ExportPath
 			}
 
-			// Create new config with updated values
-			newConfig := &settings.GlobalConfig{
-				Currency:   currencyCode,
-				DateFormat: dateFormat,
-				TimeFormat: timeFormat,
-				Timezone:   timezone,
-				ExportPath: exportPath,
-			}
+			// Update the prompted values, keeping settings the wizard doesn't cover
+			newConfig := *currentConfig
+			newConfig.Currency = currencyCode
+			newConfig.DateFormat = dateFormat
+			newConfig.TimeFormat = timeFormat
+			newConfig.WeekStart = weekStart
+			newConfig.Timezone = timezone
+			newConfig.ExportPath = exportPath
 
 			// Save the config
 			if err := newConfig.Save(); err != nil {
@@ -186,6 +211,10 @@ func ConfigCmd() *cobra.Command {
 				ui.PrintInfo(4, ui.Bold("Time format"), timeFormat)
 			}
 
+			if weekStart != "" {
+				ui.PrintInfo(4, ui.Bold("Week start"), weekStart)
+			}
+
 			if timezone != "" {
 				ui.PrintInfo(4, ui.Bold("Timezone"), timezone)
 			}
@@ -3,6 +3,8 @@ package entries
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/DylanDevelops/tmpo/internal/settings"
 	"github.com/DylanDevelops/tmpo/internal/project"
@@ -12,17 +14,61 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var showAllProjectsDelete bool
+var (
+	showAllProjectsDelete bool
+	deleteProject         string
+	deleteMilestone       string
+	deleteBefore          string
+	deleteAfter           string
+	deleteYes             bool
+	deleteDryRun          bool
+)
+
+// deletePreviewLimit is how many entries are listed before a bulk delete.
+const deletePreviewLimit = 10
 
 func DeleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Delete a time entry",
-		Long:  `Delete a time entry using an interactive menu.`,
+		Use:   "delete [id...]",
+		Short: "Delete time entries",
+		Long: `Delete a time entry using an interactive menu.
+
+Pass entry IDs, or filters such as --project and --before, to delete several entries at once without the menu. Deleted entries are moved to the trash.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			ui.NewlineAbove()
-			ui.PrintSuccess("🗑️", "Delete Time Entry")
-			fmt.Println()
+
+			filterFlags := []struct{ name, value string }{
+				{"project", deleteProject},
+				{"milestone", deleteMilestone},
+				{"before", deleteBefore},
+				{"after", deleteAfter},
+			}
+
+			// an empty filter would match every entry, so it isn't a filter
+			filtersGiven := false
+			for _, flag := range filterFlags {
+				if !cmd.Flags().Changed(flag.name) {
+					continue
+				}
+				if strings.TrimSpace(flag.value) == "" {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("--%s can't be empty", flag.name))
+					ui.NewlineBelow()
+					os.Exit(1)
+				}
+				filtersGiven = true
+			}
+
+			if len(args) > 0 && filtersGiven {
+				ui.PrintError(ui.EmojiError, "pass either entry IDs or filters, not both")
+				os.Exit(1)
+			}
+
+			if len(args) == 0 && !filtersGiven && !ui.IsInteractive() {
+				ui.PrintError(ui.EmojiError, "no entries given")
+				ui.PrintMuted(0, "Pass entry IDs or filters such as --project and --before, or run 'tmpo delete' in a terminal")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
 
 			db, err := storage.Initialize()
 			if err != nil {
@@ -31,6 +77,24 @@ func DeleteCmd() *cobra.Command {
 			}
 			defer db.Close()
 
+			if len(args) > 0 || filtersGiven {
+				ui.PrintSuccess(ui.EmojiTrash, "Delete Time Entries")
+				fmt.Println()
+
+				var entries []*storage.TimeEntry
+				if len(args) > 0 {
+					entries = entriesByID(db, args)
+				} else {
+					entries = entriesByFilter(db)
+				}
+
+				deleteEntries(db, entries)
+				return
+			}
+
+			ui.PrintSuccess("🗑️", "Delete Time Entry")
+			fmt.Println()
+
 			var entries []*storage.TimeEntry
 			var projectName string
 
@@ -162,12 +226,19 @@ func DeleteCmd() *cobra.Command {
 			}
 
 			fmt.Println()
-			ui.PrintSuccess(ui.EmojiSuccess, "Entry deleted successfully")
+			ui.PrintSuccess(ui.EmojiSuccess, "Entry moved to the trash")
+			ui.PrintMuted(4, "└─ Use 'tmpo trash restore' or 'tmpo undo' to bring it back")
 			ui.NewlineBelow()
 		},
 	}
 
 	cmd.Flags().BoolVar(&showAllProjectsDelete, "show-all-projects", false, "Show project selection before entry selection")
+	cmd.Flags().StringVarP(&deleteProject, "project", "p", "", "Delete entries of this project")
+	cmd.Flags().StringVarP(&deleteMilestone, "milestone", "m", "", "Delete entries of this milestone")
+	cmd.Flags().StringVar(&deleteBefore, "before", "", "Delete entries that started before this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&deleteAfter, "after", "", "Delete entries that started on or after this date (YYYY-MM-DD)")
+	cmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Skip the confirmation prompt")
+	cmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "Show what would be deleted without deleting anything")
 
 	return cmd
 }
@@ -198,3 +269,180 @@ func formatEntryLabelForDelete(entry *storage.TimeEntry) string {
 
 	return fmt.Sprintf("%s → %s (%s) - %s", startStr, endStr, durationStr, description)
 }
+
+// entriesByID looks up the entries named on the command line, failing if any
+// of them doesn't exist.
+func entriesByID(db *storage.Database, args []string) []*storage.TimeEntry {
+	var entries []*storage.TimeEntry
+	seen := make(map[int64]bool)
+
+	for _, arg := range args {
+		id, err := parseEntryID(arg)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entry, err := db.GetEntry(id)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("entry #%d not found", id))
+			os.Exit(1)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// entriesByFilter returns the entries matching the filter flags.
+func entriesByFilter(db *storage.Database) []*storage.TimeEntry {
+	filter := storage.EntryFilter{ProjectName: strings.TrimSpace(deleteProject)}
+
+	if milestoneName := strings.TrimSpace(deleteMilestone); milestoneName != "" {
+		if filter.ProjectName == "" {
+			detectedProject, err := project.DetectConfiguredProject()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
+				os.Exit(1)
+			}
+			filter.ProjectName = detectedProject
+		}
+		filter.MilestoneName = milestoneName
+	}
+
+	var err error
+	if strings.TrimSpace(deleteAfter) != "" {
+		if filter.Start, err = parseDateFlag(deleteAfter); err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("--after: %v", err))
+			os.Exit(1)
+		}
+	}
+
+	if strings.TrimSpace(deleteBefore) != "" {
+		if filter.End, err = parseDateFlag(deleteBefore); err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("--before: %v", err))
+			os.Exit(1)
+		}
+	}
+
+	if !filter.Start.IsZero() && !filter.End.IsZero() && !filter.End.After(filter.Start) {
+		ui.PrintError(ui.EmojiError, "--before must be later than --after")
+		os.Exit(1)
+	}
+
+	if filter.ProjectName == "" && filter.MilestoneName == "" && filter.Start.IsZero() && filter.End.IsZero() {
+		ui.PrintError(ui.EmojiError, "no filters given")
+		ui.PrintMuted(0, "Pass at least one of --project, --milestone, --before or --after")
+		ui.NewlineBelow()
+		os.Exit(1)
+	}
+
+	entries, err := db.GetEntriesByFilter(filter)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	return entries
+}
+
+// deleteEntries previews the entries, asks for confirmation unless --yes is
+// set and moves them to the trash as a single undoable change.
+func deleteEntries(db *storage.Database, entries []*storage.TimeEntry) {
+	if len(entries) == 0 {
+		ui.PrintWarning(ui.EmojiWarning, "No entries match")
+		ui.NewlineBelow()
+		return
+	}
+
+	var total time.Duration
+	for _, entry := range entries {
+		total += entry.Duration()
+	}
+
+	ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("%d %s (%s) will be moved to the trash:", len(entries), pluralize(len(entries), "entry", "entries"), ui.FormatDuration(total)))
+	fmt.Println()
+
+	for i, entry := range entries {
+		if i == deletePreviewLimit {
+			ui.PrintMuted(4, fmt.Sprintf("... and %d more", len(entries)-deletePreviewLimit))
+			break
+		}
+		fmt.Printf("    %s %s %s\n", ui.Muted(fmt.Sprintf("#%d", entry.ID)), ui.Bold(entry.ProjectName), formatEntryLabelForDelete(entry))
+	}
+	fmt.Println()
+
+	if deleteDryRun {
+		ui.PrintMuted(0, "Dry run: nothing was deleted")
+		ui.NewlineBelow()
+		return
+	}
+
+	if !deleteYes {
+		if !ui.IsInteractive() {
+			ui.PrintError(ui.EmojiError, "confirmation required")
+			ui.PrintMuted(0, "Pass --yes to delete without a terminal, or --dry-run to only preview")
+			ui.NewlineBelow()
+			os.Exit(1)
+		}
+
+		confirmPrompt := promptui.Select{
+			Label: fmt.Sprintf("Delete %d %s?", len(entries), pluralize(len(entries), "entry", "entries")),
+			Items: []string{"No", "Yes"},
+		}
+
+		_, result, err := confirmPrompt.Run()
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+
+		if result == "No" {
+			ui.PrintWarning(ui.EmojiWarning, "Deletion cancelled")
+			ui.NewlineBelow()
+			os.Exit(0)
+		}
+	}
+
+	ids := make([]int64, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+
+	if err := db.DeleteTimeEntries(ids); err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Moved %d %s to the trash", len(entries), pluralize(len(entries), "entry", "entries")))
+	ui.PrintMuted(4, "└─ Use 'tmpo undo' to bring them all back")
+	ui.NewlineBelow()
+}
+
+// parseDateFlag parses a YYYY-MM-DD date, or "today", "yesterday" or
+// "tomorrow", as local midnight.
+func parseDateFlag(value string) (time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s', use YYYY-MM-DD, today, yesterday or tomorrow", value)
+	}
+	return date, nil
+}
@@ -0,0 +1,99 @@
+package entries
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var duplicateDate string
+
+func DuplicateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "duplicate [id]",
+		Short: "Copy a time entry to another day",
+		Long:  `Copy a completed time entry to another day, keeping its time of day, length, project, description, rate and milestone. Useful for recurring work such as a daily standup.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			id, err := parseEntryID(args[0])
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			day, err := parseDateFlag(duplicateDate)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("--date: %v", err))
+				os.Exit(1)
+			}
+
+			globalCfg, err := settings.LoadGlobalConfig()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("loading config: %v", err))
+				os.Exit(1)
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			entry, err := db.GetEntry(id)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("entry #%d not found", id))
+				os.Exit(1)
+			}
+
+			if entry.IsRunning() {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("entry #%d is still running", entry.ID))
+				ui.PrintMuted(0, "Use 'tmpo stop' to finish it first")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			start := onDay(day, entry.StartTime)
+			end := start.Add(entry.Duration())
+
+			start, end, carve, ok := resolveOverlaps(db, start, end, 0, !globalCfg.RejectsOverlaps())
+			if !ok {
+				ui.PrintWarning(ui.EmojiWarning, "Duplicate cancelled")
+				ui.NewlineBelow()
+				os.Exit(0)
+			}
+
+			duplicateEntry := db.DuplicateEntry
+			if carve {
+				duplicateEntry = db.DuplicateEntryCarving
+			}
+
+			duplicate, err := duplicateEntry(entry.ID, start, end)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Duplicated entry #%d as #%d", entry.ID, duplicate.ID))
+			fmt.Printf("    %s %s %s\n", ui.Muted(fmt.Sprintf("#%d", duplicate.ID)), ui.Bold(duplicate.ProjectName), formatEntryLabelForDelete(duplicate))
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().StringVar(&duplicateDate, "date", "today", "Day to copy the entry to (YYYY-MM-DD, today, yesterday or tomorrow)")
+
+	return cmd
+}
+
+// onDay returns the time of day of t on the given day.
+func onDay(day, t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
+}
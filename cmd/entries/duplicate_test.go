@@ -0,0 +1,36 @@
+package entries
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnDay(t *testing.T) {
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local)
+	entryStart := time.Date(2024, 2, 28, 9, 15, 30, 0, time.Local)
+
+	got := onDay(day, entryStart)
+	assert.True(t, time.Date(2024, 3, 5, 9, 15, 30, 0, time.Local).Equal(got), "got %v", got)
+}
+
+func TestParseDateFlag(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	got, err := parseDateFlag("2024-03-05")
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local).Equal(got))
+
+	got, err = parseDateFlag("Tomorrow")
+	assert.NoError(t, err)
+	assert.True(t, today.AddDate(0, 0, 1).Equal(got))
+
+	got, err = parseDateFlag("yesterday")
+	assert.NoError(t, err)
+	assert.True(t, today.AddDate(0, 0, -1).Equal(got))
+
+	_, err = parseDateFlag("next week")
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package entries
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,15 +15,65 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var showAllProjects bool
+var (
+	showAllProjects bool
+	editStart       string
+	editEnd         string
+	editDescription string
+	editProject     string
+	editMilestone   string
+	editRate        string
+)
+
+// editFlags are the flags that edit an entry without prompts.
+var editFlags = []string{"start", "end", "description", "project", "milestone", "rate"}
 
 func EditCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "edit",
+		Use:   "edit [id]",
 		Short: "Edit an existing time entry",
-		Long:  `Edit an existing time entry using an interactive menu.`,
+		Long: `Edit an existing time entry using an interactive menu.
+
+Pass an entry ID and one or more flags to edit it without prompts, for example from a script:
+
+  tmpo edit 42 --end "01-15-2024 5:30 PM" --description "Client call"
+
+Times take your configured date format followed by a time, or just a time to keep the current date. Pass an empty value to clear the description, milestone or rate.`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			ui.NewlineAbove()
+
+			flagsGiven := false
+			for _, name := range editFlags {
+				if cmd.Flags().Changed(name) {
+					flagsGiven = true
+				}
+			}
+
+			if flagsGiven && len(args) == 0 {
+				ui.PrintError(ui.EmojiError, "an entry ID is required when editing with flags")
+				ui.PrintMuted(0, "Use 'tmpo log' to find the entry, then 'tmpo edit <id> --end ...'")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			if !flagsGiven && !ui.IsInteractive() {
+				ui.PrintError(ui.EmojiError, "no changes given")
+				ui.PrintMuted(0, "Pass flags such as --start, --end or --description, or run 'tmpo edit' in a terminal")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			var entryID int64
+			if len(args) > 0 {
+				id, err := parseEntryID(args[0])
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+				entryID = id
+			}
+
 			ui.PrintSuccess("✏️", "Edit Time Entry")
 			fmt.Println()
 
@@ -43,93 +94,30 @@ func EditCmd() *cobra.Command {
 			}
 			defer db.Close()
 
-			var entries []*storage.TimeEntry
-			var projectName string
-
-			if showAllProjects {
-				// Show project selection first
-				projects, err := db.GetProjectsWithCompletedEntries()
+			var selectedEntry *storage.TimeEntry
+			if entryID != 0 {
+				selectedEntry, err = db.GetEntry(entryID)
 				if err != nil {
-					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
-					os.Exit(1)
-				}
-
-				if len(projects) == 0 {
-					ui.PrintError(ui.EmojiError, "No completed time entries found")
-					ui.NewlineBelow()
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("entry #%d not found", entryID))
 					os.Exit(1)
 				}
 
-				projectPrompt := promptui.Select{
-					Label: "Select project",
-					Items: projects,
+				if flagsGiven {
+					editWithFlags(cmd, db, selectedEntry, globalCfg, dateFormatLayout, dateFormatDisplay)
+					return
 				}
 
-				_, selectedProject, err := projectPrompt.Run()
-				if err != nil {
-					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				if selectedEntry.IsRunning() {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("entry #%d is still running", entryID))
+					ui.PrintMuted(0, "Use 'tmpo stop' first, or edit it with flags such as --start or --description")
+					ui.NewlineBelow()
 					os.Exit(1)
 				}
-
-				projectName = selectedProject
 			} else {
-				// Use current project
-				detectedProject, err := project.DetectConfiguredProject()
-				if err != nil {
-					ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
-					os.Exit(1)
-				}
-				projectName = detectedProject
+				selectedEntry = selectEntryToEdit(db)
 			}
 
-			// Get completed entries for the selected/detected project
-			entries, err = db.GetCompletedEntriesByProject(projectName)
-			if err != nil {
-				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
-				os.Exit(1)
-			}
-
-			if len(entries) == 0 {
-				ui.PrintError(ui.EmojiError, fmt.Sprintf("No completed time entries found for project '%s'", projectName))
-				if !showAllProjects {
-					ui.PrintMuted(0, "Use 'tmpo edit --show-all-projects' to see entries from all projects")
-				}
-				ui.NewlineBelow()
-				os.Exit(1)
-			}
-
-			// Format entries for selection
-			templates := &promptui.SelectTemplates{
-				Label:    "{{ . }}",
-				Active:   "▸ {{ .Label }}",
-				Inactive: "  {{ .Label }}",
-				Selected: "{{ .Label }}",
-			}
-
-			type entryItem struct {
-				Label string
-				Entry *storage.TimeEntry
-			}
-
-			var items []entryItem
-			for _, entry := range entries {
-				label := formatEntryLabel(entry)
-				items = append(items, entryItem{Label: label, Entry: entry})
-			}
-
-			entryPrompt := promptui.Select{
-				Label:     "Select entry to edit",
-				Items:     items,
-				Templates: templates,
-			}
-
-			idx, _, err := entryPrompt.Run()
-			if err != nil {
-				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
-				os.Exit(1)
-			}
-
-			selectedEntry := items[idx].Entry
+			projectName := selectedEntry.ProjectName
 
 			editedEntry := &storage.TimeEntry{
 				ID:            selectedEntry.ID,
@@ -311,27 +299,8 @@ func EditCmd() *cobra.Command {
 			if newMilestoneName != nil {
 				milestone, err := db.GetMilestoneByName(projectName, *newMilestoneName)
 				if err == nil && milestone != nil {
-					entryOutsideRange := false
-					warningMsg := ""
-
-					if newStartTime.Before(milestone.StartTime) {
-						entryOutsideRange = true
-						warningMsg = fmt.Sprintf("Entry starts (%s) before milestone began (%s)",
-							settings.FormatDateTimeDashed(newStartTime),
-							settings.FormatDateTimeDashed(milestone.StartTime))
-					} else if milestone.EndTime != nil && newStartTime.After(*milestone.EndTime) {
-						entryOutsideRange = true
-						warningMsg = fmt.Sprintf("Entry starts (%s) after milestone ended (%s)",
-							settings.FormatDateTimeDashed(newStartTime),
-							settings.FormatDateTimeDashed(*milestone.EndTime))
-					}
-
-					if entryOutsideRange {
-						fmt.Println()
-						ui.PrintWarning(ui.EmojiWarning, "Entry not within milestone timeframe")
-						ui.PrintMuted(0, warningMsg)
-						ui.PrintMuted(0, "This is allowed - milestones are organizational tags and work with any date range.")
-						fmt.Println()
+					if warningMsg := milestoneTimeframeWarning(milestone, newStartTime); warningMsg != "" {
+						printMilestoneTimeframeWarning(warningMsg)
 
 						confirmPrompt := promptui.Select{
 							Label: "Assign this entry to the milestone?",
@@ -358,46 +327,7 @@ func EditCmd() *cobra.Command {
 			ui.PrintInfo(0, ui.Bold("Changes to entry"), fmt.Sprintf("#%d", selectedEntry.ID))
 			fmt.Println()
 
-			hasChanges := false
-
-			selectedStartTrunc := selectedEntry.StartTime.Truncate(time.Minute)
-			editedStartTrunc := editedEntry.StartTime.Truncate(time.Minute)
-
-			if !selectedStartTrunc.Equal(editedStartTrunc) {
-				hasChanges = true
-				oldStr := settings.FormatDateTimeDashed(selectedEntry.StartTime)
-				newStr := settings.FormatDateTimeDashed(editedEntry.StartTime)
-				fmt.Printf("    %s %s → %s\n", ui.Bold("Start time:"), ui.Muted(oldStr), newStr)
-			}
-
-			selectedEndTrunc := selectedEntry.EndTime.Truncate(time.Minute)
-			editedEndTrunc := editedEntry.EndTime.Truncate(time.Minute)
-
-			if !selectedEndTrunc.Equal(editedEndTrunc) {
-				hasChanges = true
-				oldStr := settings.FormatDateTimeDashed(*selectedEntry.EndTime)
-				newStr := settings.FormatDateTimeDashed(*editedEntry.EndTime)
-				fmt.Printf("    %s %s → %s\n", ui.Bold("End time:"), ui.Muted(oldStr), newStr)
-			}
-
-			if selectedEntry.Description != editedEntry.Description {
-				hasChanges = true
-				fmt.Printf("    %s %s → %s\n", ui.Bold("Description:"), ui.Muted(fmt.Sprintf("%q", selectedEntry.Description)), fmt.Sprintf("%q", editedEntry.Description))
-			}
-
-			// check if the milestone has changed
-			oldMilestone := "(None)"
-			if selectedEntry.MilestoneName != nil {
-				oldMilestone = *selectedEntry.MilestoneName
-			}
-			newMilestone := "(None)"
-			if editedEntry.MilestoneName != nil {
-				newMilestone = *editedEntry.MilestoneName
-			}
-			if oldMilestone != newMilestone {
-				hasChanges = true
-				fmt.Printf("    %s %s → %s\n", ui.Bold("Milestone:"), ui.Muted(oldMilestone), newMilestone)
-			}
+			hasChanges := printEntryChanges(selectedEntry, editedEntry)
 
 			if !hasChanges {
 				ui.PrintWarning(ui.EmojiWarning, "No changes detected")
@@ -424,8 +354,22 @@ func EditCmd() *cobra.Command {
 				os.Exit(0)
 			}
 
+			newStart, newEnd, carve, ok := resolveOverlaps(db, editedEntry.StartTime, *editedEntry.EndTime, editedEntry.ID, !globalCfg.RejectsOverlaps())
+			if !ok {
+				ui.PrintWarning(ui.EmojiWarning, "Changes discarded")
+				ui.NewlineBelow()
+				os.Exit(0)
+			}
+			editedEntry.StartTime = newStart
+			editedEntry.EndTime = &newEnd
+
 			// Save to database
-			if err := db.UpdateTimeEntry(editedEntry.ID, editedEntry); err != nil {
+			updateTimeEntry := db.UpdateTimeEntry
+			if carve {
+				updateTimeEntry = db.UpdateTimeEntryCarving
+			}
+
+			if err := updateTimeEntry(editedEntry.ID, editedEntry); err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
 				os.Exit(1)
 			}
@@ -437,10 +381,376 @@ func EditCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&showAllProjects, "show-all-projects", false, "Show project selection before entry selection")
+	cmd.Flags().StringVar(&editStart, "start", "", "New start date and time, or just a time to keep the date")
+	cmd.Flags().StringVar(&editEnd, "end", "", "New end date and time, or just a time to keep the date")
+	cmd.Flags().StringVar(&editDescription, "description", "", "New description")
+	cmd.Flags().StringVar(&editProject, "project", "", "Move the entry to another project")
+	cmd.Flags().StringVar(&editMilestone, "milestone", "", "Assign to a milestone of the entry's project")
+	cmd.Flags().StringVar(&editRate, "rate", "", "New hourly rate")
 
 	return cmd
 }
 
+// selectEntryToEdit asks for a completed entry of the current project, or of
+// a chosen project with --show-all-projects.
+func selectEntryToEdit(db *storage.Database) *storage.TimeEntry {
+	var projectName string
+
+	if showAllProjects {
+		// Show project selection first
+		projects, err := db.GetProjectsWithCompletedEntries()
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+
+		if len(projects) == 0 {
+			ui.PrintError(ui.EmojiError, "No completed time entries found")
+			ui.NewlineBelow()
+			os.Exit(1)
+		}
+
+		projectPrompt := promptui.Select{
+			Label: "Select project",
+			Items: projects,
+		}
+
+		_, selectedProject, err := projectPrompt.Run()
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+
+		projectName = selectedProject
+	} else {
+		// Use current project
+		detectedProject, err := project.DetectConfiguredProject()
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
+			os.Exit(1)
+		}
+		projectName = detectedProject
+	}
+
+	// Get completed entries for the selected/detected project
+	entries, err := db.GetCompletedEntriesByProject(projectName)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	if len(entries) == 0 {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("No completed time entries found for project '%s'", projectName))
+		if !showAllProjects {
+			ui.PrintMuted(0, "Use 'tmpo edit --show-all-projects' to see entries from all projects")
+		}
+		ui.NewlineBelow()
+		os.Exit(1)
+	}
+
+	// Format entries for selection
+	templates := &promptui.SelectTemplates{
+		Label:    "{{ . }}",
+		Active:   "▸ {{ .Label }}",
+		Inactive: "  {{ .Label }}",
+		Selected: "{{ .Label }}",
+	}
+
+	type entryItem struct {
+		Label string
+		Entry *storage.TimeEntry
+	}
+
+	var items []entryItem
+	for _, entry := range entries {
+		label := formatEntryLabel(entry)
+		items = append(items, entryItem{Label: label, Entry: entry})
+	}
+
+	entryPrompt := promptui.Select{
+		Label:     "Select entry to edit",
+		Items:     items,
+		Templates: templates,
+	}
+
+	idx, _, err := entryPrompt.Run()
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	return items[idx].Entry
+}
+
+// editWithFlags applies the edit flags to entry and saves it without
+// prompting, validating the values the same way as the interactive flow.
+func editWithFlags(cmd *cobra.Command, db *storage.Database, entry *storage.TimeEntry, globalCfg *settings.GlobalConfig, dateLayout, dateDisplay string) {
+	edited := *entry
+	flags := cmd.Flags()
+
+	if flags.Changed("start") {
+		start, err := parseDateTimeFlag(editStart, entry.StartTime, dateLayout, dateDisplay)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("--start: %v", err))
+			os.Exit(1)
+		}
+		edited.StartTime = start
+	}
+
+	if flags.Changed("end") {
+		if entry.IsRunning() {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("entry #%d is still running", entry.ID))
+			ui.PrintMuted(0, "Use 'tmpo stop' to set its end time")
+			ui.NewlineBelow()
+			os.Exit(1)
+		}
+
+		end, err := parseDateTimeFlag(editEnd, *entry.EndTime, dateLayout, dateDisplay)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("--end: %v", err))
+			os.Exit(1)
+		}
+		edited.EndTime = &end
+	}
+
+	if edited.EndTime != nil && !edited.EndTime.After(edited.StartTime) {
+		ui.PrintError(ui.EmojiError, "end time must be after start time")
+		os.Exit(1)
+	}
+
+	if flags.Changed("description") {
+		edited.Description = strings.TrimSpace(editDescription)
+	}
+
+	if flags.Changed("project") {
+		edited.ProjectName = strings.TrimSpace(editProject)
+		if edited.ProjectName == "" {
+			ui.PrintError(ui.EmojiError, "project name cannot be empty")
+			os.Exit(1)
+		}
+	}
+
+	if flags.Changed("milestone") {
+		edited.MilestoneName = nil
+		if name := strings.TrimSpace(editMilestone); name != "" {
+			edited.MilestoneName = &name
+		}
+	}
+
+	if edited.MilestoneName != nil {
+		milestone, err := db.GetMilestoneByName(edited.ProjectName, *edited.MilestoneName)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+
+		if milestone == nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("milestone '%s' not found in project '%s'", *edited.MilestoneName, edited.ProjectName))
+			if !flags.Changed("milestone") {
+				ui.PrintMuted(0, "Pass --milestone to reassign the entry, or --milestone \"\" to remove it")
+			}
+			ui.NewlineBelow()
+			os.Exit(1)
+		}
+
+		if warningMsg := milestoneTimeframeWarning(milestone, edited.StartTime); warningMsg != "" {
+			fmt.Println()
+			ui.PrintWarning(ui.EmojiWarning, "Entry not within milestone timeframe")
+			ui.PrintMuted(0, warningMsg)
+		}
+	}
+
+	if flags.Changed("rate") {
+		rate, err := parseRate(editRate)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("--rate: %v", err))
+			os.Exit(1)
+		}
+		edited.HourlyRate = rate
+	}
+
+	ui.PrintInfo(0, ui.Bold("Changes to entry"), fmt.Sprintf("#%d", entry.ID))
+	fmt.Println()
+
+	if !printEntryChanges(entry, &edited) {
+		ui.PrintWarning(ui.EmojiWarning, "No changes detected")
+		ui.NewlineBelow()
+		return
+	}
+
+	updateTimeEntry := db.UpdateTimeEntry
+	if edited.EndTime != nil {
+		start, end, carve, ok := resolveOverlaps(db, edited.StartTime, *edited.EndTime, edited.ID, !globalCfg.RejectsOverlaps())
+		if !ok {
+			ui.PrintWarning(ui.EmojiWarning, "Changes discarded")
+			ui.NewlineBelow()
+			os.Exit(0)
+		}
+		edited.StartTime = start
+		edited.EndTime = &end
+
+		if carve {
+			updateTimeEntry = db.UpdateTimeEntryCarving
+		}
+	}
+
+	if err := updateTimeEntry(edited.ID, &edited); err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	ui.PrintSuccess(ui.EmojiSuccess, "Entry updated successfully")
+	ui.NewlineBelow()
+}
+
+// parseDateTimeFlag parses a "<date> <time>" flag value in the configured
+// date format or as YYYY-MM-DD, or an RFC 3339 timestamp. A value with only
+// a time keeps the date of current.
+func parseDateTimeFlag(value string, current time.Time, dateLayout, dateDisplay string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		if t.After(time.Now().Add(24 * time.Hour)) {
+			return time.Time{}, fmt.Errorf("date cannot be in the future")
+		}
+		return t.Local(), nil
+	}
+
+	date := current.Format(dateLayout)
+	timeStr := value
+
+	if first, rest, found := strings.Cut(value, " "); found && validateTime(value) != nil {
+		date, timeStr = first, strings.TrimSpace(rest)
+
+		// ISO dates are unambiguous, so accept them whatever the configured format
+		if iso, err := time.Parse("2006-01-02", date); err == nil {
+			date = iso.Format(dateLayout)
+		}
+	}
+
+	if err := validateDate(date, dateLayout, dateDisplay); err != nil {
+		return time.Time{}, err
+	}
+
+	if err := validateTime(timeStr); err != nil {
+		return time.Time{}, err
+	}
+
+	return parseDateTime(date, timeStr, dateLayout)
+}
+
+// parseRate parses an hourly rate flag. An empty value clears the rate.
+func parseRate(value string) (*float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hourly rate '%s'", value)
+	}
+
+	if rate < 0 {
+		return nil, fmt.Errorf("hourly rate cannot be negative")
+	}
+
+	return &rate, nil
+}
+
+func parseEntryID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid entry ID '%s'", arg)
+	}
+	return id, nil
+}
+
+// milestoneTimeframeWarning explains why an entry starting at start falls
+// outside the milestone, or returns "" when it doesn't.
+func milestoneTimeframeWarning(milestone *storage.Milestone, start time.Time) string {
+	if start.Before(milestone.StartTime) {
+		return fmt.Sprintf("Entry starts (%s) before milestone began (%s)",
+			settings.FormatDateTimeDashed(start),
+			settings.FormatDateTimeDashed(milestone.StartTime))
+	}
+
+	if milestone.EndTime != nil && start.After(*milestone.EndTime) {
+		return fmt.Sprintf("Entry starts (%s) after milestone ended (%s)",
+			settings.FormatDateTimeDashed(start),
+			settings.FormatDateTimeDashed(*milestone.EndTime))
+	}
+
+	return ""
+}
+
+func printMilestoneTimeframeWarning(warningMsg string) {
+	fmt.Println()
+	ui.PrintWarning(ui.EmojiWarning, "Entry not within milestone timeframe")
+	ui.PrintMuted(0, warningMsg)
+	ui.PrintMuted(0, "This is allowed - milestones are organizational tags and work with any date range.")
+	fmt.Println()
+}
+
+// printEntryChanges prints a diff of the fields that differ between before
+// and after, and reports whether there were any.
+func printEntryChanges(before, after *storage.TimeEntry) bool {
+	hasChanges := false
+
+	if !before.StartTime.Truncate(time.Minute).Equal(after.StartTime.Truncate(time.Minute)) {
+		hasChanges = true
+		oldStr := settings.FormatDateTimeDashed(before.StartTime)
+		newStr := settings.FormatDateTimeDashed(after.StartTime)
+		fmt.Printf("    %s %s → %s\n", ui.Bold("Start time:"), ui.Muted(oldStr), newStr)
+	}
+
+	if before.EndTime != nil && after.EndTime != nil && !before.EndTime.Truncate(time.Minute).Equal(after.EndTime.Truncate(time.Minute)) {
+		hasChanges = true
+		oldStr := settings.FormatDateTimeDashed(*before.EndTime)
+		newStr := settings.FormatDateTimeDashed(*after.EndTime)
+		fmt.Printf("    %s %s → %s\n", ui.Bold("End time:"), ui.Muted(oldStr), newStr)
+	}
+
+	if before.ProjectName != after.ProjectName {
+		hasChanges = true
+		fmt.Printf("    %s %s → %s\n", ui.Bold("Project:"), ui.Muted(before.ProjectName), after.ProjectName)
+	}
+
+	if before.Description != after.Description {
+		hasChanges = true
+		fmt.Printf("    %s %s → %s\n", ui.Bold("Description:"), ui.Muted(fmt.Sprintf("%q", before.Description)), fmt.Sprintf("%q", after.Description))
+	}
+
+	// check if the milestone has changed
+	oldMilestone := "(None)"
+	if before.MilestoneName != nil {
+		oldMilestone = *before.MilestoneName
+	}
+	newMilestone := "(None)"
+	if after.MilestoneName != nil {
+		newMilestone = *after.MilestoneName
+	}
+	if oldMilestone != newMilestone {
+		hasChanges = true
+		fmt.Printf("    %s %s → %s\n", ui.Bold("Milestone:"), ui.Muted(oldMilestone), newMilestone)
+	}
+
+	oldRate := "(None)"
+	if before.HourlyRate != nil {
+		oldRate = fmt.Sprintf("%.2f", *before.HourlyRate)
+	}
+	newRate := "(None)"
+	if after.HourlyRate != nil {
+		newRate = fmt.Sprintf("%.2f", *after.HourlyRate)
+	}
+	if oldRate != newRate {
+		hasChanges = true
+		fmt.Printf("    %s %s → %s\n", ui.Bold("Hourly rate:"), ui.Muted(oldRate), newRate)
+	}
+
+	return hasChanges
+}
+
 func formatEntryLabel(entry *storage.TimeEntry) string {
 	startStr := settings.FormatDateTimeDashed(entry.StartTime)
 	endStr := settings.FormatTime(*entry.EndTime)
@@ -0,0 +1,101 @@
+package entries
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDateTimeFlag(t *testing.T) {
+	current := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "date and 12-hour time",
+			input: "03-05-2024 2:30 PM",
+			want:  time.Date(2024, 3, 5, 14, 30, 0, 0, time.Local),
+		},
+		{
+			name:  "date and 24-hour time",
+			input: "03-05-2024 14:30",
+			want:  time.Date(2024, 3, 5, 14, 30, 0, 0, time.Local),
+		},
+		{
+			name:  "time only keeps the current date",
+			input: "5:15 pm",
+			want:  time.Date(2024, 3, 4, 17, 15, 0, 0, time.Local),
+		},
+		{
+			name:  "ISO date",
+			input: "2024-03-05 14:30",
+			want:  time.Date(2024, 3, 5, 14, 30, 0, 0, time.Local),
+		},
+		{
+			name:  "RFC 3339 timestamp",
+			input: "2024-03-05T14:30:00Z",
+			want:  time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid date",
+			input:   "2024/03/05 14:30",
+			wantErr: true,
+		},
+		{
+			name:    "missing time",
+			input:   "03-05-2024",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDateTimeFlag(tt.input, current, "01-02-2006", "MM-DD-YYYY")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %v", got)
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	rate, err := parseRate("87.5")
+	assert.NoError(t, err)
+	assert.Equal(t, 87.5, *rate)
+
+	rate, err = parseRate("  ")
+	assert.NoError(t, err)
+	assert.Nil(t, rate)
+
+	_, err = parseRate("-1")
+	assert.Error(t, err)
+
+	_, err = parseRate("abc")
+	assert.Error(t, err)
+}
+
+func TestParseEntryID(t *testing.T) {
+	id, err := parseEntryID("42")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+
+	_, err = parseEntryID("0")
+	assert.Error(t, err)
+
+	_, err = parseEntryID("abc")
+	assert.Error(t, err)
+}
@@ -29,15 +29,27 @@ func getDateFormatInfo(configFormat string) (displayFormat, layout string) {
 	}
 }
 
+var (
+	manualProject     string
+	manualStart       string
+	manualEnd         string
+	manualDescription string
+	manualMilestone   string
+	manualRate        string
+	manualFromFile    string
+)
+
+// manualFlags are the flags that create an entry without prompts.
+var manualFlags = []string{"project", "start", "end", "description", "milestone", "rate"}
+
 func ManualCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "manual",
 		Short: "Create a manual time entry",
-		Long:  `Create a completed time entry by specifying start and end times using an interactive menu.`,
+		Long:  `Create a completed time entry by specifying start and end times using an interactive menu, with flags, or in bulk from a CSV file.`,
+		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			ui.NewlineAbove()
-			ui.PrintSuccess(ui.EmojiManual, "Create Manual Time Entry")
-			fmt.Println()
 
 			// Load global config to get date format preference
 			globalCfg, err := settings.LoadGlobalConfig()
@@ -49,6 +61,37 @@ func ManualCmd() *cobra.Command {
 			// Get date format for prompts and validation
 			dateFormatDisplay, dateFormatLayout := getDateFormatInfo(globalCfg.DateFormat)
 
+			if cmd.Flags().Changed("from-file") {
+				for _, name := range manualFlags {
+					if name != "project" && cmd.Flags().Changed(name) {
+						ui.PrintError(ui.EmojiError, fmt.Sprintf("--%s can't be combined with --from-file", name))
+						ui.PrintMuted(0, "Put the value in a column of the file instead")
+						ui.NewlineBelow()
+						os.Exit(1)
+					}
+				}
+
+				importManualEntries(manualFromFile, globalCfg, dateFormatLayout, dateFormatDisplay)
+				return
+			}
+
+			for _, name := range manualFlags {
+				if cmd.Flags().Changed(name) {
+					createManualFromFlags(cmd, globalCfg, dateFormatLayout, dateFormatDisplay)
+					return
+				}
+			}
+
+			if !ui.IsInteractive() {
+				ui.PrintError(ui.EmojiError, "no terminal to prompt in")
+				ui.PrintMuted(0, "Pass --start and --end to create the entry with flags, or --from-file to import a CSV file")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiManual, "Create Manual Time Entry")
+			fmt.Println()
+
 			defaultProject := detectProjectNameWithSource()
 
 			var projectLabel string
@@ -193,6 +236,15 @@ func ManualCmd() *cobra.Command {
 					Items: milestoneOptions,
 				}
 
+				// Preselect the milestone that was running when the entry started
+				if suggested := suggestMilestone(milestones, startTime); suggested != nil {
+					for i, m := range milestones {
+						if m.ID == suggested.ID {
+							milestonePrompt.CursorPos = i + 1
+						}
+					}
+				}
+
 				milestoneIdx, _, err := milestonePrompt.Run()
 				if err != nil {
 					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
@@ -206,44 +258,199 @@ func ManualCmd() *cobra.Command {
 				}
 			}
 
-			entry, err := db.CreateManualEntry(projectName, description, startTime, endTime, hourlyRate, milestoneName)
+			startTime, endTime, carve, ok := resolveOverlaps(db, startTime, endTime, 0, !globalCfg.RejectsOverlaps())
+			if !ok {
+				ui.PrintWarning(ui.EmojiWarning, "Manual entry cancelled")
+				ui.NewlineBelow()
+				os.Exit(0)
+			}
+
+			createManualEntry := db.CreateManualEntry
+			if carve {
+				createManualEntry = db.CreateManualEntryCarving
+			}
+
+			entry, err := createManualEntry(projectName, description, startTime, endTime, hourlyRate, milestoneName)
 			if err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
 				os.Exit(1)
 			}
 
-			duration := entry.Duration()
 			fmt.Println()
-			ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Created manual entry for %s", ui.Bold(entry.ProjectName)))
-			ui.PrintInfo(4, ui.Bold("Start"), settings.FormatDateTimeLong(startTime))
-			ui.PrintInfo(4, ui.Bold("End"), settings.FormatDateTimeLong(endTime))
-			ui.PrintInfo(4, ui.Bold("Duration"), ui.FormatDuration(duration))
+			printCreatedEntry(entry)
+			ui.NewlineBelow()
+		},
+	}
 
-			if entry.Description != "" {
-				ui.PrintInfo(4, ui.Bold("Description"), entry.Description)
-			}
+	cmd.Flags().StringVarP(&manualProject, "project", "p", "", "Project name (defaults to the detected project)")
+	cmd.Flags().StringVar(&manualStart, "start", "", "Start as \"<date> <time>\" (date in your configured format or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&manualEnd, "end", "", "End as \"<date> <time>\", or just a time on the start date")
+	cmd.Flags().StringVarP(&manualDescription, "description", "d", "", "Description of the work")
+	cmd.Flags().StringVarP(&manualMilestone, "milestone", "m", "", "Milestone to assign (defaults to the one running at the start time)")
+	cmd.Flags().StringVar(&manualRate, "rate", "", "Hourly rate (defaults to the rate in .tmporc)")
+	cmd.Flags().StringVar(&manualFromFile, "from-file", "", "Create entries from a CSV file")
 
-			if entry.MilestoneName != nil && *entry.MilestoneName != "" {
-				ui.PrintInfo(4, ui.Bold("Milestone"), *entry.MilestoneName)
-			}
+	return cmd
+}
 
-			if entry.HourlyRate != nil {
-				// Get currency from global config
-				currencyCode := currency.DefaultCurrency
-				if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
-					currencyCode = globalCfg.Currency
-				}
+// createManualFromFlags creates an entry from the command's flags without
+// prompting.
+func createManualFromFlags(cmd *cobra.Command, globalCfg *settings.GlobalConfig, dateLayout, dateDisplay string) {
+	flags := cmd.Flags()
 
-				earnings := entry.RoundedHours() * *entry.HourlyRate
-				fmt.Printf("    %s %s\n", ui.BoldInfo("Hourly Rate:"), currency.FormatCurrency(*entry.HourlyRate, currencyCode))
-				fmt.Printf("    %s %s\n", ui.BoldInfo("Earnings:"), currency.FormatCurrency(earnings, currencyCode))
-			}
+	if !flags.Changed("start") || !flags.Changed("end") {
+		ui.PrintError(ui.EmojiError, "--start and --end are required")
+		ui.PrintMuted(0, "Run 'tmpo manual' without flags to be prompted instead")
+		ui.NewlineBelow()
+		os.Exit(1)
+	}
+
+	projectName := strings.TrimSpace(manualProject)
+	if projectName == "" {
+		projectName = detectProjectNameWithSource()
+	}
 
+	if projectName == "" {
+		ui.PrintError(ui.EmojiError, "project name cannot be empty")
+		ui.PrintMuted(0, "Pass --project or run the command inside a project")
+		ui.NewlineBelow()
+		os.Exit(1)
+	}
+
+	startTime, err := parseDateTimeFlag(manualStart, time.Now(), dateLayout, dateDisplay)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("--start: %v", err))
+		os.Exit(1)
+	}
+
+	endTime, err := parseDateTimeFlag(manualEnd, startTime, dateLayout, dateDisplay)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("--end: %v", err))
+		os.Exit(1)
+	}
+
+	if !endTime.After(startTime) {
+		ui.PrintError(ui.EmojiError, "end time must be after start time")
+		os.Exit(1)
+	}
+
+	var hourlyRate *float64
+	if flags.Changed("rate") {
+		hourlyRate, err = parseRate(manualRate)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("--rate: %v", err))
+			os.Exit(1)
+		}
+	} else if cfg, _, err := settings.FindAndLoad(); err == nil && cfg != nil && cfg.HourlyRate > 0 {
+		hourlyRate = &cfg.HourlyRate
+	}
+
+	db, err := storage.Initialize()
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	var milestoneName *string
+	suggested := false
+	if name := strings.TrimSpace(manualMilestone); name != "" {
+		milestone, err := db.GetMilestoneByName(projectName, name)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+
+		if milestone == nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("milestone '%s' not found in project '%s'", name, projectName))
 			ui.NewlineBelow()
-		},
+			os.Exit(1)
+		}
+
+		if warningMsg := milestoneTimeframeWarning(milestone, startTime); warningMsg != "" {
+			printMilestoneTimeframeWarning(warningMsg)
+		}
+
+		milestoneName = &milestone.Name
+	} else if !flags.Changed("milestone") {
+		milestones, err := db.GetMilestonesByProject(projectName)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+
+		if milestone := suggestMilestone(milestones, startTime); milestone != nil {
+			milestoneName = &milestone.Name
+			suggested = true
+		}
 	}
 
-	return cmd
+	startTime, endTime, carve, ok := resolveOverlaps(db, startTime, endTime, 0, !globalCfg.RejectsOverlaps())
+	if !ok {
+		ui.PrintWarning(ui.EmojiWarning, "Manual entry cancelled")
+		ui.NewlineBelow()
+		os.Exit(0)
+	}
+
+	createManualEntry := db.CreateManualEntry
+	if carve {
+		createManualEntry = db.CreateManualEntryCarving
+	}
+
+	entry, err := createManualEntry(projectName, strings.TrimSpace(manualDescription), startTime, endTime, hourlyRate, milestoneName)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	printCreatedEntry(entry)
+	if suggested {
+		ui.PrintMuted(4, "└─ Milestone was running at the start time; pass --milestone \"\" to leave it unassigned")
+	}
+
+	ui.NewlineBelow()
+}
+
+// suggestMilestone returns the milestone that was running at start, or nil
+// if none was. Milestones are expected newest first, so the most recently
+// started one wins when several overlap.
+func suggestMilestone(milestones []*storage.Milestone, start time.Time) *storage.Milestone {
+	for _, milestone := range milestones {
+		if milestoneTimeframeWarning(milestone, start) == "" {
+			return milestone
+		}
+	}
+
+	return nil
+}
+
+// printCreatedEntry prints the summary of a newly created entry.
+func printCreatedEntry(entry *storage.TimeEntry) {
+	duration := entry.Duration()
+	ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Created manual entry for %s", ui.Bold(entry.ProjectName)))
+	ui.PrintInfo(4, ui.Bold("Start"), settings.FormatDateTimeLong(entry.StartTime))
+	ui.PrintInfo(4, ui.Bold("End"), settings.FormatDateTimeLong(*entry.EndTime))
+	ui.PrintInfo(4, ui.Bold("Duration"), ui.FormatDuration(duration))
+
+	if entry.Description != "" {
+		ui.PrintInfo(4, ui.Bold("Description"), entry.Description)
+	}
+
+	if entry.MilestoneName != nil && *entry.MilestoneName != "" {
+		ui.PrintInfo(4, ui.Bold("Milestone"), *entry.MilestoneName)
+	}
+
+	if entry.HourlyRate != nil {
+		// Get currency from global config
+		currencyCode := currency.DefaultCurrency
+		if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
+			currencyCode = globalCfg.Currency
+		}
+
+		earnings := entry.RoundedHours() * *entry.HourlyRate
+		fmt.Printf("    %s %s\n", ui.BoldInfo("Hourly Rate:"), currency.FormatCurrency(*entry.HourlyRate, currencyCode))
+		fmt.Printf("    %s %s\n", ui.BoldInfo("Earnings:"), currency.FormatCurrency(earnings, currencyCode))
+	}
 }
 
 func validateDate(input, layout, displayFormat string) error {
@@ -0,0 +1,389 @@
+package entries
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+)
+
+// importPreviewLimit is how many entries are listed after an import.
+const importPreviewLimit = 10
+
+// importColumnNames maps accepted CSV headers, lower-cased, to entry fields.
+// Export headers are included so an export can be imported again; other
+// columns such as Duration or Earnings are ignored.
+var importColumnNames = map[string]string{
+	"project":      storage.FieldProject,
+	"project name": storage.FieldProject,
+	"start":        storage.FieldStart,
+	"start time":   storage.FieldStart,
+	"end":          storage.FieldEnd,
+	"end time":     storage.FieldEnd,
+	"description":  storage.FieldDescription,
+	"milestone":    storage.FieldMilestone,
+	"rate":         storage.FieldRate,
+	"hourly rate":  storage.FieldRate,
+}
+
+// importRow is one entry read from an import file.
+type importRow struct {
+	line  int
+	entry *storage.TimeEntry
+	// suggest is set when the file has no milestone column, so the row
+	// gets the milestone running at its start time.
+	suggest bool
+}
+
+// parseImportCSV reads entries from CSV with a header row. Rows without a
+// project use defaultProject and, when the file has no rate column, every
+// row uses defaultRate. Rows without an end, such as a running entry in an
+// export, are skipped and their line numbers returned. It returns every
+// problem found rather than stopping at the first, each prefixed with its
+// line number.
+func parseImportCSV(r io.Reader, defaultProject string, defaultRate *float64, dateLayout, dateDisplay string) ([]*importRow, []int, []error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, []error{fmt.Errorf("file is empty")}
+	}
+	if err != nil {
+		return nil, nil, []error{fmt.Errorf("failed to read header: %w", err)}
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		field, ok := importColumnNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			continue
+		}
+
+		if _, dup := columns[field]; dup {
+			return nil, nil, []error{fmt.Errorf("column '%s' appears more than once", field)}
+		}
+		columns[field] = i
+	}
+
+	for _, field := range []string{storage.FieldStart, storage.FieldEnd} {
+		if _, ok := columns[field]; !ok {
+			return nil, nil, []error{fmt.Errorf("missing required '%s' column", field)}
+		}
+	}
+
+	_, hasMilestone := columns[storage.FieldMilestone]
+	_, hasRate := columns[storage.FieldRate]
+
+	var rows []*importRow
+	var running []int
+	var errs []error
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			// csv errors already name their line
+			errs = append(errs, err)
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		cell := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		if cell(storage.FieldEnd) == "" {
+			running = append(running, line)
+			continue
+		}
+
+		row, err := parseImportRecord(cell, defaultProject, dateLayout, dateDisplay)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+
+		row.line = line
+		row.suggest = !hasMilestone
+		if !hasRate {
+			row.entry.HourlyRate = defaultRate
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, running, errs
+}
+
+func parseImportRecord(cell func(field string) string, defaultProject, dateLayout, dateDisplay string) (*importRow, error) {
+	projectName := cell(storage.FieldProject)
+	if projectName == "" {
+		projectName = defaultProject
+	}
+
+	if projectName == "" {
+		return nil, fmt.Errorf("project name cannot be empty")
+	}
+
+	start, err := parseDateTimeFlag(cell(storage.FieldStart), time.Now(), dateLayout, dateDisplay)
+	if err != nil {
+		return nil, fmt.Errorf("start: %w", err)
+	}
+
+	end, err := parseDateTimeFlag(cell(storage.FieldEnd), start, dateLayout, dateDisplay)
+	if err != nil {
+		return nil, fmt.Errorf("end: %w", err)
+	}
+
+	if !end.After(start) {
+		return nil, fmt.Errorf("end time must be after start time")
+	}
+
+	rate, err := parseRate(cell(storage.FieldRate))
+	if err != nil {
+		return nil, fmt.Errorf("rate: %w", err)
+	}
+
+	entry := &storage.TimeEntry{
+		ProjectName: projectName,
+		StartTime:   start,
+		EndTime:     &end,
+		Description: cell(storage.FieldDescription),
+		HourlyRate:  rate,
+	}
+
+	if name := cell(storage.FieldMilestone); name != "" {
+		entry.MilestoneName = &name
+	}
+
+	return &importRow{entry: entry}, nil
+}
+
+// overlappingRows returns the pairs of rows in the file that overlap each
+// other.
+func overlappingRows(rows []*importRow) [][2]*importRow {
+	sorted := make([]*importRow, len(rows))
+	copy(sorted, rows)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].entry.StartTime.Before(sorted[j].entry.StartTime)
+	})
+
+	var pairs [][2]*importRow
+	for i, row := range sorted {
+		for _, next := range sorted[i+1:] {
+			if !next.entry.StartTime.Before(*row.entry.EndTime) {
+				break
+			}
+			pairs = append(pairs, [2]*importRow{row, next})
+		}
+	}
+
+	return pairs
+}
+
+// importManualEntries creates every entry in a CSV file as one operation,
+// or none of them if any row is invalid.
+func importManualEntries(path string, globalCfg *settings.GlobalConfig, dateLayout, dateDisplay string) {
+	ui.PrintSuccess(ui.EmojiManual, fmt.Sprintf("Import Entries from %s", filepath.Base(path)))
+	fmt.Println()
+
+	file, err := os.Open(path)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	defaultProject := strings.TrimSpace(manualProject)
+	if defaultProject == "" {
+		defaultProject = detectProjectNameWithSource()
+	}
+
+	var defaultRate *float64
+	if cfg, _, err := settings.FindAndLoad(); err == nil && cfg != nil && cfg.HourlyRate > 0 {
+		defaultRate = &cfg.HourlyRate
+	}
+
+	rows, running, errs := parseImportCSV(file, defaultProject, defaultRate, dateLayout, dateDisplay)
+
+	db, err := storage.Initialize()
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	errs = append(errs, assignImportMilestones(db, rows)...)
+	if len(errs) > 0 {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%d %s in %s, nothing was imported", len(errs), pluralize(len(errs), "problem", "problems"), filepath.Base(path)))
+		for _, err := range errs {
+			ui.PrintMuted(4, err.Error())
+		}
+		ui.NewlineBelow()
+		os.Exit(1)
+	}
+
+	if len(running) > 0 {
+		lines := make([]string, len(running))
+		for i, line := range running {
+			lines[i] = fmt.Sprintf("%d", line)
+		}
+
+		ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("Skipped %d %s without an end time", len(running), pluralize(len(running), "row", "rows")))
+		ui.PrintMuted(4, fmt.Sprintf("%s %s", pluralize(len(running), "line", "lines"), strings.Join(lines, ", ")))
+		fmt.Println()
+	}
+
+	if len(rows) == 0 {
+		ui.PrintWarning(ui.EmojiWarning, "No entries in file")
+		ui.NewlineBelow()
+		return
+	}
+
+	checkImportOverlaps(db, rows, !globalCfg.RejectsOverlaps())
+
+	entries := make([]*storage.TimeEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = row.entry
+	}
+
+	imported, err := db.ImportEntries(entries)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	var total time.Duration
+	for _, entry := range imported {
+		total += entry.Duration()
+	}
+
+	ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Imported %d %s (%s)", len(imported), pluralize(len(imported), "entry", "entries"), ui.FormatDuration(total)))
+	fmt.Println()
+
+	for i, entry := range imported {
+		if i == importPreviewLimit {
+			ui.PrintMuted(4, fmt.Sprintf("... and %d more", len(imported)-importPreviewLimit))
+			break
+		}
+
+		label := formatEntryLabelForDelete(entry)
+		if entry.MilestoneName != nil {
+			label += ui.Muted(fmt.Sprintf(" [%s]", *entry.MilestoneName))
+		}
+		fmt.Printf("    %s %s %s\n", ui.Muted(fmt.Sprintf("#%d", entry.ID)), ui.Bold(entry.ProjectName), label)
+	}
+
+	fmt.Println()
+	ui.PrintMuted(0, "Use 'tmpo undo' to remove the whole import")
+	ui.NewlineBelow()
+}
+
+// assignImportMilestones checks that named milestones exist and suggests
+// one for rows from files without a milestone column.
+func assignImportMilestones(db *storage.Database, rows []*importRow) []error {
+	var errs []error
+	projectMilestones := make(map[string][]*storage.Milestone)
+
+	for _, row := range rows {
+		entry := row.entry
+
+		milestones, ok := projectMilestones[entry.ProjectName]
+		if !ok {
+			var err error
+			milestones, err = db.GetMilestonesByProject(entry.ProjectName)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			projectMilestones[entry.ProjectName] = milestones
+		}
+
+		if row.suggest {
+			if milestone := suggestMilestone(milestones, entry.StartTime); milestone != nil {
+				entry.MilestoneName = &milestone.Name
+			}
+			continue
+		}
+
+		if entry.MilestoneName == nil {
+			continue
+		}
+
+		found := false
+		for _, milestone := range milestones {
+			if milestone.Name == *entry.MilestoneName {
+				found = true
+			}
+		}
+
+		if !found {
+			errs = append(errs, fmt.Errorf("line %d: milestone '%s' not found in project '%s'", row.line, *entry.MilestoneName, entry.ProjectName))
+		}
+	}
+
+	return errs
+}
+
+// checkImportOverlaps reports rows that overlap existing entries or each
+// other, exiting when overlaps aren't allowed.
+func checkImportOverlaps(db *storage.Database, rows []*importRow, allowOverlaps bool) {
+	var overlaps []string
+
+	for _, row := range rows {
+		conflicts, err := db.FindOverlappingEntries(row.entry.StartTime, row.entry.EndTime, 0)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+
+		for _, conflict := range conflicts {
+			overlaps = append(overlaps, fmt.Sprintf("line %d overlaps entry #%d %s (%s)", row.line, conflict.ID, conflict.ProjectName, formatEntryLabelForDelete(conflict)))
+		}
+	}
+
+	for _, pair := range overlappingRows(rows) {
+		first, second := pair[0].line, pair[1].line
+		if first > second {
+			first, second = second, first
+		}
+		overlaps = append(overlaps, fmt.Sprintf("line %d overlaps line %d", first, second))
+	}
+
+	if len(overlaps) == 0 {
+		return
+	}
+
+	if !allowOverlaps {
+		ui.PrintError(ui.EmojiError, "overlapping entries are not allowed by overlap_policy, nothing was imported")
+		for _, overlap := range overlaps {
+			ui.PrintMuted(4, overlap)
+		}
+		ui.NewlineBelow()
+		os.Exit(1)
+	}
+
+	ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("%d %s found, keeping both entries", len(overlaps), pluralize(len(overlaps), "overlap", "overlaps")))
+	for _, overlap := range overlaps {
+		ui.PrintMuted(4, overlap)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,100 @@
+package entries
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	const layout, display = "01-02-2006", "MM-DD-YYYY"
+	defaultRate := 50.0
+
+	t.Run("reads every column", func(t *testing.T) {
+		input := "project,start,end,description,milestone,rate\n" +
+			"client,2024-03-04 09:00,2024-03-04 12:30,review,Sprint 1,80\n" +
+			",03-04-2024 1:00 PM,2:00 PM,,,\n"
+
+		rows, _, errs := parseImportCSV(strings.NewReader(input), "fallback", &defaultRate, layout, display)
+		require.Empty(t, errs)
+		require.Len(t, rows, 2)
+
+		first := rows[0]
+		assert.Equal(t, 2, first.line)
+		assert.Equal(t, "client", first.entry.ProjectName)
+		assert.True(t, time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local).Equal(first.entry.StartTime))
+		assert.True(t, time.Date(2024, 3, 4, 12, 30, 0, 0, time.Local).Equal(*first.entry.EndTime))
+		assert.Equal(t, "review", first.entry.Description)
+		assert.Equal(t, "Sprint 1", *first.entry.MilestoneName)
+		assert.Equal(t, 80.0, *first.entry.HourlyRate)
+		assert.False(t, first.suggest)
+
+		second := rows[1]
+		assert.Equal(t, "fallback", second.entry.ProjectName)
+		assert.True(t, time.Date(2024, 3, 4, 14, 0, 0, 0, time.Local).Equal(*second.entry.EndTime))
+		assert.Nil(t, second.entry.MilestoneName)
+		assert.Nil(t, second.entry.HourlyRate)
+	})
+
+	t.Run("accepts export headers", func(t *testing.T) {
+		input := "ID,Project,Start Time,End Time,Duration (hours)\n" +
+			"7,client,2024-03-04T09:00:00Z,2024-03-04T10:00:00Z,1.00\n"
+
+		rows, _, errs := parseImportCSV(strings.NewReader(input), "", &defaultRate, layout, display)
+		require.Empty(t, errs)
+		require.Len(t, rows, 1)
+		assert.Equal(t, time.Hour, rows[0].entry.Duration())
+		assert.Equal(t, 50.0, *rows[0].entry.HourlyRate)
+		assert.True(t, rows[0].suggest)
+	})
+
+	t.Run("skips rows without an end", func(t *testing.T) {
+		input := "ID,Project,Start Time,End Time\n" +
+			"7,client,2024-03-04T09:00:00Z,2024-03-04T10:00:00Z\n" +
+			"8,client,2024-03-04T11:00:00Z,\n"
+
+		rows, running, errs := parseImportCSV(strings.NewReader(input), "", nil, layout, display)
+		require.Empty(t, errs)
+		require.Len(t, rows, 1)
+		assert.Equal(t, []int{3}, running)
+	})
+
+	t.Run("reports every invalid row", func(t *testing.T) {
+		input := "project,start,end\n" +
+			"p,2024-03-04 09:00,2024-03-04 08:00\n" +
+			",2024-03-04 09:00,2024-03-04 10:00\n" +
+			"p,someday,2024-03-04 10:00\n"
+
+		rows, _, errs := parseImportCSV(strings.NewReader(input), "", nil, layout, display)
+		assert.Empty(t, rows)
+		require.Len(t, errs, 3)
+		assert.Contains(t, errs[0].Error(), "line 2: end time must be after start time")
+		assert.Contains(t, errs[1].Error(), "line 3: project name cannot be empty")
+		assert.Contains(t, errs[2].Error(), "line 4: start:")
+	})
+
+	t.Run("requires start and end columns", func(t *testing.T) {
+		_, _, errs := parseImportCSV(strings.NewReader("project,start\n"), "p", nil, layout, display)
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), "'end'")
+	})
+}
+
+func TestOverlappingRows(t *testing.T) {
+	input := "project,start,end\n" +
+		"p,2024-03-04 09:00,2024-03-04 11:00\n" +
+		"p,2024-03-04 13:00,2024-03-04 14:00\n" +
+		"p,2024-03-04 10:00,2024-03-04 12:00\n" +
+		"p,2024-03-04 11:00,2024-03-04 11:30\n"
+
+	rows, _, errs := parseImportCSV(strings.NewReader(input), "", nil, "01-02-2006", "MM-DD-YYYY")
+	require.Empty(t, errs)
+
+	pairs := overlappingRows(rows)
+	require.Len(t, pairs, 2)
+	assert.Equal(t, []int{2, 4}, []int{pairs[0][0].line, pairs[0][1].line})
+	assert.Equal(t, []int{4, 5}, []int{pairs[1][0].line, pairs[1][1].line})
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DylanDevelops/tmpo/internal/storage"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -296,3 +297,20 @@ func TestNormalizeAMPM(t *testing.T) {
 		})
 	}
 }
+
+func TestSuggestMilestone(t *testing.T) {
+	march := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	marchEnd := time.Date(2024, 3, 31, 0, 0, 0, 0, time.Local)
+	april := time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local)
+
+	// newest first, as returned by storage
+	milestones := []*storage.Milestone{
+		{ID: 2, Name: "April", StartTime: april},
+		{ID: 1, Name: "March", StartTime: march, EndTime: &marchEnd},
+	}
+
+	assert.Equal(t, "March", suggestMilestone(milestones, march.Add(48*time.Hour)).Name)
+	assert.Equal(t, "April", suggestMilestone(milestones, april.Add(time.Hour)).Name)
+	assert.Nil(t, suggestMilestone(milestones, march.Add(-time.Hour)))
+	assert.Nil(t, suggestMilestone(milestones, marchEnd.Add(time.Hour)))
+}
@@ -0,0 +1,117 @@
+package entries
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+var mergeYes bool
+
+func MergeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge [id...]",
+		Short: "Merge adjacent time entries",
+		Long:  `Merge adjacent entries of the same project into one, for example the fragments left behind by pause and resume. The earliest entry is extended to the end of the last one and the others are moved to the trash.`,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			entries := entriesByID(db, args)
+			sort.SliceStable(entries, func(i, j int) bool {
+				return entries[i].StartTime.Before(entries[j].StartTime)
+			})
+
+			if err := storage.ValidateMerge(entries); err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			first := entries[0]
+			end := *first.EndTime
+			var tracked time.Duration
+			for _, entry := range entries {
+				tracked += entry.Duration()
+				if entry.EndTime.After(end) {
+					end = *entry.EndTime
+				}
+			}
+
+			ui.PrintInfo(0, ui.Bold(fmt.Sprintf("Merging %d entries of %s", len(entries), first.ProjectName)), "")
+			for _, entry := range entries {
+				fmt.Printf("    %s %s\n", ui.Muted(fmt.Sprintf("#%d", entry.ID)), formatEntryLabelForDelete(entry))
+			}
+			fmt.Println()
+
+			ui.PrintInfo(4, ui.Bold("Result"), fmt.Sprintf("#%d %s → %s (%s)",
+				first.ID, settings.FormatDateTimeDashed(first.StartTime), settings.FormatDateTimeDashed(end), ui.FormatDuration(end.Sub(first.StartTime))))
+
+			// Gaps between the entries become tracked time once merged
+			if gaps := end.Sub(first.StartTime) - tracked; gaps > 0 {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("The merged entry includes %s between the entries that wasn't tracked", ui.FormatDuration(gaps)))
+			}
+			fmt.Println()
+
+			if !mergeYes {
+				if !ui.IsInteractive() {
+					ui.PrintError(ui.EmojiError, "confirmation required")
+					ui.PrintMuted(0, "Pass --yes to merge without a terminal")
+					ui.NewlineBelow()
+					os.Exit(1)
+				}
+
+				confirmPrompt := promptui.Select{
+					Label: fmt.Sprintf("Merge %d entries?", len(entries)),
+					Items: []string{"No", "Yes"},
+				}
+
+				_, result, err := confirmPrompt.Run()
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				if result == "No" {
+					ui.PrintWarning(ui.EmojiWarning, "Merge cancelled")
+					ui.NewlineBelow()
+					os.Exit(0)
+				}
+			}
+
+			ids := make([]int64, len(entries))
+			for i, entry := range entries {
+				ids[i] = entry.ID
+			}
+
+			merged, err := db.MergeEntries(ids)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Merged %d entries into #%d", len(entries), merged.ID))
+			fmt.Printf("    %s %s %s\n", ui.Muted(fmt.Sprintf("#%d", merged.ID)), ui.Bold(merged.ProjectName), formatEntryLabelForDelete(merged))
+			ui.PrintMuted(4, "└─ Use 'tmpo undo' to separate them again")
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().BoolVarP(&mergeYes, "yes", "y", false, "Skip the confirmation prompt")
+
+	return cmd
+}
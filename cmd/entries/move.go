@@ -0,0 +1,245 @@
+package entries
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	moveFromProject string
+	moveToProject   string
+	moveMilestone   string
+	moveRange       string
+	moveOnConflict  string
+	moveYes         bool
+	moveDryRun      bool
+)
+
+// movePreviewLimit is how many entries are listed before a move.
+const movePreviewLimit = 10
+
+func MoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "move",
+		Short: "Move entries and milestones to another project",
+		Long:  `Move time entries and their milestones from one project to another in a single step, for example after renaming a repository. Limit the move to one milestone or a period of time with --milestone and --range.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			req := storage.MoveRequest{
+				FromProject:   strings.TrimSpace(moveFromProject),
+				ToProject:     strings.TrimSpace(moveToProject),
+				MilestoneName: strings.TrimSpace(moveMilestone),
+				OnConflict:    moveOnConflict,
+			}
+
+			if req.FromProject == "" || req.ToProject == "" {
+				ui.PrintError(ui.EmojiError, "--from-project and --to-project are required")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			switch req.OnConflict {
+			case "", storage.ConflictFail, storage.ConflictMerge, storage.ConflictRename:
+			default:
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("invalid --on-conflict '%s', use fail, merge or rename", req.OnConflict))
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			if cmd.Flags().Changed("range") {
+				r, err := period.ParseWithWeekStart(moveRange, time.Now(), settings.WeekStart())
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("--range: %v", err))
+					os.Exit(1)
+				}
+				req.Start, req.End = r.Start, r.End
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			plan, err := db.PlanMove(req)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if len(plan.Entries) == 0 && len(plan.Milestones) == 0 {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("Nothing to move from %s", req.FromProject))
+				ui.NewlineBelow()
+				return
+			}
+
+			if collisions := plan.Collisions(); len(collisions) > 0 && req.OnConflict == "" && !moveDryRun {
+				req.OnConflict = chooseMoveConflictPolicy(req.ToProject, collisions)
+				if plan, err = db.PlanMove(req); err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+			}
+
+			printMovePlan(req, plan)
+
+			if moveDryRun {
+				ui.PrintMuted(0, "Dry run: nothing was moved")
+				ui.NewlineBelow()
+				return
+			}
+
+			if !moveYes {
+				if !ui.IsInteractive() {
+					ui.PrintError(ui.EmojiError, "confirmation required")
+					ui.PrintMuted(0, "Pass --yes to move without a terminal, or --dry-run to only preview")
+					ui.NewlineBelow()
+					os.Exit(1)
+				}
+
+				confirmPrompt := promptui.Select{
+					Label: fmt.Sprintf("Move %d %s to %s?", len(plan.Entries), pluralize(len(plan.Entries), "entry", "entries"), req.ToProject),
+					Items: []string{"No", "Yes"},
+				}
+
+				_, result, err := confirmPrompt.Run()
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				if result == "No" {
+					ui.PrintWarning(ui.EmojiWarning, "Move cancelled")
+					ui.NewlineBelow()
+					os.Exit(0)
+				}
+			}
+
+			plan, err = db.MoveEntries(req)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Moved %d %s from %s to %s", len(plan.Entries), pluralize(len(plan.Entries), "entry", "entries"), ui.Bold(req.FromProject), ui.Bold(req.ToProject)))
+			ui.PrintMuted(4, "└─ Use 'tmpo undo' to move them back")
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().StringVar(&moveFromProject, "from-project", "", "Project to move entries from")
+	cmd.Flags().StringVar(&moveToProject, "to-project", "", "Project to move entries to")
+	cmd.Flags().StringVarP(&moveMilestone, "milestone", "m", "", "Only move the entries of this milestone")
+	cmd.Flags().StringVar(&moveRange, "range", "", "Only move entries that started in this period (e.g. 2024-03, last-month, 2024-01-01..2024-02-15)")
+	cmd.Flags().StringVar(&moveOnConflict, "on-conflict", "", "What to do when a milestone already exists in the target project (fail, merge or rename)")
+	cmd.Flags().BoolVarP(&moveYes, "yes", "y", false, "Skip the confirmation prompt")
+	cmd.Flags().BoolVar(&moveDryRun, "dry-run", false, "Show what would be moved without moving anything")
+
+	return cmd
+}
+
+// chooseMoveConflictPolicy asks how to handle milestones that already exist
+// in the target project, exiting when there is no terminal to ask in.
+func chooseMoveConflictPolicy(project string, collisions []string) string {
+	err := &storage.MilestoneConflictError{Project: project, Names: collisions}
+
+	if !ui.IsInteractive() {
+		ui.PrintError(ui.EmojiError, err.Error())
+		ui.PrintMuted(0, "Pass --on-conflict merge to combine them, or --on-conflict rename to keep them apart")
+		ui.NewlineBelow()
+		os.Exit(1)
+	}
+
+	ui.PrintWarning(ui.EmojiWarning, err.Error())
+
+	options := []string{
+		"Merge into the existing milestones",
+		"Rename the moved milestones",
+		"Cancel",
+	}
+
+	prompt := promptui.Select{
+		Label: "How should the milestones be combined?",
+		Items: options,
+	}
+
+	idx, _, promptErr := prompt.Run()
+	if promptErr != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", promptErr))
+		os.Exit(1)
+	}
+	fmt.Println()
+
+	switch idx {
+	case 0:
+		return storage.ConflictMerge
+	case 1:
+		return storage.ConflictRename
+	default:
+		ui.PrintWarning(ui.EmojiWarning, "Move cancelled")
+		ui.NewlineBelow()
+		os.Exit(0)
+		return ""
+	}
+}
+
+func printMovePlan(req storage.MoveRequest, plan *storage.MovePlan) {
+	var total time.Duration
+	for _, entry := range plan.Entries {
+		total += entry.Duration()
+	}
+
+	ui.PrintInfo(0, ui.Bold(fmt.Sprintf("%d %s (%s) will move from %s to %s", len(plan.Entries), pluralize(len(plan.Entries), "entry", "entries"), ui.FormatDuration(total), req.FromProject, req.ToProject)), "")
+	fmt.Println()
+
+	for i, entry := range plan.Entries {
+		if i == movePreviewLimit {
+			ui.PrintMuted(4, fmt.Sprintf("... and %d more", len(plan.Entries)-movePreviewLimit))
+			break
+		}
+		fmt.Printf("    %s %s\n", ui.Muted(fmt.Sprintf("#%d", entry.ID)), formatEntryLabelForDelete(entry))
+	}
+
+	if len(plan.Milestones) > 0 {
+		fmt.Println()
+		ui.PrintInfo(0, ui.Bold("Milestones"), "")
+		for _, m := range plan.Milestones {
+			description := describeMilestoneMove(req, m)
+			if m.Finishes {
+				description += fmt.Sprintf(", finished since %s already has an active milestone", req.ToProject)
+			}
+			fmt.Printf("    %s %s\n", ui.Bold(m.Milestone.Name), ui.Muted(description))
+		}
+	}
+
+	fmt.Println()
+}
+
+func describeMilestoneMove(req storage.MoveRequest, m *storage.MilestoneMove) string {
+	switch {
+	case m.Collides && m.NewName == m.Milestone.Name && req.OnConflict == storage.ConflictMerge:
+		return fmt.Sprintf("merged into the existing milestone of %s", req.ToProject)
+	case m.Collides && m.NewName == m.Milestone.Name:
+		return fmt.Sprintf("already exists in %s, choose --on-conflict merge or rename", req.ToProject)
+	case m.NewName != m.Milestone.Name && m.Whole:
+		return fmt.Sprintf("moves, renamed to '%s'", m.NewName)
+	case m.NewName != m.Milestone.Name:
+		return fmt.Sprintf("copied as '%s', some entries stay in %s", m.NewName, req.FromProject)
+	case m.Whole:
+		return "moves"
+	default:
+		return fmt.Sprintf("copied, some entries stay in %s", req.FromProject)
+	}
+}
@@ -0,0 +1,149 @@
+package entries
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/manifoldco/promptui"
+)
+
+// resolveOverlaps checks [start, end) against the existing entries (other
+// than excludeID) and, when it overlaps any, shows the conflicts and asks how
+// to resolve them. It returns the interval to save, which is trimmed if the
+// user chose so, whether the existing entries should be carved around it
+// when it is saved, and false if the user cancelled. Keeping both entries is
+// only offered when overlaps are allowed. Without a terminal to prompt on,
+// both entries are kept when allowed and the command fails otherwise.
+func resolveOverlaps(db *storage.Database, start, end time.Time, excludeID int64, allowOverlaps bool) (time.Time, time.Time, bool, bool) {
+	conflicts, err := db.FindOverlappingEntries(start, &end, excludeID)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	if len(conflicts) == 0 {
+		return start, end, false, true
+	}
+
+	printOverlaps(start, end, conflicts)
+
+	if !ui.IsInteractive() {
+		if !allowOverlaps {
+			ui.PrintError(ui.EmojiError, "overlapping entries are not allowed by overlap_policy")
+			ui.PrintMuted(0, "Run the command in a terminal to trim the overlap")
+			ui.NewlineBelow()
+			os.Exit(1)
+		}
+
+		ui.PrintMuted(0, "Keeping both entries; run the command in a terminal to trim the overlap instead")
+		return start, end, false, true
+	}
+
+	const (
+		optionTrim     = "trim"
+		optionCarve    = "carve"
+		optionKeepBoth = "keep"
+		optionCancel   = "cancel"
+	)
+
+	var options, labels []string
+
+	trimmedStart, trimmedEnd, canTrim := storage.TrimToFit(start, end, conflicts)
+	if canTrim {
+		options = append(options, optionTrim)
+		labels = append(labels, fmt.Sprintf("Trim this entry to %s → %s", settings.FormatDateTimeDashed(trimmedStart), settings.FormatTime(trimmedEnd)))
+	}
+
+	covered := 0
+	for _, entry := range conflicts {
+		if entry.EndTime != nil && !entry.StartTime.Before(start) && !entry.EndTime.After(end) {
+			covered++
+		}
+	}
+
+	carveLabel := "Trim or split the existing entries around this one"
+	if covered > 0 {
+		carveLabel += fmt.Sprintf(" (deletes %d fully covered)", covered)
+	}
+
+	options = append(options, optionCarve)
+	labels = append(labels, carveLabel)
+
+	if allowOverlaps {
+		options = append(options, optionKeepBoth)
+		labels = append(labels, "Keep both")
+	}
+
+	options = append(options, optionCancel)
+	labels = append(labels, "Cancel")
+
+	overlapPrompt := promptui.Select{
+		Label: "How should the overlap be resolved?",
+		Items: labels,
+	}
+
+	idx, _, err := overlapPrompt.Run()
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	switch options[idx] {
+	case optionTrim:
+		return trimmedStart, trimmedEnd, false, true
+	case optionCarve:
+		return start, end, true, true
+	case optionKeepBoth:
+		return start, end, false, true
+	default:
+		return start, end, false, false
+	}
+}
+
+func printOverlaps(start, end time.Time, conflicts []*storage.TimeEntry) {
+	fmt.Println()
+	ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("This entry overlaps %d existing %s", len(conflicts), pluralize(len(conflicts), "entry", "entries")))
+	fmt.Printf("    %s %s → %s\n", ui.Bold("New:"), settings.FormatDateTimeDashed(start), settings.FormatDateTimeDashed(end))
+
+	for _, entry := range conflicts {
+		entryEnd := time.Now()
+		endStr := "running"
+		if entry.EndTime != nil {
+			entryEnd = *entry.EndTime
+			endStr = settings.FormatDateTimeDashed(entryEnd)
+		}
+
+		overlap := minTime(end, entryEnd).Sub(maxTime(start, entry.StartTime))
+		fmt.Printf("    %s %s → %s %s\n",
+			ui.Bold(fmt.Sprintf("#%d %s:", entry.ID, entry.ProjectName)),
+			ui.Muted(settings.FormatDateTimeDashed(entry.StartTime)),
+			ui.Muted(endStr),
+			ui.Warning(fmt.Sprintf("(overlaps %s)", ui.FormatDuration(overlap))))
+	}
+	fmt.Println()
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
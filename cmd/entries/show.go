@@ -0,0 +1,184 @@
+package entries
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/currency"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var showHistory bool
+
+var sourceLabels = map[string]string{
+	storage.SourceTimer:     "Tracked live with a timer",
+	storage.SourceResume:    "Tracked live with a timer (resumed)",
+	storage.SourceManual:    "Added manually",
+	storage.SourceImport:    "Imported",
+	storage.SourceDuplicate: "Duplicated from another entry",
+}
+
+var fieldLabels = map[string]string{
+	storage.FieldProject:     "Project",
+	storage.FieldStart:       "Start",
+	storage.FieldEnd:         "End",
+	storage.FieldDescription: "Description",
+	storage.FieldRate:        "Hourly Rate",
+	storage.FieldMilestone:   "Milestone",
+}
+
+func ShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show [id]",
+		Short: "Show the details of a time entry",
+		Long:  `Show every field of a time entry, how it was created and whether it was edited. Use --history to list each recorded change.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			id, err := parseEntryID(args[0])
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			entry, err := db.GetEntry(id)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("entry #%d not found", id))
+				os.Exit(1)
+			}
+
+			currencyCode := ""
+			if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
+				currencyCode = globalCfg.Currency
+			}
+
+			ui.PrintSuccess(ui.EmojiLog, fmt.Sprintf("Entry #%d", entry.ID))
+			ui.PrintInfo(4, ui.Bold("Project"), entry.ProjectName)
+			ui.PrintInfo(4, ui.Bold("Start"), settings.FormatDateTimeDashed(entry.StartTime))
+			if entry.EndTime != nil {
+				ui.PrintInfo(4, ui.Bold("End"), settings.FormatDateTimeDashed(*entry.EndTime))
+			} else {
+				ui.PrintInfo(4, ui.Bold("End"), "(running)")
+			}
+			ui.PrintInfo(4, ui.Bold("Duration"), ui.FormatDuration(entry.Duration()))
+
+			if entry.Description != "" {
+				ui.PrintInfo(4, ui.Bold("Description"), entry.Description)
+			}
+
+			if entry.MilestoneName != nil {
+				ui.PrintInfo(4, ui.Bold("Milestone"), *entry.MilestoneName)
+			}
+
+			if entry.HourlyRate != nil {
+				ui.PrintInfo(4, ui.Bold("Hourly Rate"), currency.FormatCurrency(*entry.HourlyRate, currencyCode))
+			}
+
+			ui.PrintInfo(4, ui.Bold("Source"), formatSource(entry))
+
+			edits, err := db.GetEntryEdits(entry.ID)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if len(edits) == 0 {
+				ui.PrintInfo(4, ui.Bold("Edited"), "no")
+			} else {
+				ui.PrintInfo(4, ui.Bold("Edited"), fmt.Sprintf("yes, %d change(s), last on %s", len(edits), settings.FormatDateTimeDashed(edits[len(edits)-1].EditedAt)))
+			}
+
+			if showHistory {
+				fmt.Println()
+				printEditHistory(entry, edits, currencyCode)
+			} else if len(edits) > 0 {
+				ui.PrintMuted(4, "└─ Use --history to see each change")
+			}
+
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().BoolVar(&showHistory, "history", false, "List every recorded change to the entry")
+
+	return cmd
+}
+
+func formatSource(entry *storage.TimeEntry) string {
+	label, ok := sourceLabels[entry.Source]
+	if !ok {
+		return "unknown (recorded before tmpo tracked entry sources)"
+	}
+
+	if entry.CreatedAt != nil {
+		label += fmt.Sprintf(" on %s", settings.FormatDateTimeDashed(*entry.CreatedAt))
+	}
+
+	return label
+}
+
+func sourceLabel(entry *storage.TimeEntry) string {
+	if label, ok := sourceLabels[entry.Source]; ok {
+		return label
+	}
+	return "Created"
+}
+
+func printEditHistory(entry *storage.TimeEntry, edits []*storage.EntryEdit, currencyCode string) {
+	ui.PrintInfo(0, ui.Bold("History"), "")
+
+	if entry.CreatedAt != nil {
+		fmt.Printf("    %s  %s\n", settings.FormatDateTimeDashed(*entry.CreatedAt), sourceLabel(entry))
+	}
+
+	if len(edits) == 0 {
+		ui.PrintMuted(4, "No edits recorded")
+		return
+	}
+
+	for _, edit := range edits {
+		label, ok := fieldLabels[edit.Field]
+		if !ok {
+			label = edit.Field
+		}
+
+		fmt.Printf("    %s  %s: %s → %s  %s\n",
+			settings.FormatDateTimeDashed(edit.EditedAt),
+			ui.Bold(label),
+			formatEditValue(edit.Field, edit.Before, currencyCode),
+			formatEditValue(edit.Field, edit.After, currencyCode),
+			ui.Muted(fmt.Sprintf("(%s)", edit.Via)))
+	}
+}
+
+func formatEditValue(field string, value *string, currencyCode string) string {
+	if value == nil {
+		return ui.Muted("(none)")
+	}
+
+	switch field {
+	case storage.FieldStart, storage.FieldEnd:
+		if t, err := time.Parse(time.RFC3339, *value); err == nil {
+			return settings.FormatDateTimeDashed(t.Local())
+		}
+	case storage.FieldRate:
+		if rate, err := strconv.ParseFloat(*value, 64); err == nil {
+			return currency.FormatCurrency(rate, currencyCode)
+		}
+	}
+
+	return *value
+}
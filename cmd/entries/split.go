@@ -0,0 +1,91 @@
+package entries
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	splitAt          string
+	splitDescription string
+)
+
+func SplitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "split [id]",
+		Short: "Split a time entry in two",
+		Long:  `Split a time entry in two at a given time, for example to break a forgotten all-day timer into the pieces it really covered. Both parts keep the entry's project, rate and milestone.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			id, err := parseEntryID(args[0])
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if !cmd.Flags().Changed("at") {
+				ui.PrintError(ui.EmojiError, "--at is required")
+				ui.PrintMuted(0, "Pass the time to split at, for example --at 13:00")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			globalCfg, err := settings.LoadGlobalConfig()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("loading config: %v", err))
+				os.Exit(1)
+			}
+			dateDisplay, dateLayout := getDateFormatInfo(globalCfg.DateFormat)
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			entry, err := db.GetEntry(id)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("entry #%d not found", id))
+				os.Exit(1)
+			}
+
+			at, err := parseDateTimeFlag(splitAt, entry.StartTime, dateLayout, dateDisplay)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("--at: %v", err))
+				os.Exit(1)
+			}
+
+			var description *string
+			if cmd.Flags().Changed("description") {
+				trimmed := strings.TrimSpace(splitDescription)
+				description = &trimmed
+			}
+
+			first, second, err := db.SplitEntry(id, at, description)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Split entry #%d at %s", id, settings.FormatTime(at)))
+			for _, part := range []*storage.TimeEntry{first, second} {
+				fmt.Printf("    %s %s %s\n", ui.Muted(fmt.Sprintf("#%d", part.ID)), ui.Bold(part.ProjectName), formatEntryLabelForDelete(part))
+			}
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().StringVar(&splitAt, "at", "", "Time to split at, as \"<date> <time>\" or just a time on the entry's start date")
+	cmd.Flags().StringVarP(&splitDescription, "description", "d", "", "Description for the second part (defaults to the entry's)")
+
+	return cmd
+}
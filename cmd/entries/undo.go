@@ -0,0 +1,96 @@
+package entries
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func UndoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Undo the last change",
+		Long:  `Revert the most recent change to your entries or milestones, such as a start, stop, edit, delete or milestone finish. Run it again to undo earlier changes.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			op, err := db.Undo()
+			if err != nil {
+				printJournalError(err)
+				os.Exit(1)
+			}
+
+			if op == nil {
+				ui.PrintWarning(ui.EmojiWarning, "Nothing to undo")
+				ui.NewlineBelow()
+				return
+			}
+
+			ui.PrintSuccess(ui.EmojiUndo, fmt.Sprintf("Undid: %s", op.Description))
+			ui.PrintMuted(4, "└─ Use 'tmpo redo' to reapply it")
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
+
+func RedoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "redo",
+		Short: "Redo the last undone change",
+		Long:  `Reapply the most recently undone change. Changes can be redone until something new is recorded.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			op, err := db.Redo()
+			if err != nil {
+				printJournalError(err)
+				os.Exit(1)
+			}
+
+			if op == nil {
+				ui.PrintWarning(ui.EmojiWarning, "Nothing to redo")
+				ui.NewlineBelow()
+				return
+			}
+
+			ui.PrintSuccess(ui.EmojiRedo, fmt.Sprintf("Redid: %s", op.Description))
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
+
+func printJournalError(err error) {
+	ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+
+	var conflict *storage.JournalConflictError
+	if errors.As(err, &conflict) {
+		ui.PrintMuted(0, "It was modified outside of tmpo's change history, so it can't be reverted safely.")
+		ui.PrintMuted(0, "Use 'tmpo db restore' to roll back to a snapshot instead.")
+	}
+
+	ui.NewlineBelow()
+}
@@ -2,11 +2,14 @@ package history
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/DylanDevelops/tmpo/internal/export"
+	"github.com/DylanDevelops/tmpo/internal/period"
 	"github.com/DylanDevelops/tmpo/internal/project"
 	"github.com/DylanDevelops/tmpo/internal/settings"
 	"github.com/DylanDevelops/tmpo/internal/storage"
@@ -21,15 +24,43 @@ var (
 	exportMilestone string
 	exportToday     bool
 	exportWeek      bool
+	exportColumns   string
 )
 
 func ExportCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "export",
 		Short: "Export time entries",
-		Long:  `Export time tracking data to different formats.`,
+		Long: `Export time tracking data to different formats.
+
+Use '--output -' to write to stdout, e.g. 'tmpo export -f json -o - | jq'.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			ui.NewlineAbove()
+			toStdout := exportOutput == "-"
+
+			if !toStdout {
+				ui.NewlineAbove()
+			}
+
+			if exportFormat != export.FormatCSV && exportFormat != export.FormatJSON {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("Unknown format '%s'. Use 'csv' or 'json'", exportFormat))
+				os.Exit(1)
+			}
+
+			if exportColumns != "" && exportFormat != export.FormatCSV {
+				ui.PrintError(ui.EmojiError, "--columns can only be used with the csv format")
+				os.Exit(1)
+			}
+
+			selectedColumns, err := export.ParseColumns(exportColumns)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			opts := export.Options{Columns: selectedColumns, Location: time.Local}
+			if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
+				opts.Location = globalCfg.Location()
+			}
 
 			db, err := storage.Initialize()
 			if err != nil {
@@ -39,41 +70,44 @@ func ExportCmd() *cobra.Command {
 
 			defer db.Close()
 
-			var entries []*storage.TimeEntry
+			filter := storage.EntryFilter{ProjectName: exportProject}
 
 			if exportMilestone != "" {
-				projectName, err := project.DetectConfiguredProject()
-				if err != nil {
-					ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
-					os.Exit(1)
+				if filter.ProjectName == "" {
+					filter.ProjectName, err = project.DetectConfiguredProject()
+					if err != nil {
+						ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
+						os.Exit(1)
+					}
 				}
-				entries, err = db.GetEntriesByMilestone(projectName, exportMilestone)
-			} else if exportToday {
-				start := time.Now().Truncate(24 * time.Hour)
-				end := start.Add(24 * time.Hour)
-				entries, err = db.GetEntriesByDateRange(start, end)
+				filter.MilestoneName = exportMilestone
+			}
+
+			if exportToday {
+				filter.Start = time.Now().Truncate(24 * time.Hour)
+				filter.End = filter.Start.Add(24 * time.Hour)
 			} else if exportWeek {
-				now := time.Now()
-				weekday := int(now.Weekday())
-				if weekday == 0 {
-					weekday = 7 // sunday
-				}
+				week := period.WeekStarting(time.Now(), settings.WeekStart())
+				filter.Start, filter.End = week.Start, week.End
+			}
 
-				start := now.AddDate(0, 0, -weekday+1).Truncate(24 * time.Hour)
-				end := start.AddDate(0, 0, 7)
-				entries, err = db.GetEntriesByDateRange(start, end)
-			} else if exportProject != "" {
-				entries, err = db.GetEntriesByProject(exportProject)
-			} else {
-				entries, err = db.GetEntries(0) // all
+			if toStdout {
+				// stream straight to stdout so the output can be piped; an empty
+				// result still produces a valid (empty) document
+				if _, err := streamExport(db, filter, os.Stdout, opts); err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+				return
 			}
 
+			count, err := db.CountEntries(filter)
 			if err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
 				os.Exit(1)
 			}
 
-			if len(entries) == 0 {
+			if count == 0 {
 				ui.PrintWarning(ui.EmojiWarning, "No entries to export.")
 				ui.NewlineBelow()
 				os.Exit(0)
@@ -109,19 +143,11 @@ func ExportCmd() *cobra.Command {
 			filename := exportOutput
 			if filename == "" {
 				timestamp := time.Now().Format("2006-01-02")
-				ext := "csv"
-
-				if exportFormat == "json" {
-					ext = "json"
-				}
-
-				filename = fmt.Sprintf("tmpo-export-%s.%s", timestamp, ext)
+				filename = fmt.Sprintf("tmpo-export-%s.%s", timestamp, exportFormat)
 			}
 
-			if exportFormat == "csv" && filepath.Ext(filename) != ".csv" {
-				filename += ".csv"
-			} else if exportFormat == "json" && filepath.Ext(filename) != ".json" {
-				filename += ".json"
+			if filepath.Ext(filename) != "."+exportFormat {
+				filename += "." + exportFormat
 			}
 
 			if exportPath != "" {
@@ -129,33 +155,56 @@ func ExportCmd() *cobra.Command {
 				filename = filepath.Join(exportPath, filepath.Base(filename))
 			}
 
-			switch exportFormat {
-			case "csv":
-				err = export.ToCSV(entries, filename)
-			case "json":
-				err = export.ToJson(entries, filename)
-			default:
-				ui.PrintError(ui.EmojiError, fmt.Sprintf("Unknown format '%s'. Use 'csv' or 'json'", exportFormat))
+			file, err := os.Create(filename)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("failed to create export file: %v", err))
 				os.Exit(1)
 			}
 
+			written, err := streamExport(db, filter, file, opts)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+
 			if err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
 				os.Exit(1)
 			}
 
-			ui.PrintSuccess(ui.EmojiExport, fmt.Sprintf("Exported %s to %s", ui.Bold(fmt.Sprintf("%d entries", len(entries))), ui.Bold(filename)))
+			ui.PrintSuccess(ui.EmojiExport, fmt.Sprintf("Exported %s to %s", ui.Bold(fmt.Sprintf("%d entries", written)), ui.Bold(filename)))
 
 			ui.NewlineBelow()
 		},
 	}
 
 	cmd.Flags().StringVarP(&exportFormat, "format", "f", "csv", "Export format (csv or json)")
-	cmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output filename")
+	cmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output filename (use - for stdout)")
 	cmd.Flags().StringVarP(&exportProject, "project", "p", "", "Filter by project")
 	cmd.Flags().StringVarP(&exportMilestone, "milestone", "m", "", "Filter by milestone")
 	cmd.Flags().BoolVarP(&exportToday, "today", "t", false, "Export today's entries")
 	cmd.Flags().BoolVarP(&exportWeek, "week", "w", false, "Export this week's entries")
+	cmd.Flags().StringVar(&exportColumns, "columns", "", fmt.Sprintf("Comma separated CSV columns to include, in order (%s)", strings.Join(export.ColumnKeys(), ", ")))
 
 	return cmd
 }
+
+// streamExport writes every entry matching the filter to w in the selected
+// format, reading rows from the database cursor one at a time.
+func streamExport(db *storage.Database, filter storage.EntryFilter, w io.Writer, opts export.Options) (int, error) {
+	writer, err := export.NewWriter(exportFormat, w, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	err = db.StreamEntries(filter, func(entry *storage.TimeEntry) error {
+		written++
+		return writer.Write(entry)
+	})
+
+	if err != nil {
+		return written, err
+	}
+
+	return written, writer.Close()
+}
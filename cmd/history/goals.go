@@ -0,0 +1,253 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/project"
+	"github.com/DylanDevelops/tmpo/internal/report"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// goalBarWidth is the width of the goal progress bars.
+const goalBarWidth = 20
+
+func GoalsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "goals",
+		Short: "Show progress and streaks of your goals",
+		Long: `Show the progress towards today's and this week's goals, and how many days or weeks in a row each goal has been met.
+
+Goals are target hours per day and per week. Goals in the global config count the time tracked on every project, and goals in .tmporc only the time tracked on that project. Days outside the working days (working_hours.days, Monday to Friday by default) without the daily goal met don't end a daily streak.
+
+Days off from 'tmpo off' and the holidays file have no daily goal and don't end a daily streak, and each working day off lowers the weekly goal by that day's share of the working week.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			goals := loadGoals()
+			if !goals.global.IsSet() && !goals.project.IsSet() {
+				ui.PrintWarning(ui.EmojiWarning, "No goals are set")
+				ui.PrintMuted(0, "Add daily or weekly goals to the global config or to .tmporc, e.g.")
+				ui.PrintMuted(4, "goals:")
+				ui.PrintMuted(4, "  daily: 6")
+				ui.PrintMuted(4, "  weekly: 30")
+				ui.NewlineBelow()
+				return
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			now := time.Now().In(goals.loc)
+			entries, err := db.GetEntriesByDateRange(time.Time{}, now)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			daysOff, err := loadDaysOff(db, goals.workingHours, time.Time{}, time.Time{})
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			workingDays, err := goals.workingHours.WorkingDays()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			progress := report.TrackGoals(entries, goals.global, goals.project, goals.projectName, daysOff, workingDays, goals.firstWeekday, now)
+
+			ui.PrintSuccess(ui.EmojiGoal, ui.Bold("Goals"))
+			ui.PrintInfo(4, ui.Bold("Progress"), "")
+			printGoalProgress(progress, daysOff.On(now))
+
+			fmt.Println()
+			ui.PrintInfo(4, ui.Bold("Streaks"), "")
+
+			type goal struct {
+				project, period string
+				target          time.Duration
+			}
+
+			var set []goal
+			for _, g := range []struct {
+				project string
+				goals   settings.Goals
+			}{{"", goals.global}, {goals.projectName, goals.project}} {
+				if g.goals.DailyTarget() > 0 {
+					set = append(set, goal{g.project, report.GoalDaily, g.goals.DailyTarget()})
+				}
+				if g.goals.WeeklyTarget() > 0 {
+					set = append(set, goal{g.project, report.GoalWeekly, g.goals.WeeklyTarget()})
+				}
+			}
+
+			labels := make([]string, len(set))
+			streaks := make([]string, len(set))
+			width := 0
+			for i, g := range set {
+				labels[i] = fmt.Sprintf("%s%s goal of %gh", strings.ToUpper(g.period[:1]), g.period[1:], g.target.Hours())
+				if g.project != "" {
+					labels[i] = fmt.Sprintf("%s (%s)", labels[i], g.project)
+				}
+				width = max(width, len([]rune(labels[i])))
+
+				var streak report.Streak
+				unit := "day"
+				if g.period == report.GoalDaily {
+					streak = report.DailyStreak(entries, g.project, g.target, daysOff, workingDays, goals.loc, now)
+				} else {
+					streak = report.WeeklyStreak(entries, g.project, g.target, daysOff, workingDays, goals.loc, goals.firstWeekday, now)
+					unit = "week"
+				}
+				streaks[i] = formatStreak(streak, unit)
+			}
+
+			for i := range set {
+				fmt.Printf("        %s  %s\n", labels[i]+strings.Repeat(" ", width-len([]rune(labels[i]))), streaks[i])
+			}
+
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
+
+// goalSettings are the goals that apply in the current directory.
+type goalSettings struct {
+	global settings.Goals
+	// project holds the goals of the .tmporc of projectName.
+	project      settings.Goals
+	projectName  string
+	loc          *time.Location
+	firstWeekday time.Weekday
+	// workingHours holds the working days and the holidays file.
+	workingHours settings.WorkingHours
+}
+
+// loadGoals reads the global goals and those of the current project's
+// .tmporc. Unreadable configs count as having no goals.
+func loadGoals() goalSettings {
+	goals := goalSettings{loc: time.Local, firstWeekday: time.Monday}
+
+	if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
+		goals.global = globalCfg.Goals
+		goals.loc = globalCfg.Location()
+		goals.firstWeekday = globalCfg.FirstWeekday()
+		goals.workingHours = globalCfg.WorkingHours
+	}
+
+	if cfg, _, err := settings.FindAndLoad(); err == nil && cfg != nil && cfg.Goals.IsSet() {
+		if projectName, err := project.DetectConfiguredProject(); err == nil {
+			goals.project = cfg.Goals
+			goals.projectName = projectName
+		}
+	}
+
+	return goals
+}
+
+// printGoals prints the progress towards today's and this week's goals, if
+// any are set.
+func printGoals(db *storage.Database) {
+	goals := loadGoals()
+	if !goals.global.IsSet() && !goals.project.IsSet() {
+		return
+	}
+
+	now := time.Now().In(goals.loc)
+	week := period.WeekStarting(now, goals.firstWeekday)
+
+	entries, err := db.GetEntriesByDateRange(week.Start, week.End)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	daysOff, err := loadDaysOff(db, goals.workingHours, week.Start, week.End)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	workingDays, err := goals.workingHours.WorkingDays()
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	ui.PrintInfo(4, ui.Bold("Goals"), "")
+	printGoalProgress(report.TrackGoals(entries, goals.global, goals.project, goals.projectName, daysOff, workingDays, goals.firstWeekday, now), daysOff.On(now))
+}
+
+// printGoalProgress prints a bar for each goal, after a note when today is
+// a day off.
+func printGoalProgress(progress []report.GoalProgress, today *storage.TimeOff) {
+	if today != nil {
+		ui.PrintMuted(8, fmt.Sprintf("Today is a day off: %s", formatDayOff(today)))
+	}
+
+	labels := make([]string, len(progress))
+	width := 0
+	for i, p := range progress {
+		labels[i] = "Today"
+		if p.Period == report.GoalWeekly {
+			labels[i] = "This week"
+		}
+		if p.Project != "" {
+			labels[i] = fmt.Sprintf("%s (%s)", labels[i], p.Project)
+		}
+		width = max(width, len([]rune(labels[i])))
+	}
+
+	for i, p := range progress {
+		fmt.Printf("        %s  %s  %s of %gh%s\n",
+			labels[i]+strings.Repeat(" ", width-len([]rune(labels[i]))),
+			ui.GoalBar(p.Fraction(), goalBarWidth),
+			ui.FormatDuration(p.Tracked),
+			p.Target.Hours(),
+			formatGoalDaysOff(p.DaysOff))
+	}
+}
+
+// formatGoalDaysOff notes the days off that lowered a weekly goal.
+func formatGoalDaysOff(days int) string {
+	switch days {
+	case 0:
+		return ""
+	case 1:
+		return ui.Muted(" (1 day off)")
+	}
+	return ui.Muted(fmt.Sprintf(" (%d days off)", days))
+}
+
+func formatStreak(streak report.Streak, unit string) string {
+	count := func(n int) string {
+		if n == 1 {
+			return "1 " + unit
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	current := ui.Muted("no streak")
+	if streak.Current > 0 {
+		current = ui.Success(count(streak.Current) + " in a row")
+	}
+
+	return current + ui.Muted(fmt.Sprintf(", longest %s", count(streak.Longest)))
+}
@@ -0,0 +1,15 @@
+package history
+
+import "github.com/spf13/cobra"
+
+func HistoryCmds() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Browse the history of changes",
+		Long:  `Browse the changes made to your entries and milestones.`,
+	}
+
+	cmd.AddCommand(OpsCmd())
+
+	return cmd
+}
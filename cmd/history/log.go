@@ -5,6 +5,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/DylanDevelops/tmpo/internal/period"
 	"github.com/DylanDevelops/tmpo/internal/project"
 	"github.com/DylanDevelops/tmpo/internal/settings"
 	"github.com/DylanDevelops/tmpo/internal/storage"
@@ -51,15 +52,8 @@ func LogCmd() *cobra.Command {
 				end := start.Add(24 * time.Hour)
 				entries, err = db.GetEntriesByDateRange(start, end)
 			} else if logWeek {
-				now := time.Now()
-				weekday := int(now.Weekday())
-				if weekday == 0 {
-					weekday = 7 // sunday
-				}
-
-				start := now.AddDate(0, 0, -weekday+1).Truncate(24 * time.Hour)
-				end := start.AddDate(0, 0, 7)
-				entries, err = db.GetEntriesByDateRange(start, end)
+				week := period.WeekStarting(time.Now(), settings.WeekStart())
+				entries, err = db.GetEntriesByDateRange(week.Start, week.End)
 			} else if logProject != "" {
 				entries, err = db.GetEntriesByProject(logProject)
 			} else {
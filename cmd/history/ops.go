@@ -0,0 +1,106 @@
+package history
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	opsLimit   int
+	opsVerbose bool
+)
+
+func OpsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ops",
+		Short: "List recent changes",
+		Long:  `List the most recent changes to your entries and milestones, newest first. Changes marked "undone" can be reapplied with 'tmpo redo'.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			ops, err := db.GetOperations(opsLimit)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if len(ops) == 0 {
+				ui.PrintWarning(ui.EmojiWarning, "No changes recorded yet")
+				ui.NewlineBelow()
+				return
+			}
+
+			ui.PrintSuccess(ui.EmojiHistory, fmt.Sprintf("Last %d change(s)", len(ops)))
+			fmt.Println()
+
+			for _, op := range ops {
+				status := ""
+				description := op.Description
+				switch op.Status {
+				case storage.OpStatusUndone:
+					status = ui.Warning(" (undone)")
+					description = ui.Muted(description)
+				case storage.OpStatusDiscarded:
+					status = ui.Muted(" (discarded)")
+					description = ui.Muted(description)
+				}
+
+				fmt.Printf("  %s  %s  %s  %s%s\n",
+					ui.Muted(fmt.Sprintf("#%-4d", op.ID)),
+					settings.FormatDateTimeDashed(op.CreatedAt),
+					ui.Bold(fmt.Sprintf("%-16s", op.Kind)),
+					description,
+					status)
+
+				if opsVerbose {
+					printChanges(op)
+				}
+			}
+
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().IntVarP(&opsLimit, "limit", "n", 20, "Number of changes to show")
+	cmd.Flags().BoolVarP(&opsVerbose, "verbose", "v", false, "Show the fields each change modified")
+
+	return cmd
+}
+
+func printChanges(op *storage.Operation) {
+	for _, change := range op.Changes {
+		what := change.Subject()
+
+		switch {
+		case change.Before == nil:
+			ui.PrintMuted(8, fmt.Sprintf("+ %s #%d created", what, change.RowID))
+		case change.After == nil:
+			ui.PrintMuted(8, fmt.Sprintf("- %s #%d deleted", what, change.RowID))
+		default:
+			ui.PrintMuted(8, fmt.Sprintf("~ %s #%d", what, change.RowID))
+			for _, column := range change.Diff() {
+				ui.PrintMuted(12, fmt.Sprintf("%s: %s → %s", column.Column, formatValue(column.Before), formatValue(column.After)))
+			}
+		}
+	}
+}
+
+func formatValue(value *string) string {
+	if value == nil {
+		return "(none)"
+	}
+	return fmt.Sprintf("%q", *value)
+}
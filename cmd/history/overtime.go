@@ -0,0 +1,189 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/report"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+)
+
+// ShowOvertime prints the overtime, undertime, weekend work and balance of
+// the period span, measured against the working hours in the global config.
+// Days off in the time-off calendar or the holidays file aren't expected to
+// be worked.
+func ShowOvertime(entries []*storage.TimeEntry, span period.Range, periodName string, db *storage.Database) {
+	globalCfg, err := settings.LoadGlobalConfig()
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	policy := globalCfg.WorkingHours
+	if !policy.IsSet() {
+		ui.PrintError(ui.EmojiError, "No working hours are set")
+		ui.PrintMuted(0, "Add a working-hours policy to the global config, e.g.")
+		ui.PrintMuted(4, "working_hours:")
+		ui.PrintMuted(4, "  daily: 8")
+		ui.PrintMuted(4, "  weekly: 40")
+		ui.NewlineBelow()
+		os.Exit(1)
+	}
+
+	daysOff, err := loadDaysOff(db, policy, span.Start, span.End)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	o, err := report.BuildOvertime(entries, span, policy, daysOff, time.Local, globalCfg.FirstWeekday(), time.Now())
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		ui.NewlineBelow()
+		os.Exit(1)
+	}
+
+	ui.PrintSuccess(ui.EmojiStats, fmt.Sprintf("Overtime for %s", ui.Bold(periodName)))
+	ui.PrintInfo(4, "Policy", formatPolicy(policy, globalCfg.FirstWeekday()))
+	workingDays, _ := policy.WorkingDays()
+	for _, run := range groupDaysOff(o.DaysOff, workingDays) {
+		span := period.Range{Start: run[0].Day, End: period.Day(run[len(run)-1].Day).End}
+		ui.PrintInfo(4, "Days Off", fmt.Sprintf("%s %s", formatSpan(span), ui.Muted(formatDayOff(run[0]))))
+	}
+	fmt.Println()
+
+	if len(o.Weeks) == 0 {
+		ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("%s hasn't started yet.", periodName))
+		ui.NewlineBelow()
+		return
+	}
+
+	ui.PrintInfo(4, ui.Bold("Tracked"), ui.FormatDuration(o.Tracked))
+	ui.PrintInfo(4, ui.Bold("Expected"), ui.FormatDuration(o.Expected))
+	ui.PrintInfo(4, ui.Bold("Overtime"), ui.FormatDuration(o.Overtime))
+	ui.PrintInfo(4, ui.Bold("Undertime"), ui.FormatDuration(o.Undertime))
+	ui.PrintInfo(4, ui.Bold("Balance"), colorBalance(o.Balance(), formatBalance(o.Balance())))
+	if o.Weekend > 0 {
+		ui.PrintInfo(4, ui.Bold("Weekend Work"), ui.FormatDuration(o.Weekend))
+	}
+	if o.TimeOff > 0 {
+		ui.PrintInfo(4, ui.Bold("Work on Days Off"), ui.FormatDuration(o.TimeOff))
+	}
+
+	fmt.Println()
+	ui.PrintInfo(4, ui.Bold("By Week"), "")
+
+	dates := make([]string, len(o.Weeks))
+	width := len("Week of")
+	for i, week := range o.Weeks {
+		dates[i] = settings.FormatDate(week.Start)
+		width = max(width, len(dates[i]))
+	}
+
+	fmt.Println(ui.Bold(fmt.Sprintf("        %-*s  %8s  %8s  %8s  %8s  %10s", width, "Week of", "Tracked", "Expected", "Balance", "Weekend", "Cumulative")))
+	for i, week := range o.Weeks {
+		fmt.Printf("        %-*s  %8.2f  %8.2f  %s  %8.2f  %s\n",
+			width, dates[i],
+			week.Tracked.Hours(),
+			week.Expected.Hours(),
+			colorBalance(week.Balance(), fmt.Sprintf("%+8.2f", week.Balance().Hours())),
+			week.Weekend.Hours(),
+			colorBalance(week.Cumulative, fmt.Sprintf("%+10.2f", week.Cumulative.Hours())))
+	}
+	ui.PrintMuted(8, "Times are in decimal hours; days after today aren't expected yet")
+
+	ui.NewlineBelow()
+}
+
+// loadDaysOff reads the days off from start up to end from the time-off
+// calendar, along with the holidays file of the working hours.
+func loadDaysOff(db *storage.Database, policy settings.WorkingHours, start, end time.Time) (report.DaysOff, error) {
+	calendar, err := db.GetTimeOff(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := policy.LoadHolidays()
+	if err != nil {
+		return nil, fmt.Errorf("working_hours: %w", err)
+	}
+
+	return report.NewDaysOff(calendar, holidays), nil
+}
+
+// groupDaysOff splits days off, in order, into runs of the same time off on
+// consecutive working days, so a week of vacation is listed once.
+func groupDaysOff(days []*storage.TimeOff, workingDays map[time.Weekday]bool) [][]*storage.TimeOff {
+	var runs [][]*storage.TimeOff
+	for _, off := range days {
+		if len(runs) > 0 {
+			run := runs[len(runs)-1]
+			last := run[len(run)-1]
+
+			// days that aren't worked in between don't split a run
+			next := last.Day.AddDate(0, 0, 1)
+			for next.Before(off.Day) && !workingDays[next.Weekday()] {
+				next = next.AddDate(0, 0, 1)
+			}
+
+			if off.Kind == last.Kind && off.Name == last.Name && next.Equal(off.Day) {
+				runs[len(runs)-1] = append(run, off)
+				continue
+			}
+		}
+		runs = append(runs, []*storage.TimeOff{off})
+	}
+	return runs
+}
+
+// formatDayOff names a day off and its type, e.g. "Christmas Day (holiday)".
+func formatDayOff(off *storage.TimeOff) string {
+	if off.Name == "" {
+		return off.Label()
+	}
+	return fmt.Sprintf("%s (%s)", off.Name, off.Kind)
+}
+
+// formatPolicy describes a working-hours policy, e.g. "8h a day, 40h a
+// week, Mon Tue Wed Thu Fri".
+func formatPolicy(policy settings.WorkingHours, firstWeekday time.Weekday) string {
+	var parts []string
+	if policy.Daily > 0 {
+		parts = append(parts, fmt.Sprintf("%gh a day", policy.Daily))
+	}
+	if policy.Weekly > 0 {
+		parts = append(parts, fmt.Sprintf("%gh a week", policy.Weekly))
+	}
+
+	workingDays, _ := policy.WorkingDays()
+	var days []string
+	for i := range 7 {
+		day := time.Weekday((int(firstWeekday) + i) % 7)
+		if workingDays[day] {
+			days = append(days, day.String()[:3])
+		}
+	}
+
+	return strings.Join(parts, ", ") + ", " + strings.Join(days, " ")
+}
+
+// formatBalance formats a duration with its sign, e.g. "+2h 0m 0s".
+func formatBalance(d time.Duration) string {
+	if d < 0 {
+		return "-" + ui.FormatDuration(-d)
+	}
+	return "+" + ui.FormatDuration(d)
+}
+
+// colorBalance colors text by whether balance is under the expected hours.
+func colorBalance(balance time.Duration, text string) string {
+	if balance < 0 {
+		return ui.Warning(text)
+	}
+	return ui.Success(text)
+}
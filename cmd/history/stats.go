@@ -2,12 +2,16 @@ package history
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/DylanDevelops/tmpo/internal/settings"
 	"github.com/DylanDevelops/tmpo/internal/currency"
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/report"
 	"github.com/DylanDevelops/tmpo/internal/storage"
 	"github.com/DylanDevelops/tmpo/internal/ui"
 	"github.com/spf13/cobra"
@@ -16,8 +20,16 @@ import (
 var (
 	statsToday bool
 	statsWeek bool
+	statsMonth bool
+	statsYear  bool
+	statsRange string
+	statsBy    []string
+	statsOvertime bool
 )
 
+// barWidth is the width of the bars charting each share of the time.
+const barWidth = 20
+
 func StatsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "stats",
@@ -34,24 +46,40 @@ func StatsCmd() *cobra.Command {
 
 			defer db.Close()
 
-			var start, end time.Time
-			var periodName string
+			for _, by := range statsBy {
+				if err := report.ValidateDimension(by); err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("--by: %v", err))
+					ui.NewlineBelow()
+					os.Exit(1)
+				}
+			}
+
+			now := time.Now()
+			var span period.Range
+			var periodName, previousName string
 
 			if statsToday {
-				now := time.Now()
-				start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-				end = start.Add(24 * time.Hour)
-				periodName = "Today"
+				span = period.Day(now)
+				periodName, previousName = "Today", "yesterday"
 			} else if statsWeek {
-				now := time.Now()
-				weekday := int(now.Weekday())
-				if weekday == 0 {
-					weekday = 7
+				span = period.WeekStarting(now, settings.WeekStart())
+				periodName, previousName = "This Week", "last week"
+			} else if statsMonth {
+				span = period.Month(now)
+				periodName, previousName = "This Month", "last month"
+			} else if statsYear {
+				span = period.Year(now)
+				periodName, previousName = "This Year", "last year"
+			} else if cmd.Flags().Changed("range") {
+				span, err = period.ParseWithWeekStart(statsRange, now, settings.WeekStart())
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("--range: %v", err))
+					os.Exit(1)
 				}
-
-				start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -weekday+1)
-				end = start.AddDate(0, 0, 7)
-				periodName = "This Week"
+				periodName = formatSpan(span)
+			} else if statsOvertime {
+				span = period.Month(now)
+				periodName = "This Month"
 			} else {
 				entries, err := db.GetEntries(0)
 				if err != nil {
@@ -59,27 +87,58 @@ func StatsCmd() *cobra.Command {
 					os.Exit(1)
 				}
 
-				ShowAllTimeStats(entries, db)
+				ShowAllTimeStats(entries, statsBy, db)
 				return
 			}
 
-			entries, err := db.GetEntriesByDateRange(start, end)
+			entries, err := db.GetEntriesByFilter(storage.EntryFilter{Start: span.Start, End: span.End})
 			if err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
 				os.Exit(1)
 			}
 
-			ShowPeriodStats(entries, periodName)
+			if statsOvertime {
+				ShowOvertime(entries, span, periodName, db)
+				return
+			}
+
+			// compare against the period of the same length just before, or
+			// as much of it as has passed of this one
+			var previous []*storage.TimeEntry
+			if previousSpan, ok := span.PreviousSoFar(now); ok {
+				if previousName == "" {
+					previousName = formatSpan(previousSpan)
+				}
+
+				previous, err = db.GetEntriesByFilter(storage.EntryFilter{Start: previousSpan.Start, End: previousSpan.End})
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+			}
+
+			ShowPeriodStats(entries, span, periodName, previous, previousName, statsBy, db)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&statsToday, "today", "t", false, "Show today's stats")
 	cmd.Flags().BoolVarP(&statsWeek, "week", "w", false, "Show this week's stats")
+	cmd.Flags().BoolVarP(&statsMonth, "month", "m", false, "Show this month's stats")
+	cmd.Flags().BoolVarP(&statsYear, "year", "y", false, "Show this year's stats")
+	cmd.Flags().StringVarP(&statsRange, "range", "r", "", "Show stats for a period (e.g. 2024-03, 2024-W11, last-month, 2024-01-01..2024-02-15)")
+	cmd.Flags().StringSliceVarP(&statsBy, "by", "b", nil, fmt.Sprintf("Break the time down by %s instead of by project", strings.Join(report.Dimensions, ", ")))
+	cmd.Flags().BoolVar(&statsOvertime, "overtime", false, "Report overtime against the working hours in the global config (this month by default)")
+	cmd.MarkFlagsMutuallyExclusive("today", "week", "month", "year", "range")
+	cmd.MarkFlagsMutuallyExclusive("overtime", "by")
 
 	return cmd
 }
 
-func ShowPeriodStats(entries []*storage.TimeEntry, periodName string) {
+// ShowPeriodStats prints the stats of the period span. When previousName is
+// set, the totals are compared with the previous entries, those of the period
+// before. The daily totals are charted, and the time is broken down by
+// project and milestone, or by the dimensions in by when there are any.
+func ShowPeriodStats(entries []*storage.TimeEntry, span period.Range, periodName string, previous []*storage.TimeEntry, previousName string, by []string, db *storage.Database) {
 	if len(entries) == 0 {
 		ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("No entries for %s.", periodName))
 		ui.NewlineBelow()
@@ -107,13 +166,43 @@ func ShowPeriodStats(entries []*storage.TimeEntry, periodName string) {
 
 	currencyCode := getCurrencyCode()
 
+	var previousDuration time.Duration
+	var previousEarnings float64
+	for _, entry := range previous {
+		previousDuration += entry.Duration()
+		if entry.HourlyRate != nil {
+			previousEarnings += entry.RoundedHours() * *entry.HourlyRate
+		}
+	}
+
 	ui.PrintSuccess(ui.EmojiStats, fmt.Sprintf("Stats for %s", ui.Bold(periodName)))
 	fmt.Println()
-	ui.PrintInfo(4, ui.Bold("Total Time"), fmt.Sprintf("%s (%.2f hours)", ui.FormatDuration(totalDuration), totalDuration.Hours()))
+
+	totalTime := fmt.Sprintf("%s (%.2f hours)", ui.FormatDuration(totalDuration), totalDuration.Hours())
+	if previousName != "" {
+		totalTime += "  " + ui.Muted(formatChange(totalDuration.Hours(), previousDuration.Hours(), previousName))
+	}
+	ui.PrintInfo(4, ui.Bold("Total Time"), totalTime)
 	ui.PrintInfo(4, ui.Bold("Total Entries"), fmt.Sprintf("%d", len(entries)))
 
 	if hasAnyEarnings {
-		ui.PrintInfo(4, ui.Bold("Earnings"), currency.FormatCurrency(totalEarnings, currencyCode))
+		earnings := currency.FormatCurrency(totalEarnings, currencyCode)
+		if previousName != "" && previousEarnings > 0 {
+			earnings += "  " + ui.Muted(formatChange(totalEarnings, previousEarnings, previousName))
+		}
+		ui.PrintInfo(4, ui.Bold("Earnings"), earnings)
+	}
+
+	if span.Contains(time.Now()) {
+		printGoals(db)
+	}
+
+	printActivity(entries, span)
+
+	if len(by) > 0 {
+		printGroupedStats(entries, by, totalDuration, currencyCode)
+		ui.NewlineBelow()
+		return
 	}
 
 	fmt.Println()
@@ -128,17 +217,19 @@ func ShowPeriodStats(entries []*storage.TimeEntry, periodName string) {
 	for _, project := range projects {
 		duration := projectStats[project]
 		percentage := (duration.Seconds() / totalDuration.Seconds()) * 100
-		fmt.Printf("        %s  %s  (%.1f%%)\n", ui.Bold(fmt.Sprintf("%-20s", project)), ui.FormatDuration(duration), percentage)
+		fmt.Printf("        %s  %s  %s  (%.1f%%)\n", ui.Bold(fmt.Sprintf("%-20s", project)), ui.Info(ui.Bar(percentage/100, barWidth)), ui.FormatDuration(duration), percentage)
 
 		if earnings, ok := projectEarnings[project]; ok && earnings > 0 {
 			fmt.Printf("        %s %s\n", ui.Muted("└─ Earnings:"), currency.FormatCurrency(earnings, currencyCode))
 		}
 	}
 
+	printMilestoneStats(db, entries, totalDuration)
+
 	ui.NewlineBelow()
 }
 
-func ShowAllTimeStats(entries []*storage.TimeEntry, db *storage.Database) {
+func ShowAllTimeStats(entries []*storage.TimeEntry, by []string, db *storage.Database) {
 	if len(entries) == 0 {
 		ui.PrintWarning(ui.EmojiWarning, "No entries found.")
 		ui.NewlineBelow()
@@ -176,6 +267,14 @@ func ShowAllTimeStats(entries []*storage.TimeEntry, db *storage.Database) {
 		ui.PrintInfo(4, ui.Bold("Earnings"), currency.FormatCurrency(totalEarnings, currencyCode))
 	}
 
+	printGoals(db)
+
+	if len(by) > 0 {
+		printGroupedStats(entries, by, totalDuration, currencyCode)
+		ui.NewlineBelow()
+		return
+	}
+
 	fmt.Println()
 	ui.PrintInfo(4, ui.Bold("By Project"), "")
 
@@ -188,16 +287,170 @@ func ShowAllTimeStats(entries []*storage.TimeEntry, db *storage.Database) {
 	for _, project := range projects {
 		duration := projectStats[project]
 		percentage := (duration.Seconds() / totalDuration.Seconds()) * 100
-		fmt.Printf("        %s  %s  (%.1f%%)\n", ui.Bold(fmt.Sprintf("%-20s", project)), ui.FormatDuration(duration), percentage)
+		fmt.Printf("        %s  %s  %s  (%.1f%%)\n", ui.Bold(fmt.Sprintf("%-20s", project)), ui.Info(ui.Bar(percentage/100, barWidth)), ui.FormatDuration(duration), percentage)
 
 		if earnings, ok := projectEarnings[project]; ok && earnings > 0 {
 			fmt.Printf("        %s %s\n", ui.Muted("└─ Earnings:"), currency.FormatCurrency(earnings, currencyCode))
 		}
 	}
 
+	printMilestoneStats(db, entries, totalDuration)
+
 	ui.NewlineBelow()
 }
 
+// printGroupedStats prints a table of the time per group for each dimension
+// in by.
+func printGroupedStats(entries []*storage.TimeEntry, by []string, totalDuration time.Duration, currencyCode string) {
+	for _, dimension := range by {
+		groups, err := report.GroupBy(entries, dimension, time.Local, settings.WeekStart(), time.Now())
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+
+		labels := make([]string, len(groups))
+		durations := make([]string, len(groups))
+		labelWidth, durationWidth := 0, 0
+		for i, group := range groups {
+			labels[i] = group.Label
+			if runes := []rune(labels[i]); len(runes) > 40 {
+				labels[i] = string(runes[:39]) + "…"
+			}
+			durations[i] = ui.FormatDuration(group.Duration)
+
+			labelWidth = max(labelWidth, len([]rune(labels[i])))
+			durationWidth = max(durationWidth, len(durations[i]))
+		}
+
+		fmt.Println()
+		ui.PrintInfo(4, ui.Bold("By "+strings.ToUpper(dimension[:1])+dimension[1:]), "")
+
+		for i, group := range groups {
+			label := labels[i] + strings.Repeat(" ", labelWidth-len([]rune(labels[i])))
+			if group.None {
+				label = ui.Muted(label)
+			} else {
+				label = ui.Bold(label)
+			}
+
+			percentage := (group.Duration.Seconds() / totalDuration.Seconds()) * 100
+			line := fmt.Sprintf("        %s  %s  %-*s  %5.1f%%  %s", label, ui.Info(ui.Bar(percentage/100, barWidth)), durationWidth, durations[i], percentage, ui.Muted(entriesLabel(group.Entries)))
+			if group.Earnings > 0 {
+				line += "  " + currency.FormatCurrency(group.Earnings, currencyCode)
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+// printActivity charts the time tracked on each day of span: a sparkline of
+// the days of periods up to two months long, a calendar heatmap of periods
+// up to a year long, and a sparkline of the weeks of longer periods. Open
+// periods and single days aren't charted.
+func printActivity(entries []*storage.TimeEntry, span period.Range) {
+	if span.Start.IsZero() || span.End.IsZero() {
+		return
+	}
+
+	tracked := make(map[string]time.Duration)
+	for _, entry := range entries {
+		tracked[entry.StartTime.In(time.Local).Format("2006-01-02")] += entry.Duration()
+	}
+	daily := func(day time.Time) time.Duration {
+		return tracked[day.Format("2006-01-02")]
+	}
+
+	start := period.Day(span.Start).Start
+	days := int(math.Round(span.End.Sub(start).Hours() / 24))
+
+	// days that haven't happened yet are left off
+	end := span.End
+	if tomorrow := period.Day(time.Now()).End; tomorrow.Before(end) {
+		end = tomorrow
+	}
+
+	switch {
+	case days <= 1:
+		return
+
+	case days <= 62:
+		var values []float64
+		var busiest time.Time
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			values = append(values, daily(day).Hours())
+			if daily(day) > daily(busiest) {
+				busiest = day
+			}
+		}
+		if len(values) < 2 || busiest.IsZero() {
+			return
+		}
+
+		note := fmt.Sprintf("busiest %s, %s", settings.FormatDate(busiest), ui.FormatDuration(daily(busiest)))
+		fmt.Println()
+		ui.PrintInfo(4, ui.Bold("Daily"), ui.Success(ui.Sparkline(values))+"  "+ui.Muted(note))
+
+	case days <= 371:
+		fmt.Println()
+		ui.PrintInfo(4, ui.Bold("Activity"), "")
+		for _, line := range ui.Heatmap(start, end, settings.WeekStart(), func(day time.Time) float64 { return daily(day).Hours() }) {
+			fmt.Printf("        %s\n", line)
+		}
+
+	default:
+		var values []float64
+		for week := period.WeekStarting(start, settings.WeekStart()).Start; week.Before(end); week = week.AddDate(0, 0, 7) {
+			var total time.Duration
+			for day := week; day.Before(week.AddDate(0, 0, 7)); day = day.AddDate(0, 0, 1) {
+				total += daily(day)
+			}
+			values = append(values, total.Hours())
+		}
+
+		fmt.Println()
+		ui.PrintInfo(4, ui.Bold("Weekly"), ui.Success(ui.Sparkline(values)))
+	}
+}
+
+func entriesLabel(count int) string {
+	if count == 1 {
+		return "1 entry"
+	}
+	return fmt.Sprintf("%d entries", count)
+}
+
+// formatChange describes how current compares with previous, e.g. "+12% vs
+// last month".
+func formatChange(current, previous float64, previousName string) string {
+	if previous == 0 {
+		return fmt.Sprintf("nothing tracked %s", previousName)
+	}
+
+	change := math.Round((current - previous) / previous * 100)
+	if change == 0 {
+		return fmt.Sprintf("same as %s", previousName)
+	}
+
+	return fmt.Sprintf("%+.0f%% vs %s", change, previousName)
+}
+
+// formatSpan describes a period by its first and last day.
+func formatSpan(r period.Range) string {
+	lastDay := r.End.Add(-time.Nanosecond)
+
+	switch {
+	case r.Start.IsZero():
+		return "until " + settings.FormatDate(lastDay)
+	case r.End.IsZero():
+		return "since " + settings.FormatDate(r.Start)
+	case period.Day(r.Start).End.Equal(r.End):
+		return settings.FormatDate(r.Start)
+	default:
+		return settings.FormatDate(r.Start) + " – " + settings.FormatDate(lastDay)
+	}
+}
+
 func getCurrencyCode() string {
 	globalCfg, err := settings.LoadGlobalConfig()
 	if err != nil {
@@ -205,3 +458,82 @@ func getCurrencyCode() string {
 	}
 	return globalCfg.Currency
 }
+
+// printMilestoneStats prints the time per milestone, with the time of
+// nested milestones rolled up into their parents.
+func printMilestoneStats(db *storage.Database, entries []*storage.TimeEntry, totalDuration time.Duration) {
+	tracked := make(map[string]map[string]time.Duration)
+	for _, entry := range entries {
+		if entry.MilestoneName == nil {
+			continue
+		}
+
+		if tracked[entry.ProjectName] == nil {
+			tracked[entry.ProjectName] = make(map[string]time.Duration)
+		}
+		tracked[entry.ProjectName][*entry.MilestoneName] += entry.Duration()
+	}
+
+	if len(tracked) == 0 {
+		return
+	}
+
+	var projects []string
+	for project := range tracked {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+
+	fmt.Println()
+	ui.PrintInfo(4, ui.Bold("By Milestone"), "")
+
+	for _, project := range projects {
+		own := tracked[project]
+		fmt.Printf("        %s\n", ui.Bold(project))
+
+		milestones, err := db.GetMilestonesByProject(project)
+		if err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+
+		listed := make(map[string]bool)
+		for _, root := range storage.BuildMilestoneTree(milestones) {
+			root.Walk(func(node *storage.MilestoneNode) {
+				var rolled time.Duration
+				node.Walk(func(n *storage.MilestoneNode) {
+					rolled += own[n.Milestone.Name]
+					listed[n.Milestone.Name] = true
+				})
+
+				if rolled > 0 {
+					printMilestoneStat(node.Milestone.Name, node.Depth, rolled, totalDuration)
+				}
+			})
+		}
+
+		// entries can name a milestone that no longer exists
+		var unknown []string
+		for name := range own {
+			if !listed[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+
+		for _, name := range unknown {
+			printMilestoneStat(name, 0, own[name], totalDuration)
+		}
+	}
+}
+
+func printMilestoneStat(name string, depth int, duration, totalDuration time.Duration) {
+	label := name
+	if depth > 0 {
+		label = "└─ " + name
+	}
+
+	width := max(20-2*depth, len(label))
+	percentage := (duration.Seconds() / totalDuration.Seconds()) * 100
+	fmt.Printf("          %s%-*s  %s  (%.1f%%)\n", strings.Repeat("  ", depth), width, label, ui.FormatDuration(duration), percentage)
+}
@@ -0,0 +1,280 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/export"
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/report"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	timesheetWeek   string
+	timesheetBy     string
+	timesheetFormat string
+	timesheetOutput string
+)
+
+// timesheetLabelLimit is how wide the row labels of a timesheet may get.
+const timesheetLabelLimit = 30
+
+func TimesheetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "timesheet",
+		Short: "Show a weekly timesheet",
+		Long: `Show the time tracked in a week as a grid, with a row per project and a column per day, and the totals of each row and day. Rows can be milestones or descriptions instead.
+
+The week defaults to this week. Pass an ISO week such as 2026-W41, last-week, or any date in the week. Days off from 'tmpo off' and the holidays file are marked.
+
+Pass --format or --output to save the timesheet as CSV or JSON instead. Use '--output -' to write to stdout.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			exporting := cmd.Flags().Changed("format") || cmd.Flags().Changed("output")
+			toStdout := timesheetOutput == "-"
+
+			if !toStdout {
+				ui.NewlineAbove()
+			}
+
+			format := timesheetFormat
+			if !cmd.Flags().Changed("format") {
+				format = export.FormatCSV
+				if strings.EqualFold(filepath.Ext(timesheetOutput), ".json") {
+					format = export.FormatJSON
+				}
+			}
+
+			if format != export.FormatCSV && format != export.FormatJSON {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("Unknown format '%s'. Use 'csv' or 'json'", format))
+				os.Exit(1)
+			}
+
+			loc := time.Local
+			var policy settings.WorkingHours
+			if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
+				loc = globalCfg.Location()
+				policy = globalCfg.WorkingHours
+			}
+
+			now := time.Now().In(loc)
+			week, err := parseWeek(timesheetWeek, now)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("--week: %v", err))
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			entries, err := db.GetEntriesByFilter(storage.EntryFilter{Start: week.Start, End: week.End})
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			daysOff, err := loadDaysOff(db, policy, week.Start, week.End)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ts, err := report.BuildTimesheet(entries, week, timesheetBy, daysOff, loc, settings.WeekStart(), now)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("--by: %v", err))
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			if !exporting {
+				printTimesheet(ts)
+				ui.NewlineBelow()
+				return
+			}
+
+			if toStdout {
+				if err := export.WriteTimesheet(format, os.Stdout, ts); err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+				return
+			}
+
+			filename := timesheetOutput
+			if filename == "" {
+				year, number := weekNumber(week)
+				filename = fmt.Sprintf("tmpo-timesheet-%d-W%02d.%s", year, number, format)
+			}
+
+			if filepath.Ext(filename) != "."+format {
+				filename += "." + format
+			}
+
+			file, err := os.Create(filename)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("failed to create timesheet file: %v", err))
+				os.Exit(1)
+			}
+
+			err = export.WriteTimesheet(format, file, ts)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiExport, fmt.Sprintf("Saved timesheet for %s to %s", ui.Bold(formatSpan(week)), ui.Bold(filename)))
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().StringVarP(&timesheetWeek, "week", "w", "this-week", "Week to show (e.g. 2026-W41, last-week or a date in the week)")
+	cmd.Flags().StringVarP(&timesheetBy, "by", "b", report.ByProject, fmt.Sprintf("Rows of the timesheet (%s, %s or %s)", report.ByProject, report.ByMilestone, report.ByDescription))
+	cmd.Flags().StringVarP(&timesheetFormat, "format", "f", export.FormatCSV, "Export format (csv or json)")
+	cmd.Flags().StringVarP(&timesheetOutput, "output", "o", "", "Output filename (use - for stdout)")
+
+	return cmd
+}
+
+// parseWeek returns the week, starting on the configured day, that spec
+// falls in. Specs longer than a week are rejected.
+func parseWeek(spec string, now time.Time) (period.Range, error) {
+	span, err := period.ParseWithWeekStart(spec, now, settings.WeekStart())
+	if err != nil {
+		return period.Range{}, err
+	}
+
+	if span.Start.IsZero() || span.End.IsZero() {
+		return period.Range{}, fmt.Errorf("'%s' isn't a week", spec)
+	}
+
+	week := period.WeekStarting(span.Start, settings.WeekStart())
+	if span.End.After(week.End) {
+		return period.Range{}, fmt.Errorf("'%s' is longer than a week", spec)
+	}
+
+	return week, nil
+}
+
+// weekNumber returns the ISO year and number of the week holding the Monday
+// of week, whichever day it starts on.
+func weekNumber(week period.Range) (int, int) {
+	// the Monday is at most six days in, so the fourth day shares its ISO week
+	return week.Start.AddDate(0, 0, 3).ISOWeek()
+}
+
+func printTimesheet(ts *report.Timesheet) {
+	year, number := weekNumber(ts.Span)
+	ui.PrintSuccess(ui.EmojiLog, fmt.Sprintf("Timesheet for %s", ui.Bold(fmt.Sprintf("Week %d, %d", number, year))))
+	ui.PrintInfo(4, "Period", formatSpan(ts.Span))
+	fmt.Println()
+
+	if len(ts.Rows) == 0 {
+		ui.PrintWarning(ui.EmojiWarning, "No time tracked this week.")
+		printTimesheetDaysOff(ts)
+		return
+	}
+
+	labels := make([]string, len(ts.Rows))
+	width := len(ts.By)
+	for i, row := range ts.Rows {
+		labels[i] = row.Label
+		if runes := []rune(labels[i]); len(runes) > timesheetLabelLimit {
+			labels[i] = string(runes[:timesheetLabelLimit-1]) + "…"
+		}
+		width = max(width, len([]rune(labels[i])))
+	}
+	if hasDaysOff(ts) {
+		width = max(width, len("Time off"))
+	}
+
+	pad := func(label string) string {
+		return label + strings.Repeat(" ", width-len([]rune(label)))
+	}
+	cell := func(d time.Duration) string {
+		if d == 0 {
+			return ui.Muted(fmt.Sprintf("%7s", "-"))
+		}
+		return fmt.Sprintf("%7.2f", d.Hours())
+	}
+
+	header := "    " + pad(strings.ToUpper(ts.By[:1])+ts.By[1:])
+	for _, day := range ts.Days {
+		header += fmt.Sprintf(" %7s", day.Format("Mon 02"))
+	}
+	fmt.Println(ui.Bold(header + fmt.Sprintf(" %7s", "Total")))
+
+	for i, row := range ts.Rows {
+		label := pad(labels[i])
+		if row.None {
+			label = ui.Muted(label)
+		}
+
+		line := "    " + label
+		for _, d := range row.Days {
+			line += " " + cell(d)
+		}
+		fmt.Println(line + " " + ui.Bold(fmt.Sprintf("%7.2f", row.Total.Hours())))
+	}
+
+	fmt.Println("    " + ui.Muted(strings.Repeat("─", width+8*(len(ts.Days)+1))))
+
+	line := "    " + ui.Bold(pad("Total"))
+	for _, d := range ts.DayTotals {
+		line += " " + cell(d)
+	}
+	fmt.Println(line + " " + ui.Bold(fmt.Sprintf("%7.2f", ts.Total.Hours())))
+
+	if hasDaysOff(ts) {
+		line = "    " + ui.Muted(pad("Time off"))
+		for _, off := range ts.DaysOff {
+			kind := "-"
+			if off != nil {
+				kind = off.Kind
+			}
+			line += " " + ui.Muted(fmt.Sprintf("%7.7s", kind))
+		}
+		fmt.Println(line)
+	}
+
+	ui.PrintMuted(4, "Times are in decimal hours")
+	printTimesheetDaysOff(ts)
+}
+
+func hasDaysOff(ts *report.Timesheet) bool {
+	for _, off := range ts.DaysOff {
+		if off != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// printTimesheetDaysOff lists the days off in the timesheet's week.
+func printTimesheetDaysOff(ts *report.Timesheet) {
+	if !hasDaysOff(ts) {
+		return
+	}
+
+	fmt.Println()
+	for _, off := range ts.DaysOff {
+		if off != nil {
+			ui.PrintInfo(4, "Day Off", fmt.Sprintf("%s %s", off.Day.Format("Mon 02"), ui.Muted(formatDayOff(off))))
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package maintenance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func BackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup [path]",
+		Short: "Back up the database",
+		Long:  `Write a consistent copy of the tmpo database to the given file or directory. Without a path, the backup is stored in the tmpo backups directory.`,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			filename := fmt.Sprintf("tmpo-backup-%s.db", time.Now().Format("20060102-150405"))
+
+			var dest string
+			if len(args) > 0 {
+				dest = args[0]
+				if info, err := os.Stat(dest); err == nil && info.IsDir() {
+					dest = filepath.Join(dest, filename)
+				}
+			} else {
+				dir, err := storage.BackupDir()
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+				dest = filepath.Join(dir, filename)
+			}
+
+			if err := db.Backup(dest); err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiBackup, fmt.Sprintf("Backed up database to %s", ui.Bold(dest)))
+			ui.PrintMuted(4, "└─ Restore it with 'tmpo db restore <path>'")
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
+
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,17 @@
+package maintenance
+
+import "github.com/spf13/cobra"
+
+func DBCmds() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Back up and restore the tmpo database",
+		Long:  `Create backups of the tmpo database, restore from a backup, and browse the automatic snapshots taken before destructive operations.`,
+	}
+
+	cmd.AddCommand(BackupCmd())
+	cmd.AddCommand(RestoreCmd())
+	cmd.AddCommand(SnapshotsCmd())
+
+	return cmd
+}
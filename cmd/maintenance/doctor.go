@@ -0,0 +1,214 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+var repairLabels = map[storage.RepairAction]string{
+	storage.RepairStopRunning:     "Stop the older entries when the next entry started",
+	storage.RepairSwapTimes:       "Swap the start and end times",
+	storage.RepairDeleteEntry:     "Delete the entry",
+	storage.RepairCreateMilestone: "Create the missing milestone",
+	storage.RepairClearMilestone:  "Remove the milestone from these entries",
+	storage.RepairTrimEarlier:     "Trim the earlier entry around the later one",
+	storage.RepairDeleteLater:     "Delete the later entry",
+}
+
+func DoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the database for problems and repair them",
+		Long: `Scan the tmpo database for entries that were never stopped, entries that end before they start,
+entries assigned to milestones that don't exist, and overlapping entries.
+
+Each problem can be repaired interactively, or use --fix to apply the default repair to all of them.
+A snapshot of the database is taken before the first repair.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			issues, err := db.CheckIntegrity()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if len(issues) == 0 {
+				ui.PrintSuccess(ui.EmojiSuccess, "No problems found")
+				ui.NewlineBelow()
+				return
+			}
+
+			ui.PrintWarning(ui.EmojiDoctor, fmt.Sprintf("Found %d problem(s)", len(issues)))
+			fmt.Println()
+
+			for _, issue := range issues {
+				printIssue(issue)
+			}
+
+			if !doctorFix && !ui.IsInteractive() {
+				ui.PrintMuted(0, "Run 'tmpo doctor --fix' to apply the default repairs.")
+				ui.NewlineBelow()
+				return
+			}
+
+			repaired, skipped := 0, 0
+			snapshotTaken := false
+			// issues already handled, so a repair that doesn't clear one can't loop forever
+			handled := make(map[string]bool)
+
+			for {
+				issues, err := db.CheckIntegrity()
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				var issue *storage.IntegrityIssue
+				for i := range issues {
+					if !handled[issues[i].Key()] {
+						issue = &issues[i]
+						break
+					}
+				}
+
+				if issue == nil {
+					break
+				}
+				handled[issue.Key()] = true
+
+				action := issue.Actions()[0]
+				if !doctorFix {
+					var ok bool
+					action, ok = promptRepair(*issue)
+					if !ok {
+						skipped++
+						continue
+					}
+				}
+
+				if !snapshotTaken {
+					snapshot, err := db.Snapshot("doctor")
+					if err != nil {
+						ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+						os.Exit(1)
+					}
+					if snapshot != "" {
+						ui.PrintMuted(0, fmt.Sprintf("Snapshot saved to %s", snapshot))
+					}
+					snapshotTaken = true
+				}
+
+				if err := db.Repair(*issue, action); err != nil {
+					var skip *storage.RepairSkippedError
+					if errors.As(err, &skip) {
+						ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("Skipped: %s", skip.Reason))
+						skipped++
+						continue
+					}
+
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				repaired++
+			}
+
+			fmt.Println()
+			ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Repaired %d problem(s)", repaired))
+			if skipped > 0 {
+				ui.PrintMuted(4, fmt.Sprintf("└─ Skipped %d", skipped))
+			}
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().BoolVar(&doctorFix, "fix", false, "Apply the default repair to every problem without prompting")
+
+	return cmd
+}
+
+func printIssue(issue storage.IntegrityIssue) {
+	switch issue.Kind {
+	case storage.IssueMultipleRunning:
+		ui.PrintInfo(0, ui.Bold(fmt.Sprintf("%d entries are running at the same time", len(issue.Entries))), "")
+	case storage.IssueEndBeforeStart:
+		ui.PrintInfo(0, ui.Bold(fmt.Sprintf("Entry #%d ends before it starts", issue.Entries[0].ID)), "")
+	case storage.IssueMissingMilestone:
+		ui.PrintInfo(0, ui.Bold(fmt.Sprintf("Milestone '%s' in %s does not exist", issue.MilestoneName, issue.ProjectName)), "")
+	case storage.IssueOverlap:
+		ui.PrintInfo(0, ui.Bold(fmt.Sprintf("Entries #%d and #%d overlap", issue.Entries[0].ID, issue.Entries[1].ID)), "")
+	}
+
+	for _, entry := range issue.Entries {
+		end := "running"
+		if entry.EndTime != nil {
+			end = settings.FormatDateTimeDashed(*entry.EndTime)
+		}
+
+		line := fmt.Sprintf("#%d  %s  %s → %s", entry.ID, entry.ProjectName, settings.FormatDateTimeDashed(entry.StartTime), end)
+		if entry.Description != "" {
+			line += fmt.Sprintf("  %s", entry.Description)
+		}
+		ui.PrintMuted(4, line)
+	}
+
+	fmt.Println()
+}
+
+// promptRepair asks which repair to apply. It returns false when the
+// problem should be skipped.
+func promptRepair(issue storage.IntegrityIssue) (storage.RepairAction, bool) {
+	actions := issue.Actions()
+
+	var items []string
+	for _, action := range actions {
+		items = append(items, repairLabels[action])
+	}
+	items = append(items, "Skip")
+
+	label := "Repair"
+	switch issue.Kind {
+	case storage.IssueMultipleRunning:
+		label = fmt.Sprintf("%d running entries", len(issue.Entries))
+	case storage.IssueEndBeforeStart:
+		label = fmt.Sprintf("Entry #%d ends before it starts", issue.Entries[0].ID)
+	case storage.IssueMissingMilestone:
+		label = fmt.Sprintf("Missing milestone '%s'", issue.MilestoneName)
+	case storage.IssueOverlap:
+		label = fmt.Sprintf("Entries #%d and #%d overlap", issue.Entries[0].ID, issue.Entries[1].ID)
+	}
+
+	repairPrompt := promptui.Select{
+		Label: label,
+		Items: items,
+	}
+
+	idx, _, err := repairPrompt.Run()
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	if idx == len(actions) {
+		return "", false
+	}
+
+	return actions[idx], true
+}
@@ -0,0 +1,108 @@
+package maintenance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+var restoreYes bool
+
+func RestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore [path]",
+		Short: "Restore the database from a backup",
+		Long:  `Replace the tmpo database with a backup. Without a path, choose from the backups directory. The current data is snapshotted first so a restore can itself be undone.`,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			var src string
+			if len(args) > 0 {
+				src = args[0]
+			} else {
+				snapshots, err := storage.ListSnapshots()
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				if len(snapshots) == 0 {
+					ui.PrintError(ui.EmojiError, "No backups found")
+					ui.PrintMuted(0, "Pass the path of a backup file: 'tmpo db restore <path>'")
+					ui.NewlineBelow()
+					os.Exit(1)
+				}
+
+				var items []string
+				for _, snapshot := range snapshots {
+					items = append(items, fmt.Sprintf("%s (%s)", filepath.Base(snapshot.Path), settings.FormatDateTimeDashed(snapshot.CreatedAt)))
+				}
+
+				snapshotPrompt := promptui.Select{
+					Label: "Select backup to restore",
+					Items: items,
+				}
+
+				idx, _, err := snapshotPrompt.Run()
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				src = snapshots[idx].Path
+			}
+
+			if !restoreYes {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("This will replace all current data with %s", src))
+				fmt.Println()
+
+				confirmPrompt := promptui.Select{
+					Label: "Restore this backup?",
+					Items: []string{"No", "Yes"},
+				}
+
+				_, result, err := confirmPrompt.Run()
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				if result == "No" {
+					ui.PrintWarning(ui.EmojiWarning, "Restore cancelled")
+					ui.NewlineBelow()
+					os.Exit(0)
+				}
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			// RestoreFrom closes the database itself
+			snapshot, err := db.RestoreFrom(src)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Restored database from %s", ui.Bold(src)))
+			if snapshot != "" {
+				ui.PrintMuted(4, fmt.Sprintf("└─ Previous data saved to %s", snapshot))
+			}
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().BoolVarP(&restoreYes, "yes", "y", false, "Skip the confirmation prompt")
+
+	return cmd
+}
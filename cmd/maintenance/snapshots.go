@@ -0,0 +1,55 @@
+package maintenance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func SnapshotsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshots",
+		Short: "List backups and automatic snapshots",
+		Long:  `List the backups in the tmpo backups directory, including the snapshots taken automatically before deletes and schema migrations.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			snapshots, err := storage.ListSnapshots()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if len(snapshots) == 0 {
+				ui.PrintWarning(ui.EmojiWarning, "No backups found")
+				ui.NewlineBelow()
+				return
+			}
+
+			dir, _ := storage.BackupDir()
+			ui.PrintSuccess(ui.EmojiBackup, fmt.Sprintf("Backups in %s", ui.Bold(dir)))
+			fmt.Println()
+
+			for _, snapshot := range snapshots {
+				kind := "manual"
+				if snapshot.Automatic {
+					kind = "auto"
+				}
+
+				fmt.Printf("  %s  %s  %s\n",
+					ui.Bold(fmt.Sprintf("%-45s", filepath.Base(snapshot.Path))),
+					settings.FormatDateTimeDashed(snapshot.CreatedAt),
+					ui.Muted(fmt.Sprintf("%s, %s", kind, formatSize(snapshot.Size))))
+			}
+
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,155 @@
+package milestones
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/currency"
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// progressBarWidth is the width of the budget bars in status and list.
+const progressBarWidth = 20
+
+// planFlags holds the budget flags shared by start and edit.
+type planFlags struct {
+	hours  float64
+	amount float64
+	due    string
+}
+
+func addPlanFlags(cmd *cobra.Command, flags *planFlags) {
+	cmd.Flags().Float64Var(&flags.hours, "budget-hours", 0, "Hour budget for the milestone (0 removes it)")
+	cmd.Flags().Float64Var(&flags.amount, "budget", 0, "Money budget for the milestone, in your currency (0 removes it)")
+	cmd.Flags().StringVar(&flags.due, "due", "", "Day the milestone is due (YYYY-MM-DD, empty removes it)")
+}
+
+// changed reports whether any budget flag was passed.
+func (f *planFlags) changed(cmd *cobra.Command) bool {
+	return cmd.Flags().Changed("budget-hours") || cmd.Flags().Changed("budget") || cmd.Flags().Changed("due")
+}
+
+// apply returns plan with the passed budget flags applied.
+func (f *planFlags) apply(cmd *cobra.Command, plan storage.MilestonePlan) (storage.MilestonePlan, error) {
+	if cmd.Flags().Changed("budget-hours") {
+		if f.hours < 0 {
+			return plan, fmt.Errorf("--budget-hours cannot be negative")
+		}
+
+		plan.BudgetHours = nil
+		if f.hours > 0 {
+			hours := f.hours
+			plan.BudgetHours = &hours
+		}
+	}
+
+	if cmd.Flags().Changed("budget") {
+		if f.amount < 0 {
+			return plan, fmt.Errorf("--budget cannot be negative")
+		}
+
+		plan.BudgetAmount = nil
+		if f.amount > 0 {
+			amount := f.amount
+			plan.BudgetAmount = &amount
+		}
+	}
+
+	if cmd.Flags().Changed("due") {
+		plan.DueDate = nil
+		if strings.TrimSpace(f.due) != "" {
+			due, err := parseDueDate(f.due, time.Now())
+			if err != nil {
+				return plan, fmt.Errorf("--due: %w", err)
+			}
+			plan.DueDate = &due
+		}
+	}
+
+	return plan, nil
+}
+
+// parseDueDate parses the day a milestone is due. A month or year means its
+// last day.
+func parseDueDate(value string, now time.Time) (time.Time, error) {
+	r, err := period.ParseWithWeekStart(value, now, settings.WeekStart())
+	if err != nil || r.Start.IsZero() || r.End.IsZero() || strings.Contains(value, "..") {
+		return time.Time{}, fmt.Errorf("invalid due date '%s', use YYYY-MM-DD", value)
+	}
+
+	return period.Day(r.End.AddDate(0, 0, -1)).Start, nil
+}
+
+// printProgress shows a milestone's budgets, burn rate and due date.
+func printProgress(progress *storage.MilestoneProgress, currencyCode string) {
+	m := progress.Milestone
+
+	if used, ok := progress.HoursUsed(); ok {
+		ui.PrintInfo(4, "Hours", fmt.Sprintf("%s  %s of %gh", ui.ProgressBar(used, progressBarWidth), ui.FormatDuration(progress.Tracked), *m.BudgetHours))
+	}
+
+	if used, ok := progress.AmountUsed(); ok {
+		ui.PrintInfo(4, "Budget", fmt.Sprintf("%s  %s of %s", ui.ProgressBar(used, progressBarWidth),
+			currency.FormatCurrency(progress.Earned, currencyCode), currency.FormatCurrency(*m.BudgetAmount, currencyCode)))
+	}
+
+	if m.BudgetHours != nil || m.BudgetAmount != nil || m.DueDate != nil {
+		ui.PrintInfo(4, "Burn Rate", fmt.Sprintf("%s per day", ui.FormatDuration(progress.BurnRate())))
+	}
+
+	projected, hasProjection := progress.ProjectedCompletion()
+	if hasProjection {
+		ui.PrintInfo(4, "Projected", fmt.Sprintf("hour budget used up on %s", settings.FormatDate(projected)))
+	}
+
+	if m.DueDate != nil {
+		ui.PrintInfo(4, "Due", settings.FormatDate(*m.DueDate))
+	}
+
+	if progress.OverBudget() {
+		ui.PrintWarning(ui.EmojiWarning, "This milestone is over budget")
+	}
+
+	if progress.Overdue() {
+		ui.PrintWarning(ui.EmojiWarning, "This milestone is past its due date")
+	} else if hasProjection && m.DueDate != nil && projected.Before(m.DueDate.AddDate(0, 0, 1)) {
+		ui.PrintWarning(ui.EmojiWarning, "At this rate the hour budget runs out before the due date")
+	}
+}
+
+// progressSummary is the one-line budget overview shown by list, or "" when
+// the milestone has no budget or due date.
+func progressSummary(progress *storage.MilestoneProgress) string {
+	var parts []string
+
+	if used, ok := progress.HoursUsed(); ok {
+		parts = append(parts, fmt.Sprintf("Hours: %s", ui.ProgressBar(used, progressBarWidth/2)))
+	}
+
+	if used, ok := progress.AmountUsed(); ok {
+		parts = append(parts, fmt.Sprintf("Budget: %s", ui.ProgressBar(used, progressBarWidth/2)))
+	}
+
+	if due := progress.Milestone.DueDate; due != nil {
+		label := fmt.Sprintf("Due: %s", settings.FormatDate(*due))
+		if progress.Overdue() {
+			label = ui.Error(label + " (overdue)")
+		}
+		parts = append(parts, label)
+	}
+
+	return strings.Join(parts, "  ")
+}
+
+func getCurrencyCode() string {
+	globalCfg, err := settings.LoadGlobalConfig()
+	if err != nil {
+		return currency.DefaultCurrency
+	}
+	return globalCfg.Currency
+}
@@ -0,0 +1,101 @@
+package milestones
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/DylanDevelops/tmpo/internal/project"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteWithEntries bool
+	deleteYes         bool
+)
+
+func DeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a milestone",
+		Long:  `Move a milestone of the current project to the trash. Its time entries are kept without a milestone, or moved to the trash as well with --with-entries. Use 'tmpo undo' to bring back the milestone together with its entries.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			projectName, err := project.DetectConfiguredProject()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
+				os.Exit(1)
+			}
+
+			milestone := findMilestone(db, projectName, args[0])
+
+			entries, err := db.GetEntriesByMilestone(projectName, milestone.Name)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if !deleteYes {
+				if !ui.IsInteractive() {
+					ui.PrintError(ui.EmojiError, "confirmation required")
+					ui.PrintMuted(0, "Pass --yes to delete without a terminal")
+					ui.NewlineBelow()
+					os.Exit(1)
+				}
+
+				label := fmt.Sprintf("Delete milestone '%s' and keep its %d entries?", milestone.Name, len(entries))
+				if deleteWithEntries {
+					label = fmt.Sprintf("Delete milestone '%s' and its %d entries?", milestone.Name, len(entries))
+				}
+
+				confirmPrompt := promptui.Select{
+					Label: label,
+					Items: []string{"No", "Yes"},
+				}
+
+				_, result, err := confirmPrompt.Run()
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				if result == "No" {
+					ui.PrintWarning(ui.EmojiWarning, "Deletion cancelled")
+					ui.NewlineBelow()
+					os.Exit(0)
+				}
+			}
+
+			changed, err := db.DeleteMilestone(milestone.ID, deleteWithEntries)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiTrash, fmt.Sprintf("Moved milestone %s to the trash", ui.Bold(milestone.Name)))
+			if deleteWithEntries {
+				ui.PrintInfo(4, "Entries", fmt.Sprintf("%d moved to the trash", changed))
+			} else {
+				ui.PrintInfo(4, "Entries", fmt.Sprintf("%d kept without a milestone", changed))
+			}
+			ui.PrintMuted(4, "└─ Use 'tmpo undo' to bring it back")
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().BoolVar(&deleteWithEntries, "with-entries", false, "Move the milestone's entries to the trash as well")
+	cmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Skip the confirmation prompt")
+
+	return cmd
+}
@@ -0,0 +1,187 @@
+package milestones
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/project"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	editStart string
+	editEnd   string
+	editPlan  planFlags
+)
+
+func EditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit [name]",
+		Short: "Change a milestone's dates or budget",
+		Long:  `Change the start or end of a milestone of the current project, for example to create a milestone retroactively, or its budget and due date. Setting an end on an active milestone finishes it along with the active milestones nested in it.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			datesChanged := cmd.Flags().Changed("start") || cmd.Flags().Changed("end")
+			if !datesChanged && !editPlan.changed(cmd) {
+				ui.PrintError(ui.EmojiError, "Nothing to change")
+				ui.PrintMuted(0, "Pass --start, --end, --budget-hours, --budget or --due.")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			projectName, err := project.DetectConfiguredProject()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
+				os.Exit(1)
+			}
+
+			milestone := findMilestone(db, projectName, args[0])
+			start, end := milestone.StartTime, milestone.EndTime
+
+			if cmd.Flags().Changed("start") {
+				if start, err = parseMilestoneTime(editStart, false, time.Now()); err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("--start: %v", err))
+					os.Exit(1)
+				}
+			}
+
+			if cmd.Flags().Changed("end") {
+				t, err := parseMilestoneTime(editEnd, true, time.Now())
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("--end: %v", err))
+					os.Exit(1)
+				}
+				end = &t
+			}
+
+			if start.After(time.Now()) || (end != nil && end.After(time.Now())) {
+				ui.PrintError(ui.EmojiError, "Milestone dates cannot be in the future")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			plan, err := editPlan.apply(cmd, milestone.Plan())
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if datesChanged {
+				if err := db.UpdateMilestoneDates(milestone.ID, start, end); err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+			}
+
+			if editPlan.changed(cmd) {
+				if err := db.SetMilestonePlan(milestone.ID, plan); err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+			}
+
+			updated, err := db.GetMilestone(milestone.ID)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			progress, err := db.GetMilestoneProgress(updated)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Updated milestone %s", ui.Bold(milestone.Name)))
+			ui.PrintInfo(4, "Started", settings.FormatDateTimeDashed(start))
+			if end != nil {
+				ui.PrintInfo(4, "Finished", settings.FormatDateTimeDashed(*end))
+			} else {
+				ui.PrintInfo(4, "Finished", "still active")
+			}
+			printProgress(progress, getCurrencyCode())
+
+			// Entries keep their tag, so point out the ones now outside the milestone
+			entries, err := db.GetEntriesByMilestone(projectName, milestone.Name)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			outside := 0
+			for _, entry := range entries {
+				if entry.StartTime.Before(start) || (end != nil && !entry.StartTime.Before(*end)) {
+					outside++
+				}
+			}
+
+			if outside == 1 {
+				ui.PrintWarning(ui.EmojiWarning, "1 tagged entry started outside the new dates")
+			} else if outside > 1 {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("%d tagged entries started outside the new dates", outside))
+			}
+
+			ui.PrintMuted(4, "└─ Use 'tmpo undo' to revert the change")
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().StringVar(&editStart, "start", "", "When the milestone started (YYYY-MM-DD, optionally followed by a time such as 09:00)")
+	cmd.Flags().StringVar(&editEnd, "end", "", "When the milestone finished (YYYY-MM-DD, optionally followed by a time such as 17:30)")
+	addPlanFlags(cmd, &editPlan)
+
+	return cmd
+}
+
+// parseMilestoneTime parses a date, optionally followed by a time of day, or
+// an RFC 3339 timestamp. A date on its own means the start of that day, or
+// its end when endOfDay is set.
+func parseMilestoneTime(value string, endOfDay bool, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Local(), nil
+	}
+
+	datePart, timePart, hasTime := strings.Cut(value, " ")
+
+	day, err := period.Parse(datePart, now)
+	if err != nil || strings.Contains(datePart, "..") || !day.End.Equal(period.Day(day.Start).End) {
+		return time.Time{}, fmt.Errorf("invalid date '%s', use YYYY-MM-DD optionally followed by a time such as 14:30 or 2:30 PM", value)
+	}
+
+	if !hasTime {
+		// A milestone finishing today finishes now rather than at midnight
+		if endOfDay && day.Contains(now) {
+			return now, nil
+		}
+		if endOfDay {
+			return day.End, nil
+		}
+		return day.Start, nil
+	}
+
+	timePart = strings.ToUpper(strings.TrimSpace(timePart))
+	for _, layout := range []string{"15:04", "3:04 PM", "3:04PM"} {
+		if t, err := time.Parse(layout, timePart); err == nil {
+			return time.Date(day.Start.Year(), day.Start.Month(), day.Start.Day(), t.Hour(), t.Minute(), 0, 0, day.Start.Location()), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time '%s', use 24-hour (14:30) or 12-hour (2:30 PM) format", timePart)
+}
@@ -12,9 +12,10 @@ import (
 
 func FinishCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "finish",
+		Use:   "finish [name]",
 		Short: "Finish the active milestone",
-		Long:  `Finish the currently active milestone for the current project. This marks the milestone as completed and stops auto-tagging new time entries with it.`,
+		Long:  `Finish the currently active milestone for the current project, or the named one. This marks the milestone as completed and stops auto-tagging new time entries with it. Active milestones nested inside it are finished as well.`,
+		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			ui.NewlineAbove()
 
@@ -31,13 +32,34 @@ func FinishCmd() *cobra.Command {
 				os.Exit(1)
 			}
 
-			// Get active milestone
-			activeMilestone, err := db.GetActiveMilestoneForProject(projectName)
+			// Get the active chain, top level first, to find nested milestones
+			activeMilestones, err := db.GetActiveMilestonesForProject(projectName)
 			if err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
 				os.Exit(1)
 			}
 
+			var activeMilestone *storage.Milestone
+			var nested []*storage.Milestone
+
+			if len(args) > 0 {
+				activeMilestone = findMilestone(db, projectName, args[0])
+				if !activeMilestone.IsActive() {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("Milestone '%s' is already finished", activeMilestone.Name))
+					ui.NewlineBelow()
+					os.Exit(1)
+				}
+
+				for i, m := range activeMilestones {
+					if m.ID == activeMilestone.ID {
+						nested = activeMilestones[i+1:]
+					}
+				}
+			} else if len(activeMilestones) > 0 {
+				// the deepest level is the one entries are being tagged with
+				activeMilestone = activeMilestones[len(activeMilestones)-1]
+			}
+
 			if activeMilestone == nil {
 				ui.PrintError(ui.EmojiError, "No active milestone found")
 				ui.PrintMuted(0, "Use 'tmpo milestone start' to start a new milestone.")
@@ -46,7 +68,7 @@ func FinishCmd() *cobra.Command {
 			}
 
 			// Get entries for this milestone to show count
-			entries, err := db.GetEntriesByMilestone(projectName, activeMilestone.Name)
+			entries, err := db.GetEntriesInMilestoneTree(activeMilestone)
 			if err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
 				os.Exit(1)
@@ -69,6 +91,10 @@ func FinishCmd() *cobra.Command {
 			ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Finished milestone %s", ui.Bold(finishedMilestone.Name)))
 			ui.PrintInfo(4, "Duration", ui.FormatDuration(finishedMilestone.Duration()))
 			ui.PrintInfo(4, "Entries", fmt.Sprintf("%d", len(entries)))
+			for _, m := range nested {
+				ui.PrintInfo(4, "Also Finished", m.Name)
+			}
+			ui.PrintMuted(4, fmt.Sprintf("└─ Use 'tmpo milestone report \"%s\"' for a full summary", finishedMilestone.Name))
 			ui.NewlineBelow()
 		},
 	}
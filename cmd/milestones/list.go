@@ -3,6 +3,7 @@ package milestones
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/DylanDevelops/tmpo/internal/project"
 	"github.com/DylanDevelops/tmpo/internal/settings"
@@ -73,58 +74,37 @@ func ListCmd() *cobra.Command {
 			}
 			ui.NewlineBelow()
 
-			// Group by active/finished
-			var activeMilestones []*storage.Milestone
-			var finishedMilestones []*storage.Milestone
+			// Group top-level milestones by active/finished, newest first, with
+			// nested milestones listed under their parent
+			var activeRoots []*storage.MilestoneNode
+			var finishedRoots []*storage.MilestoneNode
 
-			for _, m := range milestones {
-				if m.IsActive() {
-					activeMilestones = append(activeMilestones, m)
+			roots := storage.BuildMilestoneTree(milestones)
+			for i := len(roots) - 1; i >= 0; i-- {
+				if roots[i].Milestone.IsActive() {
+					activeRoots = append(activeRoots, roots[i])
 				} else {
-					finishedMilestones = append(finishedMilestones, m)
+					finishedRoots = append(finishedRoots, roots[i])
 				}
 			}
 
 			// Print active milestones
-			if len(activeMilestones) > 0 {
+			if len(activeRoots) > 0 {
 				fmt.Printf("%s Active %s\n", ui.Muted("───"), ui.Muted("───"))
-				for _, m := range activeMilestones {
-					// Get entry count for this milestone
-					entries, _ := db.GetEntriesByMilestone(m.ProjectName, m.Name)
-					entryCount := len(entries)
-
-					if listAll {
-						fmt.Printf("  %s (%s)\n", ui.Bold(m.Name), m.ProjectName)
-					} else {
-						fmt.Printf("  %s\n", ui.Bold(m.Name))
-					}
-					fmt.Printf("    Started: %s  Duration: %s  Entries: %d\n",
-						settings.FormatTime(m.StartTime),
-						ui.FormatDuration(m.Duration()),
-						entryCount)
-					fmt.Println()
+				for _, root := range activeRoots {
+					root.Walk(func(node *storage.MilestoneNode) {
+						printMilestoneNode(db, node)
+					})
 				}
 			}
 
 			// Print finished milestones
-			if len(finishedMilestones) > 0 {
+			if len(finishedRoots) > 0 {
 				fmt.Printf("%s Finished %s\n", ui.Muted("───"), ui.Muted("───"))
-				for _, m := range finishedMilestones {
-					// Get entry count for this milestone
-					entries, _ := db.GetEntriesByMilestone(m.ProjectName, m.Name)
-					entryCount := len(entries)
-
-					if listAll {
-						fmt.Printf("  %s (%s)\n", ui.Bold(m.Name), m.ProjectName)
-					} else {
-						fmt.Printf("  %s\n", ui.Bold(m.Name))
-					}
-					fmt.Printf("    %s - %s  Duration: %s  Entries: %d\n",
-						settings.FormatTime(m.StartTime),
-						settings.FormatTime(*m.EndTime),
-						ui.FormatDuration(m.Duration()),
-						entryCount)
-					fmt.Println()
+				for _, root := range finishedRoots {
+					root.Walk(func(node *storage.MilestoneNode) {
+						printMilestoneNode(db, node)
+					})
 				}
 			}
 
@@ -137,3 +117,52 @@ func ListCmd() *cobra.Command {
 
 	return cmd
 }
+
+// printMilestoneNode prints one milestone of the list, indented by its depth.
+// Entries and time include the milestones nested inside it.
+func printMilestoneNode(db *storage.Database, node *storage.MilestoneNode) {
+	m := node.Milestone
+
+	progress, err := db.GetMilestoneProgress(m)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	indent := strings.Repeat("    ", node.Depth)
+	name := ui.Bold(m.Name)
+	if node.Depth > 0 {
+		name = ui.Muted("└─ ") + name
+		if m.IsActive() {
+			name += ui.Muted(" (active)")
+		}
+	}
+
+	if listAll && node.Depth == 0 {
+		fmt.Printf("  %s (%s)\n", name, m.ProjectName)
+	} else {
+		fmt.Printf("  %s%s\n", indent, name)
+	}
+
+	if m.IsActive() {
+		fmt.Printf("  %s  Started: %s  Duration: %s  Entries: %d  Tracked: %s\n",
+			indent,
+			settings.FormatTime(m.StartTime),
+			ui.FormatDuration(m.Duration()),
+			progress.Entries,
+			ui.FormatDuration(progress.Tracked))
+	} else {
+		fmt.Printf("  %s  %s - %s  Duration: %s  Entries: %d  Tracked: %s\n",
+			indent,
+			settings.FormatTime(m.StartTime),
+			settings.FormatTime(*m.EndTime),
+			ui.FormatDuration(m.Duration()),
+			progress.Entries,
+			ui.FormatDuration(progress.Tracked))
+	}
+
+	if summary := progressSummary(progress); summary != "" {
+		fmt.Printf("  %s  %s\n", indent, summary)
+	}
+	fmt.Println()
+}
@@ -1,6 +1,13 @@
 package milestones
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"os"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
 
 func MilestoneCmds() *cobra.Command {
 	cmd := &cobra.Command{
@@ -13,6 +20,31 @@ func MilestoneCmds() *cobra.Command {
 	cmd.AddCommand(FinishCmd())
 	cmd.AddCommand(StatusCmd())
 	cmd.AddCommand(ListCmd())
+	cmd.AddCommand(ReportCmd())
+	cmd.AddCommand(RenameCmd())
+	cmd.AddCommand(ReopenCmd())
+	cmd.AddCommand(EditCmd())
+	cmd.AddCommand(DeleteCmd())
+	cmd.AddCommand(SyncCmd())
 
 	return cmd
 }
+
+// findMilestone returns a milestone of the project by name, exiting when
+// there is none.
+func findMilestone(db *storage.Database, projectName, name string) *storage.Milestone {
+	milestone, err := db.GetMilestoneByName(projectName, name)
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	if milestone == nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("Milestone '%s' not found for %s", name, projectName))
+		ui.PrintMuted(0, "Use 'tmpo milestone list' to see the milestones of this project.")
+		ui.NewlineBelow()
+		os.Exit(1)
+	}
+
+	return milestone
+}
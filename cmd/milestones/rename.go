@@ -0,0 +1,64 @@
+package milestones
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DylanDevelops/tmpo/internal/project"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func RenameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename [old-name] [new-name]",
+		Short: "Rename a milestone",
+		Long:  `Rename a milestone of the current project. Time entries tagged with the milestone, including those in the trash, are retagged with the new name.`,
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			newName := strings.TrimSpace(args[1])
+			if newName == "" {
+				ui.PrintError(ui.EmojiError, "Milestone name cannot be empty")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			projectName, err := project.DetectConfiguredProject()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
+				os.Exit(1)
+			}
+
+			milestone := findMilestone(db, projectName, args[0])
+			if milestone.Name == newName {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("Milestone is already named '%s'", newName))
+				ui.NewlineBelow()
+				return
+			}
+
+			retagged, err := db.RenameMilestone(milestone.ID, newName)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Renamed milestone %s to %s", ui.Bold(milestone.Name), ui.Bold(newName)))
+			ui.PrintInfo(4, "Entries", fmt.Sprintf("%d retagged", retagged))
+			ui.PrintMuted(4, "└─ Use 'tmpo undo' to change it back")
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,49 @@
+package milestones
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/DylanDevelops/tmpo/internal/project"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func ReopenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reopen [name]",
+		Short: "Reopen a finished milestone",
+		Long:  `Make a finished milestone of the current project active again, so new time entries are tagged with it. Only one milestone can be active at a time.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			projectName, err := project.DetectConfiguredProject()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
+				os.Exit(1)
+			}
+
+			milestone := findMilestone(db, projectName, args[0])
+
+			if err := db.ReopenMilestone(milestone.ID); err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Reopened milestone %s for %s", ui.Bold(milestone.Name), ui.Bold(projectName)))
+			ui.PrintMuted(4, "└─ New time entries will be automatically tagged")
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
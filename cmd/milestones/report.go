@@ -0,0 +1,273 @@
+package milestones
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/DylanDevelops/tmpo/internal/currency"
+	"github.com/DylanDevelops/tmpo/internal/export"
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/project"
+	"github.com/DylanDevelops/tmpo/internal/report"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportFormat string
+	reportOutput string
+)
+
+// reportDescriptionLimit is how many descriptions the report lists.
+const reportDescriptionLimit = 10
+
+func ReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report [name]",
+		Short: "Summarise a milestone",
+		Long: `Summarise a milestone of the current project, including the milestones nested inside it: total and billable time, earnings, a per-day breakdown, the top descriptions and the weekdays without tracked time. Without a name, the active milestone is reported.
+
+Pass --format or --output to save the report as CSV or JSON instead, e.g. for a sprint retrospective. Use '--output -' to write to stdout.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			exporting := cmd.Flags().Changed("format") || cmd.Flags().Changed("output")
+			toStdout := reportOutput == "-"
+
+			if !toStdout {
+				ui.NewlineAbove()
+			}
+
+			format := reportFormat
+			if !cmd.Flags().Changed("format") {
+				format = export.FormatCSV
+				if strings.EqualFold(filepath.Ext(reportOutput), ".json") {
+					format = export.FormatJSON
+				}
+			}
+
+			if format != export.FormatCSV && format != export.FormatJSON {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("Unknown format '%s'. Use 'csv' or 'json'", format))
+				os.Exit(1)
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			projectName, err := project.DetectConfiguredProject()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
+				os.Exit(1)
+			}
+
+			var milestone *storage.Milestone
+			if len(args) > 0 {
+				milestone = findMilestone(db, projectName, args[0])
+			} else {
+				milestone, err = db.GetActiveMilestoneForProject(projectName)
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				if milestone == nil {
+					ui.PrintError(ui.EmojiError, "No active milestone found")
+					ui.PrintMuted(0, "Pass the name of the milestone to report on.")
+					ui.NewlineBelow()
+					os.Exit(1)
+				}
+			}
+
+			entries, err := db.GetEntriesInMilestoneTree(milestone)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			loc := time.Local
+			if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
+				loc = globalCfg.Location()
+			}
+
+			span := period.Range{Start: period.Day(milestone.StartTime.In(loc)).Start}
+			if milestone.EndTime != nil {
+				span.End = period.Day(milestone.EndTime.In(loc)).End
+			}
+
+			r := report.Build(milestone.Name, span, entries, loc, time.Now())
+
+			if !exporting {
+				progress, err := db.GetMilestoneProgress(milestone)
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				printReport(milestone, r, progress)
+				ui.NewlineBelow()
+				return
+			}
+
+			if toStdout {
+				if err := export.WriteReport(format, os.Stdout, r); err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+				return
+			}
+
+			filename := reportOutput
+			if filename == "" {
+				filename = fmt.Sprintf("tmpo-report-%s-%s.%s", slugify(milestone.Name), time.Now().Format("2006-01-02"), format)
+			}
+
+			if filepath.Ext(filename) != "."+format {
+				filename += "." + format
+			}
+
+			file, err := os.Create(filename)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("failed to create report file: %v", err))
+				os.Exit(1)
+			}
+
+			err = export.WriteReport(format, file, r)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiExport, fmt.Sprintf("Saved report for %s to %s", ui.Bold(milestone.Name), ui.Bold(filename)))
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().StringVarP(&reportFormat, "format", "f", export.FormatCSV, "Export format (csv or json)")
+	cmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Output filename (use - for stdout)")
+
+	return cmd
+}
+
+func printReport(milestone *storage.Milestone, r *report.Report, progress *storage.MilestoneProgress) {
+	currencyCode := getCurrencyCode()
+
+	finished := "still active"
+	if milestone.EndTime != nil {
+		finished = settings.FormatDate(*milestone.EndTime)
+	}
+
+	ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Milestone Report: %s", ui.Bold(milestone.Name)))
+	ui.PrintInfo(4, "Project", milestone.ProjectName)
+	ui.PrintInfo(4, "Period", fmt.Sprintf("%s - %s", settings.FormatDate(milestone.StartTime), finished))
+	ui.PrintInfo(4, "Entries", fmt.Sprintf("%d", r.Entries))
+	ui.PrintInfo(4, "Total Time", ui.FormatDuration(r.Total))
+	ui.PrintInfo(4, "Billable", ui.FormatDuration(r.Billable))
+	if r.Billable > 0 {
+		ui.PrintInfo(4, "Earnings", currency.FormatCurrency(r.Earnings, currencyCode))
+	}
+	printProgress(progress, currencyCode)
+	fmt.Println()
+
+	if len(r.Days) > 0 {
+		fmt.Printf("%s By Day %s\n", ui.Muted("───"), ui.Muted("───"))
+		for _, day := range r.Days {
+			fmt.Printf("  %s %s  %s  %s\n",
+				day.Day.Format("Mon"),
+				settings.FormatDate(day.Day),
+				ui.FormatDuration(day.Duration),
+				ui.Muted(entriesLabel(day.Entries)))
+		}
+		fmt.Println()
+
+		shown := r.Descriptions
+		if len(shown) > reportDescriptionLimit {
+			shown = shown[:reportDescriptionLimit]
+		}
+
+		labels := make([]string, len(shown))
+		width := 0
+		for i, total := range shown {
+			labels[i] = total.Description
+			if labels[i] == "" {
+				labels[i] = "(no description)"
+			}
+			if runes := []rune(labels[i]); len(runes) > 40 {
+				labels[i] = string(runes[:39]) + "…"
+			}
+			width = max(width, len([]rune(labels[i])))
+		}
+
+		fmt.Printf("%s Top Descriptions %s\n", ui.Muted("───"), ui.Muted("───"))
+		for i, total := range shown {
+			label := labels[i] + strings.Repeat(" ", width-len([]rune(labels[i])))
+			if total.Description == "" {
+				label = ui.Muted(label)
+			}
+
+			fmt.Printf("  %s  %s  %s\n",
+				label,
+				ui.FormatDuration(total.Duration),
+				ui.Muted(fmt.Sprintf("%.0f%%, %s", 100*total.Duration.Hours()/r.Total.Hours(), entriesLabel(total.Entries))))
+		}
+		if len(r.Descriptions) > len(shown) {
+			ui.PrintMuted(2, fmt.Sprintf("... and %d more", len(r.Descriptions)-len(shown)))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%s Gaps %s\n", ui.Muted("───"), ui.Muted("───"))
+	if len(r.Gaps) == 0 {
+		ui.PrintMuted(2, "Time was tracked on every weekday")
+	}
+	for _, gap := range r.Gaps {
+		label := settings.FormatDate(gap.Start)
+		if !gap.End.Equal(gap.Start) {
+			label = fmt.Sprintf("%s - %s", label, settings.FormatDate(gap.End))
+		}
+
+		days := "1 weekday"
+		if gap.Days != 1 {
+			days = fmt.Sprintf("%d weekdays", gap.Days)
+		}
+
+		fmt.Printf("  %s  %s\n", label, ui.Muted(days+" without tracked time"))
+	}
+}
+
+func entriesLabel(count int) string {
+	if count == 1 {
+		return "1 entry"
+	}
+	return fmt.Sprintf("%d entries", count)
+}
+
+// slugify turns a milestone name into something safe to use in a filename.
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
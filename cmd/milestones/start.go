@@ -4,17 +4,24 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/DylanDevelops/tmpo/internal/currency"
 	"github.com/DylanDevelops/tmpo/internal/project"
+	"github.com/DylanDevelops/tmpo/internal/settings"
 	"github.com/DylanDevelops/tmpo/internal/storage"
 	"github.com/DylanDevelops/tmpo/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+var (
+	startPlan   planFlags
+	startParent string
+)
+
 func StartCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "start [name]",
 		Short: "Start a new milestone",
-		Long:  `Start a new milestone for the current project. Time entries created after starting a milestone will be automatically tagged with it.`,
+		Long:  `Start a new milestone for the current project. Time entries created after starting a milestone will be automatically tagged with it. Milestones can be nested with --parent, in which case entries are tagged with the deepest active one. Optionally give it an hour budget, a money budget and a due date to track progress against.`,
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			ui.NewlineAbove()
@@ -34,16 +41,38 @@ func StartCmd() *cobra.Command {
 
 			milestoneName := args[0]
 
-			// Check if there's already an active milestone
-			activeMilestone, err := db.GetActiveMilestoneForProject(projectName)
+			plan, err := startPlan.apply(cmd, storage.MilestonePlan{})
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			var parent *storage.Milestone
+			if startParent != "" {
+				parent = findMilestone(db, projectName, startParent)
+				if !parent.IsActive() {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("Milestone '%s' is finished", parent.Name))
+					ui.PrintMuted(0, fmt.Sprintf("Use 'tmpo milestone reopen \"%s\"' to nest new milestones in it.", parent.Name))
+					ui.NewlineBelow()
+					os.Exit(1)
+				}
+			}
+
+			// Check if there's already an active milestone at this level
+			activeMilestones, err := db.GetActiveMilestonesForProject(projectName)
 			if err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
 				os.Exit(1)
 			}
 
-			if activeMilestone != nil {
-				ui.PrintError(ui.EmojiError, fmt.Sprintf("Milestone '%s' is already active for %s", activeMilestone.Name, projectName))
-				ui.PrintMuted(0, "Use 'tmpo milestone finish' to finish it first.")
+			for _, active := range activeMilestones {
+				sameLevel := (active.ParentID == nil && parent == nil) || (active.ParentID != nil && parent != nil && *active.ParentID == parent.ID)
+				if !sameLevel {
+					continue
+				}
+
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("Milestone '%s' is already active for %s", active.Name, projectName))
+				ui.PrintMuted(0, fmt.Sprintf("Use 'tmpo milestone finish' to finish it first, or --parent \"%s\" to nest the new milestone inside it.", active.Name))
 				ui.NewlineBelow()
 				os.Exit(1)
 			}
@@ -60,24 +89,44 @@ func StartCmd() *cobra.Command {
 				if existingMilestone.IsActive() {
 					ui.PrintMuted(0, "This milestone is currently active. Use a different name or finish it first.")
 				} else {
-					ui.PrintMuted(0, "This milestone has already been finished. Use a different name for the new milestone.")
+					ui.PrintMuted(0, fmt.Sprintf("This milestone has already been finished. Use 'tmpo milestone reopen \"%s\"' to continue it, or a different name for the new milestone.", milestoneName))
 				}
 				ui.NewlineBelow()
 				os.Exit(1)
 			}
 
 			// Create the milestone
-			milestone, err := db.CreateMilestone(projectName, milestoneName)
+			var parentID *int64
+			if parent != nil {
+				parentID = &parent.ID
+			}
+
+			milestone, err := db.CreateMilestoneWithPlan(projectName, milestoneName, parentID, plan)
 			if err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("failed to create milestone: %v", err))
 				os.Exit(1)
 			}
 
 			ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Started milestone %s for %s", ui.Bold(milestone.Name), ui.Bold(projectName)))
+			if parent != nil {
+				ui.PrintInfo(4, "Part Of", parent.Name)
+			}
+			if milestone.BudgetHours != nil {
+				ui.PrintInfo(4, "Hour Budget", fmt.Sprintf("%gh", *milestone.BudgetHours))
+			}
+			if milestone.BudgetAmount != nil {
+				ui.PrintInfo(4, "Budget", currency.FormatCurrency(*milestone.BudgetAmount, getCurrencyCode()))
+			}
+			if milestone.DueDate != nil {
+				ui.PrintInfo(4, "Due", settings.FormatDate(*milestone.DueDate))
+			}
 			ui.PrintMuted(4, "└─ New time entries will be automatically tagged")
 			ui.NewlineBelow()
 		},
 	}
 
+	addPlanFlags(cmd, &startPlan)
+	cmd.Flags().StringVar(&startParent, "parent", "", "Nest the milestone inside this active milestone, e.g. a sprint inside a release")
+
 	return cmd
 }
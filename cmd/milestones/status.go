@@ -3,7 +3,6 @@ package milestones
 import (
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/DylanDevelops/tmpo/internal/project"
 	"github.com/DylanDevelops/tmpo/internal/settings"
@@ -16,7 +15,7 @@ func StatusCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show active milestone status",
-		Long:  `Display information about the currently active milestone for the current project.`,
+		Long:  `Display information about the currently active milestone for the current project, including its progress against any budget and due date.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			ui.NewlineAbove()
 
@@ -33,41 +32,51 @@ func StatusCmd() *cobra.Command {
 				os.Exit(1)
 			}
 
-			// Get active milestone
-			activeMilestone, err := db.GetActiveMilestoneForProject(projectName)
+			// Get the active milestones, top level first
+			activeMilestones, err := db.GetActiveMilestonesForProject(projectName)
 			if err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
 				os.Exit(1)
 			}
 
-			if activeMilestone == nil {
+			if len(activeMilestones) == 0 {
 				ui.PrintWarning(ui.EmojiWarning, "No active milestone")
 				ui.PrintMuted(0, "Use 'tmpo milestone start' to start a new milestone.")
 				ui.NewlineBelow()
 				return
 			}
 
-			// Get entries for this milestone
-			entries, err := db.GetEntriesByMilestone(projectName, activeMilestone.Name)
+			activeMilestone := activeMilestones[len(activeMilestones)-1]
+			parents := activeMilestones[:len(activeMilestones)-1]
+
+			progress, err := db.GetMilestoneProgress(activeMilestone)
 			if err != nil {
 				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
 				os.Exit(1)
 			}
 
-			// Calculate total time tracked
-			var totalTime time.Duration
-			for _, entry := range entries {
-				if !entry.IsRunning() {
-					totalTime += entry.Duration()
-				}
-			}
-
 			ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Active Milestone: %s", ui.Bold(activeMilestone.Name)))
 			ui.PrintInfo(4, "Project", projectName)
 			ui.PrintInfo(4, "Started", settings.FormatTime(activeMilestone.StartTime))
 			ui.PrintInfo(4, "Duration", ui.FormatDuration(activeMilestone.Duration()))
-			ui.PrintInfo(4, "Entries", fmt.Sprintf("%d", len(entries)))
-			ui.PrintInfo(4, "Total Time", ui.FormatDuration(totalTime))
+			ui.PrintInfo(4, "Entries", fmt.Sprintf("%d", progress.Entries))
+			ui.PrintInfo(4, "Total Time", ui.FormatDuration(progress.Tracked))
+			printProgress(progress, getCurrencyCode())
+
+			// Outer levels, with the time of everything nested inside them
+			for i := len(parents) - 1; i >= 0; i-- {
+				parentProgress, err := db.GetMilestoneProgress(parents[i])
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				summary := ui.FormatDuration(parentProgress.Tracked)
+				if extra := progressSummary(parentProgress); extra != "" {
+					summary += "  " + extra
+				}
+				ui.PrintInfo(4, fmt.Sprintf("Part Of %s", ui.Bold(parents[i].Name)), summary)
+			}
 			ui.NewlineBelow()
 		},
 	}
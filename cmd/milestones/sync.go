@@ -0,0 +1,135 @@
+package milestones
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/project"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func SyncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync milestones with the schedule in .tmporc",
+		Long:  `Reconcile the milestones of the current project with the milestones scheduled in .tmporc. Scheduled milestones are started and finished on their dates, milestones that already exist get the scheduled dates, and a milestone finished before its scheduled end is reopened. Milestones that aren't in .tmporc are left alone.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			cfg, _, err := settings.FindAndLoad()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				ui.PrintMuted(0, "Run 'tmpo init' to create one, then add a milestones list to it.")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			if len(cfg.Milestones) == 0 {
+				ui.PrintWarning(ui.EmojiWarning, "No milestones are scheduled in .tmporc")
+				ui.NewlineBelow()
+				return
+			}
+
+			projectName, err := project.DetectConfiguredProject()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("detecting project: %v", err))
+				os.Exit(1)
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			result, err := db.SyncMilestoneSchedule(projectName, cfg.Milestones, time.Now(), true)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf(".tmporc: %v", err))
+				os.Exit(1)
+			}
+
+			if !result.Changed() {
+				ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Milestones of %s match the schedule", ui.Bold(projectName)))
+			} else {
+				printScheduleSync(projectName, result)
+				ui.PrintMuted(4, "└─ Use 'tmpo undo' to revert the changes")
+			}
+
+			for _, reason := range result.Skipped {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("Skipped %s", reason))
+			}
+
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
+
+// SyncSchedule starts and finishes the milestones scheduled in .tmporc whose
+// dates have passed. It runs before every command, so problems are reported
+// as warnings rather than stopping the command.
+func SyncSchedule() {
+	cfg, _, err := settings.FindAndLoad()
+	if err != nil || len(cfg.Milestones) == 0 {
+		return
+	}
+
+	projectName, err := project.DetectConfiguredProject()
+	if err != nil {
+		return
+	}
+
+	db, err := storage.Initialize()
+	if err != nil {
+		return
+	}
+	defer db.Close()
+
+	result, err := db.SyncMilestoneSchedule(projectName, cfg.Milestones, time.Now(), false)
+
+	// keep piped output, such as an export to stdout, clean
+	if !ui.IsTerminalOutput() {
+		return
+	}
+
+	if err != nil {
+		ui.NewlineAbove()
+		ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("Scheduled milestones in .tmporc weren't synced: %v", err))
+		return
+	}
+
+	if result.Changed() {
+		ui.NewlineAbove()
+		printScheduleSync(projectName, result)
+	}
+}
+
+func printScheduleSync(projectName string, result *storage.ScheduleSync) {
+	for _, name := range result.Finished {
+		ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Finished scheduled milestone %s for %s", ui.Bold(name), ui.Bold(projectName)))
+	}
+	for _, name := range result.Updated {
+		ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Updated the dates of milestone %s to the schedule", ui.Bold(name)))
+	}
+	for _, name := range result.Reopened {
+		ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Reopened scheduled milestone %s for %s", ui.Bold(name), ui.Bold(projectName)))
+	}
+
+	// a milestone created already finished was only listed as finished
+	finished := make(map[string]bool)
+	for _, name := range result.Finished {
+		finished[name] = true
+	}
+	for _, name := range result.Started {
+		if !finished[name] {
+			ui.PrintSuccess(ui.EmojiMilestone, fmt.Sprintf("Started scheduled milestone %s for %s", ui.Bold(name), ui.Bold(projectName)))
+		}
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"github.com/DylanDevelops/tmpo/cmd/config"
 	"github.com/DylanDevelops/tmpo/cmd/entries"
 	"github.com/DylanDevelops/tmpo/cmd/history"
+	"github.com/DylanDevelops/tmpo/cmd/maintenance"
 	"github.com/DylanDevelops/tmpo/cmd/milestones"
 	"github.com/DylanDevelops/tmpo/cmd/setup"
+	"github.com/DylanDevelops/tmpo/cmd/timeoff"
 	"github.com/DylanDevelops/tmpo/cmd/tracking"
+	"github.com/DylanDevelops/tmpo/cmd/trash"
 	"github.com/DylanDevelops/tmpo/cmd/utilities"
 	"github.com/spf13/cobra"
 )
@@ -21,6 +24,16 @@ func RootCmd() *cobra.Command {
 
 A minimal, developer-friendly time tracking tool that lives in your terminal.
 Track time effortlessly with automatic project detection and simple commands.`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// Undoing an automatic start shouldn't redo it straight away, and
+			// sync reports what it does itself
+			switch cmd.CommandPath() {
+			case "tmpo undo", "tmpo redo", "tmpo milestone sync":
+				return
+			}
+
+			milestones.SyncSchedule()
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			// Check if version flag was set
 			versionFlag, _ := cmd.Flags().GetBool("version")
@@ -51,11 +64,22 @@ Track time effortlessly with automatic project detection and simple commands.`,
 	cmd.AddCommand(history.LogCmd())
 	cmd.AddCommand(history.StatsCmd())
 	cmd.AddCommand(history.ExportCmd())
+	cmd.AddCommand(history.TimesheetCmd())
+	cmd.AddCommand(history.GoalsCmd())
+	cmd.AddCommand(history.HistoryCmds())
 	
 	// Entries
+	cmd.AddCommand(entries.ShowCmd())
 	cmd.AddCommand(entries.EditCmd())
+	cmd.AddCommand(entries.SplitCmd())
+	cmd.AddCommand(entries.MergeCmd())
+	cmd.AddCommand(entries.DuplicateCmd())
+	cmd.AddCommand(entries.MoveCmd())
 	cmd.AddCommand(entries.DeleteCmd())
 	cmd.AddCommand(entries.ManualCmd())
+	cmd.AddCommand(entries.UndoCmd())
+	cmd.AddCommand(entries.RedoCmd())
+	cmd.AddCommand(trash.TrashCmds())
 
 	// Setup
 	cmd.AddCommand(setup.InitCmd())
@@ -66,6 +90,13 @@ Track time effortlessly with automatic project detection and simple commands.`,
 	// Milestones
 	cmd.AddCommand(milestones.MilestoneCmds())
 
+	// Time off
+	cmd.AddCommand(timeoff.TimeOffCmds())
+
+	// Maintenance
+	cmd.AddCommand(maintenance.DBCmds())
+	cmd.AddCommand(maintenance.DoctorCmd())
+
 	return cmd
 }
 
@@ -0,0 +1,93 @@
+package timeoff
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addType string
+	addName string
+)
+
+func AddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add [date|range]",
+		Short: "Add a holiday, vacation or sick day",
+		Long: `Add a day off, or every working day of a range, to the time-off calendar.
+
+Pass a date such as 2026-12-24, or a range such as 2026-12-21..2026-12-31 or next-week. A range only adds the working days of the working hours in the global config (Monday to Friday by default). A day that is already off is replaced.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			if err := storage.ValidateTimeOffKind(addType); err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("--type: %v", err))
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			span, err := parseDays(args[0], time.Now())
+			if err == nil && span.End.After(span.Start.AddDate(0, 0, maxDays)) {
+				err = fmt.Errorf("'%s' is longer than %d days", args[0], maxDays)
+			}
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				ui.PrintMuted(0, "Pass a date such as 2026-12-24 or a range such as 2026-12-21..2026-12-31")
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			working := workingDays()
+			single := span.End.Equal(span.Start.AddDate(0, 0, 1))
+
+			var days []*storage.TimeOff
+			for day := span.Start; day.Before(span.End); day = day.AddDate(0, 0, 1) {
+				if single || working[day.Weekday()] {
+					days = append(days, &storage.TimeOff{Day: day, Kind: addType, Name: strings.TrimSpace(addName)})
+				}
+			}
+
+			if len(days) == 0 {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("%s has no working days", formatSpan(span)))
+				ui.NewlineBelow()
+				return
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			added, err := db.AddTimeOff(days)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiTimeOff, fmt.Sprintf("Added %s off (%s)", ui.Bold(countDays(len(days))), addType))
+			ui.PrintInfo(4, "Period", formatSpan(span))
+			if name := strings.TrimSpace(addName); name != "" {
+				ui.PrintInfo(4, "Name", name)
+			}
+			if replaced := len(days) - added; replaced > 0 {
+				ui.PrintMuted(4, fmt.Sprintf("Replaced %s already off", countDays(replaced)))
+			}
+
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().StringVarP(&addType, "type", "t", storage.TimeOffVacation, fmt.Sprintf("Type of time off (%s)", strings.Join(storage.TimeOffKinds, ", ")))
+	cmd.Flags().StringVarP(&addName, "name", "n", "", "Name of the day off, such as Christmas Eve")
+
+	return cmd
+}
@@ -0,0 +1,279 @@
+package timeoff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var importType string
+
+func ImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import [file.ics]",
+		Short: "Import days off from an .ics calendar",
+		Long: `Import the events of an iCalendar (.ics) file, such as a public holiday calendar, as days off. Each event adds every day it covers, named after its summary. Days that are already off are replaced.
+
+Recurring events and cancelled events are skipped.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			if err := storage.ValidateTimeOffKind(importType); err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("--type: %v", err))
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			file, err := os.Open(args[0])
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("failed to open calendar: %v", err))
+				os.Exit(1)
+			}
+			defer file.Close()
+
+			days, skipped, err := parseICS(file, importType, time.Local)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%s: %v", args[0], err))
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			if len(days) == 0 {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("No events to import from %s", args[0]))
+				if skipped > 0 {
+					ui.PrintMuted(0, fmt.Sprintf("Skipped %d recurring or cancelled event(s).", skipped))
+				}
+				ui.NewlineBelow()
+				return
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			added, err := db.AddTimeOff(days)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			span := period.Range{Start: days[0].Day, End: period.Day(days[len(days)-1].Day).End}
+			ui.PrintSuccess(ui.EmojiTimeOff, fmt.Sprintf("Imported %s off (%s) from %s", ui.Bold(countDays(len(days))), importType, ui.Bold(args[0])))
+			ui.PrintInfo(4, "Period", formatSpan(span))
+			if replaced := len(days) - added; replaced > 0 {
+				ui.PrintMuted(4, fmt.Sprintf("Replaced %s already off", countDays(replaced)))
+			}
+			if skipped > 0 {
+				ui.PrintMuted(4, fmt.Sprintf("Skipped %d recurring or cancelled event(s)", skipped))
+			}
+			ui.PrintMuted(4, "Use 'tmpo off list' to see them, or 'tmpo undo' to take the import back.")
+
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().StringVarP(&importType, "type", "t", storage.TimeOffHoliday, fmt.Sprintf("Type of time off (%s)", strings.Join(storage.TimeOffKinds, ", ")))
+
+	return cmd
+}
+
+// icsEvent is the part of a VEVENT that days off are made from.
+type icsEvent struct {
+	line       int
+	summary    string
+	start, end string
+	recurring  bool
+	cancelled  bool
+}
+
+// parseICS reads the events of an iCalendar file as days off of kind, in
+// order. All-day events cover the days up to their end date, which is
+// excluded, and timed events the days they touch in loc. Recurring and
+// cancelled events are skipped and counted. When events share a day, the
+// first one is kept.
+func parseICS(r io.Reader, kind string, loc *time.Location) ([]*storage.TimeOff, int, error) {
+	type contentLine struct {
+		number int
+		text   string
+	}
+
+	// long properties are folded onto lines starting with a space or tab
+	var lines []contentLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		lines = append(lines, contentLine{number, text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	var events []*icsEvent
+	var event *icsEvent
+	calendar := false
+	// nested counts the components inside an event, such as alarms
+	nested := 0
+	for _, line := range lines {
+		name, value, found := strings.Cut(line.text, ":")
+		if !found {
+			continue
+		}
+		name, _, _ = strings.Cut(name, ";")
+
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			switch strings.ToUpper(value) {
+			case "VCALENDAR":
+				calendar = true
+			case "VEVENT":
+				event = &icsEvent{line: line.number}
+			default:
+				if event != nil {
+					nested++
+				}
+			}
+			continue
+		case "END":
+			switch {
+			case nested > 0:
+				nested--
+			case strings.EqualFold(value, "VEVENT") && event != nil:
+				events = append(events, event)
+				event = nil
+			}
+			continue
+		}
+
+		if event == nil || nested > 0 {
+			continue
+		}
+
+		switch strings.ToUpper(name) {
+		case "SUMMARY":
+			event.summary = unescapeICS(value)
+		case "DTSTART":
+			event.start = value
+		case "DTEND":
+			event.end = value
+		case "RRULE", "RDATE":
+			event.recurring = true
+		case "STATUS":
+			event.cancelled = strings.EqualFold(value, "CANCELLED")
+		}
+	}
+
+	if !calendar {
+		return nil, 0, fmt.Errorf("not an iCalendar file")
+	}
+
+	seen := make(map[string]bool)
+	var days []*storage.TimeOff
+	skipped := 0
+	for _, event := range events {
+		if event.recurring || event.cancelled {
+			skipped++
+			continue
+		}
+
+		first, last, err := eventDays(event, loc)
+		if err != nil {
+			return nil, 0, fmt.Errorf("event on line %d: %w", event.line, err)
+		}
+
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			key := day.Format("2006-01-02")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			days = append(days, &storage.TimeOff{Day: day, Kind: kind, Name: event.summary})
+		}
+	}
+
+	sort.Slice(days, func(a, b int) bool {
+		return days[a].Day.Before(days[b].Day)
+	})
+
+	return days, skipped, nil
+}
+
+// eventDays returns the first and last day of an event, as the start of the
+// day in loc.
+func eventDays(event *icsEvent, loc *time.Location) (time.Time, time.Time, error) {
+	if event.start == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("no DTSTART")
+	}
+
+	start, allDay, err := parseICSTime(event.start, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid DTSTART '%s'", event.start)
+	}
+	first := period.Day(start).Start
+
+	if event.end == "" {
+		return first, first, nil
+	}
+
+	end, _, err := parseICSTime(event.end, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid DTEND '%s'", event.end)
+	}
+
+	// the end is excluded, whether it is a date or a time
+	last := period.Day(end.Add(-time.Nanosecond)).Start
+	if allDay {
+		last = period.Day(end).Start.AddDate(0, 0, -1)
+	}
+
+	if last.Before(first) {
+		return first, first, nil
+	}
+	if last.After(first.AddDate(0, 0, maxDays)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("longer than %d days", maxDays)
+	}
+
+	return first, last, nil
+}
+
+// parseICSTime parses a DATE (20261224) or DATE-TIME (20261224T090000, with
+// a trailing Z in UTC) value. Times without a Z are read in loc. It reports
+// whether the value was a date.
+func parseICSTime(value string, loc *time.Location) (time.Time, bool, error) {
+	if len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t.In(loc), false, err
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// unescapeICS undoes the escaping of iCalendar text values. Line breaks
+// become spaces.
+func unescapeICS(value string) string {
+	replacer := strings.NewReplacer(`\\`, `\`, `\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ")
+	return strings.TrimSpace(replacer.Replace(value))
+}
@@ -0,0 +1,71 @@
+package timeoff
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseICS(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("events", func(t *testing.T) {
+		calendar := strings.Join([]string{
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"BEGIN:VEVENT",
+			"DTSTART;VALUE=DATE:20261225",
+			"DTEND;VALUE=DATE:20261227",
+			"SUMMARY:Christmas\\, Boxing",
+			"  Day",
+			"BEGIN:VALARM",
+			"SUMMARY:Reminder",
+			"END:VALARM",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"DTSTART:20261224T090000Z",
+			"DTEND:20261225T000000Z",
+			"SUMMARY:Christmas Eve",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"DTSTART;VALUE=DATE:20260101",
+			"RRULE:FREQ=YEARLY",
+			"SUMMARY:New Year",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"DTSTART;VALUE=DATE:20261231",
+			"STATUS:CANCELLED",
+			"END:VEVENT",
+			"END:VCALENDAR",
+		}, "\r\n")
+
+		days, skipped, err := parseICS(strings.NewReader(calendar), storage.TimeOffHoliday, time.UTC)
+		require.NoError(t, err)
+		assert.Equal(t, 2, skipped, "the recurring and cancelled events")
+
+		require.Len(t, days, 3)
+		assert.Equal(t, day(time.December, 24), days[0].Day)
+		assert.Equal(t, "Christmas Eve", days[0].Name, "the end of a timed event is excluded")
+		assert.Equal(t, day(time.December, 25), days[1].Day)
+		assert.Equal(t, "Christmas, Boxing Day", days[1].Name)
+		assert.Equal(t, day(time.December, 26), days[2].Day, "the end date of an all-day event is excluded")
+		assert.Equal(t, storage.TimeOffHoliday, days[2].Kind)
+	})
+
+	t.Run("not a calendar", func(t *testing.T) {
+		_, _, err := parseICS(strings.NewReader("2026-12-25 Christmas Day\n"), storage.TimeOffHoliday, time.UTC)
+		assert.ErrorContains(t, err, "not an iCalendar file")
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		calendar := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\nEND:VCALENDAR\n"
+		_, _, err := parseICS(strings.NewReader(calendar), storage.TimeOffHoliday, time.UTC)
+		assert.ErrorContains(t, err, "line 2")
+	})
+}
@@ -0,0 +1,106 @@
+package timeoff
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/report"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func ListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [period]",
+		Short: "List days off",
+		Long: `List the days off of a period, this year by default, with the number of days of each type.
+
+Holidays from the holidays file of the working hours in the global config are listed too.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			spec := "this-year"
+			if len(args) > 0 {
+				spec = args[0]
+			}
+
+			span, err := parseDays(spec, time.Now())
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			calendar, err := db.GetTimeOff(span.Start, span.End)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			var holidays map[string]string
+			if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
+				if holidays, err = globalCfg.WorkingHours.LoadHolidays(); err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("working_hours: %v", err))
+					os.Exit(1)
+				}
+			}
+
+			var days []*storage.TimeOff
+			for _, off := range report.NewDaysOff(calendar, holidays) {
+				if span.Contains(off.Day) {
+					days = append(days, off)
+				}
+			}
+			sort.Slice(days, func(a, b int) bool {
+				return days[a].Day.Before(days[b].Day)
+			})
+
+			if len(days) == 0 {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("No days off in %s", formatSpan(span)))
+				ui.PrintMuted(0, "Use 'tmpo off add' to add holidays, vacation or sick days.")
+				ui.NewlineBelow()
+				return
+			}
+
+			ui.PrintSuccess(ui.EmojiTimeOff, fmt.Sprintf("Days Off in %s", ui.Bold(formatSpan(span))))
+			fmt.Println()
+
+			counts := make(map[string]int)
+			for _, off := range days {
+				counts[off.Kind]++
+
+				source := ""
+				if off.ID == 0 {
+					source = ui.Muted("  (holidays file)")
+				}
+				fmt.Printf("    %s  %-8s  %s%s\n", settings.FormatDate(off.Day)+" "+off.Day.Format("Mon"), off.Kind, off.Name, source)
+			}
+
+			var totals []string
+			for _, kind := range storage.TimeOffKinds {
+				if counts[kind] > 0 {
+					totals = append(totals, fmt.Sprintf("%s %s", countDays(counts[kind]), kind))
+				}
+			}
+
+			fmt.Println()
+			ui.PrintInfo(4, ui.Bold("Total"), strings.Join(totals, ", "))
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,85 @@
+package timeoff
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// maxDays is the longest period days off can be added for at once.
+const maxDays = 366
+
+func TimeOffCmds() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "off",
+		Short: "Manage holidays, vacation and sick days",
+		Long: `Keep a calendar of days off: public holidays, vacation and sick days.
+
+Goals, 'tmpo stats --overtime' and 'tmpo timesheet' don't expect work on days off, so they don't show up as missed days. Holidays can also be imported from an .ics calendar.`,
+	}
+
+	cmd.AddCommand(AddCmd())
+	cmd.AddCommand(ListCmd())
+	cmd.AddCommand(RemoveCmd())
+	cmd.AddCommand(ImportCmd())
+
+	return cmd
+}
+
+// parseDays parses a day or a range of days such as 2026-12-24 or
+// 2026-12-21..2026-12-31. The range must be closed.
+func parseDays(spec string, now time.Time) (period.Range, error) {
+	span, err := period.ParseWithWeekStart(spec, now, settings.WeekStart())
+	if err != nil {
+		return period.Range{}, err
+	}
+
+	if span.Start.IsZero() || span.End.IsZero() {
+		return period.Range{}, fmt.Errorf("'%s' needs a start and an end", spec)
+	}
+
+	return span, nil
+}
+
+// workingDays returns the working days of the working hours in the global
+// config, Monday to Friday when none are set.
+func workingDays() map[time.Weekday]bool {
+	var policy settings.WorkingHours
+	if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
+		policy = globalCfg.WorkingHours
+	}
+
+	days, err := policy.WorkingDays()
+	if err != nil {
+		days, _ = settings.WorkingHours{}.WorkingDays()
+	}
+	return days
+}
+
+// formatSpan formats a range of days, e.g. "12/21/2026 – 12/31/2026".
+func formatSpan(span period.Range) string {
+	if period.Day(span.Start).End.Equal(span.End) {
+		return settings.FormatDate(span.Start)
+	}
+	return settings.FormatDate(span.Start) + " – " + settings.FormatDate(span.End.Add(-time.Nanosecond))
+}
+
+// formatDayOff names a day off and its type, e.g. "Christmas Day (holiday)".
+func formatDayOff(off *storage.TimeOff) string {
+	if off.Name == "" {
+		return off.Label()
+	}
+	return fmt.Sprintf("%s (%s)", off.Name, off.Kind)
+}
+
+// countDays formats a number of days, e.g. "1 day" or "5 days".
+func countDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
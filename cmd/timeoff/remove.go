@@ -0,0 +1,57 @@
+package timeoff
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func RemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove [date|range]",
+		Short: "Remove days off",
+		Long: `Remove a day off, or every day off in a range, from the time-off calendar.
+
+Holidays from the holidays file stay until they are removed from the file. Use 'tmpo undo' to bring removed days back.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			span, err := parseDays(args[0], time.Now())
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				ui.NewlineBelow()
+				os.Exit(1)
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			removed, err := db.RemoveTimeOff(span.Start, span.End)
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if removed == 0 {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("No days off in %s", formatSpan(span)))
+				ui.NewlineBelow()
+				return
+			}
+
+			ui.PrintSuccess(ui.EmojiTimeOff, fmt.Sprintf("Removed %s off in %s", ui.Bold(countDays(removed)), formatSpan(span)))
+			ui.PrintMuted(4, "Use 'tmpo undo' to bring them back.")
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
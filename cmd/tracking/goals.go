@@ -0,0 +1,101 @@
+package tracking
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/project"
+	"github.com/DylanDevelops/tmpo/internal/report"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+)
+
+// goalBarWidth is the width of the goal progress bars.
+const goalBarWidth = 20
+
+// printGoals prints the progress towards today's and this week's goals set
+// in the global config and in the current project's .tmporc, if any. Status
+// is still useful without it, so problems leave it out.
+func printGoals(db *storage.Database) {
+	var global, projectGoals settings.Goals
+	var projectName string
+	var workingHours settings.WorkingHours
+	loc, firstWeekday := time.Local, time.Monday
+
+	if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
+		global, workingHours = globalCfg.Goals, globalCfg.WorkingHours
+		loc, firstWeekday = globalCfg.Location(), globalCfg.FirstWeekday()
+	}
+
+	if cfg, _, err := settings.FindAndLoad(); err == nil && cfg != nil && cfg.Goals.IsSet() {
+		if name, err := project.DetectConfiguredProject(); err == nil {
+			projectGoals, projectName = cfg.Goals, name
+		}
+	}
+
+	if !global.IsSet() && !projectGoals.IsSet() {
+		return
+	}
+
+	now := time.Now().In(loc)
+	week := period.WeekStarting(now, firstWeekday)
+
+	entries, err := db.GetEntriesByDateRange(week.Start, week.End)
+	if err != nil {
+		return
+	}
+
+	calendar, err := db.GetTimeOff(week.Start, week.End)
+	if err != nil {
+		return
+	}
+	holidays, err := workingHours.LoadHolidays()
+	if err != nil {
+		return
+	}
+	daysOff := report.NewDaysOff(calendar, holidays)
+	workingDays, err := workingHours.WorkingDays()
+	if err != nil {
+		return
+	}
+
+	progress := report.TrackGoals(entries, global, projectGoals, projectName, daysOff, workingDays, firstWeekday, now)
+
+	labels := make([]string, len(progress))
+	width := 0
+	for i, p := range progress {
+		labels[i] = "Today"
+		if p.Period == report.GoalWeekly {
+			labels[i] = "This week"
+		}
+		if p.Project != "" {
+			labels[i] = fmt.Sprintf("%s (%s)", labels[i], p.Project)
+		}
+		width = max(width, len([]rune(labels[i])))
+	}
+
+	fmt.Println()
+	ui.PrintInfo(4, ui.Bold("Goals"), "")
+	if today := daysOff.On(now); today != nil {
+		ui.PrintMuted(8, fmt.Sprintf("Today is a day off: %s", today.Label()))
+	}
+	for i, p := range progress {
+		daysOffNote := ""
+		switch {
+		case p.DaysOff == 1:
+			daysOffNote = ui.Muted(" (1 day off)")
+		case p.DaysOff > 1:
+			daysOffNote = ui.Muted(fmt.Sprintf(" (%d days off)", p.DaysOff))
+		}
+
+		fmt.Printf("        %s  %s  %s of %gh%s\n",
+			labels[i]+strings.Repeat(" ", width-len([]rune(labels[i]))),
+			ui.GoalBar(p.Fraction(), goalBarWidth),
+			ui.FormatDuration(p.Tracked),
+			p.Target.Hours(),
+			daysOffNote)
+	}
+}
@@ -85,6 +85,15 @@ func StartCmd() *cobra.Command {
 
 			if milestoneName != nil {
 				ui.PrintInfo(4, "Milestone", *milestoneName)
+
+				if progress, err := db.GetMilestoneProgress(activeMilestone); err == nil {
+					if progress.OverBudget() {
+						ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("Milestone %s is over budget", ui.Bold(activeMilestone.Name)))
+						ui.PrintMuted(4, "└─ Use 'tmpo milestone status' to see its progress")
+					} else if progress.Overdue() {
+						ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("Milestone %s is past its due date", ui.Bold(activeMilestone.Name)))
+					}
+				}
 			}
 
 			ui.NewlineBelow()
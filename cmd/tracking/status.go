@@ -36,6 +36,7 @@ func StatusCmd() *cobra.Command {
 
 			if running == nil {
 				ui.PrintWarning(ui.EmojiWarning, "Not currently tracking time")
+				printGoals(db)
 				ui.NewlineBelow()
 				ui.PrintMuted(0, "Use 'tmpo start' to begin tracking")
 				ui.NewlineBelow()
@@ -52,10 +53,12 @@ func StatusCmd() *cobra.Command {
 				ui.PrintInfo(4, ui.Bold("Description"), running.Description)
 			}
 
-			if *running.MilestoneName != "" {
+			if running.MilestoneName != nil && *running.MilestoneName != "" {
 				ui.PrintInfo(4, ui.Bold("Milestone"), *running.MilestoneName);
 			}
 
+			printGoals(db)
+
 			ui.NewlineBelow()
 		},
 	}
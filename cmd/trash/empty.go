@@ -0,0 +1,85 @@
+package trash
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+var emptyYes bool
+
+func EmptyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Permanently remove everything in the trash",
+		Long:  `Permanently remove every entry and milestone in the trash. A snapshot of the database is taken first.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			entries, err := db.GetTrashedEntries()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			milestones, err := db.GetTrashedMilestones()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if len(entries) == 0 && len(milestones) == 0 {
+				ui.PrintWarning(ui.EmojiWarning, "The trash is already empty")
+				ui.NewlineBelow()
+				return
+			}
+
+			if !emptyYes {
+				ui.PrintWarning(ui.EmojiWarning, fmt.Sprintf("This will permanently remove %d entries and %d milestones", len(entries), len(milestones)))
+				fmt.Println()
+
+				confirmPrompt := promptui.Select{
+					Label: "Empty the trash?",
+					Items: []string{"No", "Yes"},
+				}
+
+				_, result, err := confirmPrompt.Run()
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					os.Exit(1)
+				}
+
+				if result == "No" {
+					ui.PrintWarning(ui.EmojiWarning, "Trash not emptied")
+					ui.NewlineBelow()
+					os.Exit(0)
+				}
+			}
+
+			removedEntries, removedMilestones, err := db.EmptyTrash()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Removed %d entries and %d milestones", removedEntries, removedMilestones))
+			ui.NewlineBelow()
+		},
+	}
+
+	cmd.Flags().BoolVarP(&emptyYes, "yes", "y", false, "Skip the confirmation prompt")
+
+	return cmd
+}
@@ -0,0 +1,75 @@
+package trash
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func ListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List deleted entries and milestones",
+		Long:  `List the entries and milestones in the trash, most recently deleted first.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			entries, err := db.GetTrashedEntries()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			milestones, err := db.GetTrashedMilestones()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+
+			if len(entries) == 0 && len(milestones) == 0 {
+				ui.PrintWarning(ui.EmojiWarning, "The trash is empty")
+				ui.NewlineBelow()
+				return
+			}
+
+			ui.PrintSuccess(ui.EmojiTrash, fmt.Sprintf("Trash (%d item(s))", len(entries)+len(milestones)))
+			fmt.Println()
+
+			if len(entries) > 0 {
+				ui.PrintInfo(0, ui.Bold("Entries"), "")
+				for _, entry := range entries {
+					fmt.Printf("    %s  %s\n", formatTrashedEntry(entry), formatDeletedAt(entry.DeletedAt))
+				}
+				fmt.Println()
+			}
+
+			if len(milestones) > 0 {
+				ui.PrintInfo(0, ui.Bold("Milestones"), "")
+				for _, milestone := range milestones {
+					fmt.Printf("    %s  %s\n", formatTrashedMilestone(milestone), formatDeletedAt(milestone.DeletedAt))
+				}
+				fmt.Println()
+			}
+
+			if globalCfg, err := settings.LoadGlobalConfig(); err == nil && globalCfg.TrashDaysToKeep() > 0 {
+				ui.PrintMuted(0, fmt.Sprintf("Items are permanently removed %d days after deletion.", globalCfg.TrashDaysToKeep()))
+			}
+			ui.PrintMuted(0, "Use 'tmpo trash restore' to bring items back.")
+			ui.NewlineBelow()
+		},
+	}
+
+	return cmd
+}
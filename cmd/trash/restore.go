@@ -0,0 +1,130 @@
+package trash
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+var restoreMilestones bool
+
+func RestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore [id...]",
+		Short: "Restore deleted entries or milestones",
+		Long:  `Restore entries from the trash by ID, or choose an item interactively when no IDs are given. Use --milestone to restore milestones by ID.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ui.NewlineAbove()
+
+			var ids []int64
+			for _, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("invalid ID '%s'", arg))
+					os.Exit(1)
+				}
+				ids = append(ids, id)
+			}
+
+			db, err := storage.Initialize()
+			if err != nil {
+				ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			if len(ids) == 0 {
+				restoreInteractively(db)
+				ui.NewlineBelow()
+				return
+			}
+
+			failed := false
+			for _, id := range ids {
+				restore := db.RestoreEntry
+				what := "entry"
+				if restoreMilestones {
+					restore = db.RestoreMilestone
+					what = "milestone"
+				}
+
+				if err := restore(id); err != nil {
+					ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+					failed = true
+					continue
+				}
+
+				ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Restored %s #%d", what, id))
+			}
+
+			ui.NewlineBelow()
+			if failed {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&restoreMilestones, "milestone", "m", false, "Treat the IDs as milestone IDs")
+
+	return cmd
+}
+
+func restoreInteractively(db *storage.Database) {
+	entries, err := db.GetTrashedEntries()
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	milestones, err := db.GetTrashedMilestones()
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	if len(entries) == 0 && len(milestones) == 0 {
+		ui.PrintWarning(ui.EmojiWarning, "The trash is empty")
+		return
+	}
+
+	var items []string
+	for _, entry := range entries {
+		items = append(items, formatTrashedEntry(entry))
+	}
+	for _, milestone := range milestones {
+		items = append(items, "Milestone "+formatTrashedMilestone(milestone))
+	}
+
+	itemPrompt := promptui.Select{
+		Label: "Select item to restore",
+		Items: items,
+	}
+
+	idx, _, err := itemPrompt.Run()
+	if err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+
+	if idx < len(entries) {
+		entry := entries[idx]
+		if err := db.RestoreEntry(entry.ID); err != nil {
+			ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+		ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Restored entry #%d for %s", entry.ID, ui.Bold(entry.ProjectName)))
+		return
+	}
+
+	milestone := milestones[idx-len(entries)]
+	if err := db.RestoreMilestone(milestone.ID); err != nil {
+		ui.PrintError(ui.EmojiError, fmt.Sprintf("%v", err))
+		os.Exit(1)
+	}
+	ui.PrintSuccess(ui.EmojiSuccess, fmt.Sprintf("Restored milestone %s", ui.Bold(milestone.Name)))
+}
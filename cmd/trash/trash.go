@@ -0,0 +1,47 @@
+package trash
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/DylanDevelops/tmpo/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func TrashCmds() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "Manage deleted entries and milestones",
+		Long:  `Deleted entries and milestones are moved to the trash, where they can be restored until they are purged automatically or the trash is emptied.`,
+	}
+
+	cmd.AddCommand(ListCmd())
+	cmd.AddCommand(RestoreCmd())
+	cmd.AddCommand(EmptyCmd())
+
+	return cmd
+}
+
+func formatTrashedEntry(entry *storage.TimeEntry) string {
+	end := "running"
+	if entry.EndTime != nil {
+		end = settings.FormatTime(*entry.EndTime)
+	}
+
+	description := entry.Description
+	if description == "" {
+		description = "(no description)"
+	}
+
+	return fmt.Sprintf("#%d %s: %s → %s - %s", entry.ID, entry.ProjectName, settings.FormatDateTimeDashed(entry.StartTime), end, description)
+}
+
+func formatTrashedMilestone(milestone *storage.Milestone) string {
+	return fmt.Sprintf("#%d %s: %s (started %s)", milestone.ID, milestone.ProjectName, milestone.Name, settings.FormatDateDashed(milestone.StartTime))
+}
+
+func formatDeletedAt(deletedAt *time.Time) string {
+	return ui.Muted(fmt.Sprintf("deleted %s", settings.FormatDateTimeDashed(*deletedAt)))
+}
@@ -0,0 +1,141 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+)
+
+// Column describes a single CSV column: the key used to select it with
+// --columns, its header text and how its value is derived from an entry.
+type Column struct {
+	Key    string
+	Header string
+	value  func(entry *storage.TimeEntry, loc *time.Location) string
+}
+
+var columns = []Column{
+	{Key: "id", Header: "ID", value: func(e *storage.TimeEntry, _ *time.Location) string {
+		return strconv.FormatInt(e.ID, 10)
+	}},
+	{Key: "project", Header: "Project", value: func(e *storage.TimeEntry, _ *time.Location) string {
+		return e.ProjectName
+	}},
+	{Key: "start", Header: "Start Time", value: func(e *storage.TimeEntry, loc *time.Location) string {
+		return formatTimestamp(e.StartTime, loc)
+	}},
+	{Key: "end", Header: "End Time", value: func(e *storage.TimeEntry, loc *time.Location) string {
+		if e.EndTime == nil {
+			return ""
+		}
+		return formatTimestamp(*e.EndTime, loc)
+	}},
+	{Key: "duration", Header: "Duration (hours)", value: func(e *storage.TimeEntry, _ *time.Location) string {
+		return fmt.Sprintf("%.2f", e.Duration().Hours())
+	}},
+	{Key: "description", Header: "Description", value: func(e *storage.TimeEntry, _ *time.Location) string {
+		return e.Description
+	}},
+	{Key: "milestone", Header: "Milestone", value: func(e *storage.TimeEntry, _ *time.Location) string {
+		if e.MilestoneName == nil {
+			return ""
+		}
+		return *e.MilestoneName
+	}},
+	{Key: "rate", Header: "Hourly Rate", value: func(e *storage.TimeEntry, _ *time.Location) string {
+		if e.HourlyRate == nil {
+			return ""
+		}
+		return fmt.Sprintf("%.2f", *e.HourlyRate)
+	}},
+	{Key: "earnings", Header: "Earnings", value: func(e *storage.TimeEntry, _ *time.Location) string {
+		earnings := entryEarnings(e)
+		if earnings == nil {
+			return ""
+		}
+		return fmt.Sprintf("%.2f", *earnings)
+	}},
+	{Key: "edited", Header: "Edited", value: func(e *storage.TimeEntry, _ *time.Location) string {
+		if !e.Edited {
+			return ""
+		}
+		return "yes"
+	}},
+}
+
+// ColumnKeys returns the keys of every available column in default order.
+func ColumnKeys() []string {
+	keys := make([]string, len(columns))
+	for i, column := range columns {
+		keys[i] = column.Key
+	}
+	return keys
+}
+
+// ParseColumns turns a comma separated list of column keys into columns,
+// keeping the order given. An empty spec selects every column.
+func ParseColumns(spec string) ([]Column, error) {
+	if strings.TrimSpace(spec) == "" {
+		return columns, nil
+	}
+
+	var selected []Column
+	seen := make(map[string]bool)
+
+	for _, key := range strings.Split(spec, ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+
+		column, ok := findColumn(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown column '%s' (available: %s)", key, strings.Join(ColumnKeys(), ", "))
+		}
+
+		if seen[key] {
+			return nil, fmt.Errorf("column '%s' listed more than once", key)
+		}
+		seen[key] = true
+
+		selected = append(selected, column)
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+
+	return selected, nil
+}
+
+func findColumn(key string) (Column, bool) {
+	for _, column := range columns {
+		if column.Key == key {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+// formatTimestamp renders t as RFC 3339 with its UTC offset, converted to
+// loc when one is given.
+func formatTimestamp(t time.Time, loc *time.Location) string {
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// entryEarnings returns the billable amount for an entry, or nil when the
+// entry has no hourly rate.
+func entryEarnings(entry *storage.TimeEntry) *float64 {
+	if entry.HourlyRate == nil {
+		return nil
+	}
+
+	earnings := entry.RoundedHours() * *entry.HourlyRate
+	return &earnings
+}
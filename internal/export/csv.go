@@ -3,54 +3,64 @@ package export
 import (
 	"encoding/csv"
 	"fmt"
-	"os"
+	"io"
+	"time"
 
 	"github.com/DylanDevelops/tmpo/internal/storage"
 )
 
-func ToCSV(entries []*storage.TimeEntry, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create CSV file: %w", err)
-	}
+// CSVWriter streams time entries as CSV rows, one row per entry.
+type CSVWriter struct {
+	writer   *csv.Writer
+	columns  []Column
+	location *time.Location
+}
 
-	defer file.Close()
+// NewCSVWriter writes the CSV header to w and returns a writer for the rows.
+func NewCSVWriter(w io.Writer, opts Options) (*CSVWriter, error) {
+	selected := opts.Columns
+	if len(selected) == 0 {
+		selected = columns
+	}
 
-	writer := csv.NewWriter(file)
+	writer := csv.NewWriter(w)
 
-	defer writer.Flush()
+	header := make([]string, len(selected))
+	for i, column := range selected {
+		header[i] = column.Header
+	}
 
-	header := []string{"Project", "Start Time", "End Time", "Duration (hours)", "Description", "Milestone"}
 	if err := writer.Write(header); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	return &CSVWriter{writer: writer, columns: selected, location: opts.Location}, nil
+}
+
+func (c *CSVWriter) Write(entry *storage.TimeEntry) error {
+	record := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		record[i] = column.value(entry, c.location)
 	}
 
-	for _, entry := range entries {
-		endTime := ""
-		if entry.EndTime != nil {
-			endTime = entry.EndTime.Format("2006-01-02 15:04:05")
-		}
-
-		milestoneName := ""
-		if entry.MilestoneName != nil {
-			milestoneName = *entry.MilestoneName
-		}
-
-		duration := entry.Duration().Hours()
-
-		record := []string{
-			entry.ProjectName,
-			entry.StartTime.Format("2006-01-02 15:04:05"),
-			endTime,
-			fmt.Sprintf("%.2f", duration),
-			entry.Description,
-			milestoneName,
-		}
-
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("failed to write record: %w", err)
-		}
+	if err := c.writer.Write(record); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// Close flushes any buffered rows to the underlying writer.
+func (c *CSVWriter) Close() error {
+	c.writer.Flush()
+
+	if err := c.writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return nil
+}
+
+func ToCSV(entries []*storage.TimeEntry, filename string) error {
+	return writeFile(FormatCSV, entries, filename)
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Options controls how entries are rendered.
+type Options struct {
+	// Columns selects and orders the CSV columns. Empty means every column.
+	Columns []Column
+	// Location converts timestamps before formatting. Nil keeps each
+	// timestamp in the zone it was recorded in.
+	Location *time.Location
+}
+
+// Writer receives time entries one at a time. Close must be called once all
+// entries have been written to finish the document.
+type Writer interface {
+	Write(entry *storage.TimeEntry) error
+	Close() error
+}
+
+// NewWriter returns a streaming writer for the given export format.
+func NewWriter(format string, w io.Writer, opts Options) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w, opts)
+	case FormatJSON:
+		return NewJSONWriter(w, opts), nil
+	default:
+		return nil, fmt.Errorf("unknown format '%s'. Use 'csv' or 'json'", format)
+	}
+}
+
+func writeFile(format string, entries []*storage.TimeEntry, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create %s file: %w", strings.ToUpper(format), err)
+	}
+
+	writer, err := NewWriter(format, file, Options{})
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := writer.Write(entry); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
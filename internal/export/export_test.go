@@ -1,6 +1,7 @@
 package export
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"os"
@@ -57,15 +58,19 @@ func TestToCSV(t *testing.T) {
 		assert.Len(t, records, 3)
 
 		// Verify header
-		assert.Equal(t, []string{"Project", "Start Time", "End Time", "Duration (hours)", "Description", "Milestone"}, records[0])
+		assert.Equal(t, []string{"ID", "Project", "Start Time", "End Time", "Duration (hours)", "Description", "Milestone", "Hourly Rate", "Earnings", "Edited"}, records[0])
 
 		// Verify first entry
-		assert.Equal(t, "test-project", records[1][0])
-		assert.Equal(t, "2024-01-01 09:00:00", records[1][1])
-		assert.Equal(t, "2024-01-01 17:00:00", records[1][2])
-		assert.Equal(t, "8.00", records[1][3]) // 8 hours
-		assert.Equal(t, "Test work", records[1][4])
-		assert.Equal(t, "", records[1][5]) // No milestone
+		assert.Equal(t, "1", records[1][0])
+		assert.Equal(t, "test-project", records[1][1])
+		assert.Equal(t, "2024-01-01T09:00:00Z", records[1][2])
+		assert.Equal(t, "2024-01-01T17:00:00Z", records[1][3])
+		assert.Equal(t, "8.00", records[1][4]) // 8 hours
+		assert.Equal(t, "Test work", records[1][5])
+		assert.Equal(t, "", records[1][6]) // No milestone
+		assert.Equal(t, "", records[1][7]) // No rate
+		assert.Equal(t, "", records[1][8]) // No earnings
+		assert.Equal(t, "", records[1][9]) // Not edited
 	})
 
 	t.Run("handles running entries", func(t *testing.T) {
@@ -95,7 +100,7 @@ func TestToCSV(t *testing.T) {
 		assert.NoError(t, err)
 
 		// End time should be empty string
-		assert.Empty(t, records[1][2])
+		assert.Empty(t, records[1][3])
 	})
 
 	t.Run("handles empty entries", func(t *testing.T) {
@@ -146,7 +151,7 @@ func TestToCSV(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Description should be empty string
-		assert.Empty(t, records[1][4])
+		assert.Empty(t, records[1][5])
 	})
 }
 
@@ -196,6 +201,7 @@ func TestToJson(t *testing.T) {
 		assert.Len(t, exportedEntries, 2)
 
 		// Verify first entry
+		assert.Equal(t, int64(1), exportedEntries[0].ID)
 		assert.Equal(t, "test-project", exportedEntries[0].Project)
 		assert.Equal(t, "2024-01-01T09:00:00Z", exportedEntries[0].StartTime)
 		assert.Equal(t, "2024-01-01T17:00:00Z", exportedEntries[0].EndTime)
@@ -289,3 +295,178 @@ func TestToJson(t *testing.T) {
 		}
 	})
 }
+
+func TestNewWriter(t *testing.T) {
+	startTime := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	endTime := time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC)
+
+	entries := []*storage.TimeEntry{
+		{ID: 1, ProjectName: "first", StartTime: startTime, EndTime: &endTime},
+		{ID: 2, ProjectName: "second", StartTime: startTime, EndTime: &endTime, Description: "More work"},
+	}
+
+	t.Run("streams CSV to any writer", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		writer, err := NewWriter(FormatCSV, &buf, Options{})
+		assert.NoError(t, err)
+
+		for _, entry := range entries {
+			assert.NoError(t, writer.Write(entry))
+		}
+		assert.NoError(t, writer.Close())
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, records, 3)
+		assert.Equal(t, "second", records[2][1])
+	})
+
+	t.Run("streams a valid JSON array", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		writer, err := NewWriter(FormatJSON, &buf, Options{})
+		assert.NoError(t, err)
+
+		for _, entry := range entries {
+			assert.NoError(t, writer.Write(entry))
+		}
+		assert.NoError(t, writer.Close())
+
+		var exported []ExportEntry
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+		assert.Len(t, exported, 2)
+		assert.Equal(t, "More work", exported[1].Description)
+	})
+
+	t.Run("writes an empty JSON array when there are no entries", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		writer, err := NewWriter(FormatJSON, &buf, Options{})
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		assert.Equal(t, "[]\n", buf.String())
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		_, err := NewWriter("xml", &bytes.Buffer{}, Options{})
+		assert.Error(t, err)
+	})
+}
+
+func TestRichColumns(t *testing.T) {
+	startTime := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	endTime := time.Date(2024, 1, 1, 11, 30, 0, 0, time.UTC)
+	rate := 100.0
+	milestone := "Sprint 1"
+
+	entry := &storage.TimeEntry{
+		ID:            42,
+		ProjectName:   "billable",
+		StartTime:     startTime,
+		EndTime:       &endTime,
+		Description:   "Client work",
+		HourlyRate:    &rate,
+		MilestoneName: &milestone,
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	t.Run("includes rate and earnings in CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		writer, err := NewWriter(FormatCSV, &buf, Options{})
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Write(entry))
+		assert.NoError(t, writer.Close())
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"42", "billable", "2024-01-01T09:00:00Z", "2024-01-01T11:30:00Z", "2.50", "Client work", "Sprint 1", "100.00", "250.00", ""}, records[1])
+	})
+
+	t.Run("selects and orders columns", func(t *testing.T) {
+		selected, err := ParseColumns("earnings, id,project")
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		writer, err := NewWriter(FormatCSV, &buf, Options{Columns: selected})
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Write(entry))
+		assert.NoError(t, writer.Close())
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Earnings", "ID", "Project"}, records[0])
+		assert.Equal(t, []string{"250.00", "42", "billable"}, records[1])
+	})
+
+	t.Run("marks edited entries", func(t *testing.T) {
+		edited := *entry
+		edited.Edited = true
+
+		selected, err := ParseColumns("id,edited")
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		writer, err := NewWriter(FormatCSV, &buf, Options{Columns: selected})
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Write(entry))
+		assert.NoError(t, writer.Write(&edited))
+		assert.NoError(t, writer.Close())
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ID", "Edited"}, records[0])
+		assert.Equal(t, []string{"42", ""}, records[1])
+		assert.Equal(t, []string{"42", "yes"}, records[2])
+
+		buf.Reset()
+		jsonWriter := NewJSONWriter(&buf, Options{})
+		assert.NoError(t, jsonWriter.Write(&edited))
+		assert.NoError(t, jsonWriter.Close())
+
+		var exported []ExportEntry
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+		assert.True(t, exported[0].Edited)
+	})
+
+	t.Run("converts timestamps to the requested location", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		writer, err := NewWriter(FormatJSON, &buf, Options{Location: newYork})
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Write(entry))
+		assert.NoError(t, writer.Close())
+
+		var exported []ExportEntry
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+		assert.Equal(t, "2024-01-01T04:00:00-05:00", exported[0].StartTime)
+		assert.Equal(t, "2024-01-01T06:30:00-05:00", exported[0].EndTime)
+		assert.Equal(t, 100.0, *exported[0].HourlyRate)
+		assert.Equal(t, 250.0, *exported[0].Earnings)
+	})
+}
+
+func TestParseColumns(t *testing.T) {
+	t.Run("empty spec selects all columns", func(t *testing.T) {
+		selected, err := ParseColumns("")
+		assert.NoError(t, err)
+		assert.Len(t, selected, len(ColumnKeys()))
+	})
+
+	t.Run("rejects unknown columns", func(t *testing.T) {
+		_, err := ParseColumns("id,bogus")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "bogus")
+	})
+
+	t.Run("rejects duplicate columns", func(t *testing.T) {
+		_, err := ParseColumns("id,ID")
+		assert.Error(t, err)
+	})
+}
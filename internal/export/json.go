@@ -3,55 +3,94 @@ package export
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"time"
 
 	"github.com/DylanDevelops/tmpo/internal/storage"
 )
 
 type ExportEntry struct {
-	Project     string  `json:"project"`
-	StartTime   string  `json:"start_time"`
-	EndTime     string  `json:"end_time,omitempty"`
-	Duration    float64 `json:"duration_hours"`
-	Description string  `json:"description,omitempty"`
-	Milestone   string  `json:"milestone,omitempty"`
+	ID          int64    `json:"id"`
+	Project     string   `json:"project"`
+	StartTime   string   `json:"start_time"`
+	EndTime     string   `json:"end_time,omitempty"`
+	Duration    float64  `json:"duration_hours"`
+	Description string   `json:"description,omitempty"`
+	Milestone   string   `json:"milestone,omitempty"`
+	HourlyRate  *float64 `json:"hourly_rate,omitempty"`
+	Earnings    *float64 `json:"earnings,omitempty"`
+	Edited      bool     `json:"edited"`
 }
 
-func ToJson(entries []*storage.TimeEntry, filename string) error {
-	var exportEntries []ExportEntry
+// JSONWriter streams time entries as the elements of a JSON array, encoding
+// each entry as soon as it is written instead of buffering the whole array.
+type JSONWriter struct {
+	w        io.Writer
+	location *time.Location
+	count    int
+}
 
-	for _, entry := range entries {
-		export := ExportEntry{
-			Project:     entry.ProjectName,
-			StartTime:   entry.StartTime.Format("2006-01-02T15:04:05Z07:00"),
-			Duration:    entry.Duration().Hours(),
-			Description: entry.Description,
-		}
+func NewJSONWriter(w io.Writer, opts Options) *JSONWriter {
+	return &JSONWriter{w: w, location: opts.Location}
+}
 
-		if entry.EndTime != nil {
-			export.EndTime = entry.EndTime.Format("2006-01-02T15:04:05Z07:00")
-		}
+func (j *JSONWriter) Write(entry *storage.TimeEntry) error {
+	export := ExportEntry{
+		ID:          entry.ID,
+		Project:     entry.ProjectName,
+		StartTime:   formatTimestamp(entry.StartTime, j.location),
+		Duration:    entry.Duration().Hours(),
+		Description: entry.Description,
+		HourlyRate:  entry.HourlyRate,
+		Earnings:    entryEarnings(entry),
+		Edited:      entry.Edited,
+	}
 
-		if entry.MilestoneName != nil {
-			export.Milestone = *entry.MilestoneName
-		}
+	if entry.EndTime != nil {
+		export.EndTime = formatTimestamp(*entry.EndTime, j.location)
+	}
 
-		exportEntries = append(exportEntries, export)
+	if entry.MilestoneName != nil {
+		export.Milestone = *entry.MilestoneName
 	}
 
-	file, err := os.Create(filename)
+	data, err := json.MarshalIndent(export, "  ", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to create JSON file: %w", err)
+		return fmt.Errorf("failed to encode JSON: %w", err)
 	}
 
-	defer file.Close()
+	separator := ",\n  "
+	if j.count == 0 {
+		separator = "[\n  "
+	}
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+	if _, err := io.WriteString(j.w, separator); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
 
-	if err := encoder.Encode(exportEntries); err != nil {
-		return fmt.Errorf("failed to encode JSON: %w", err)
+	if _, err := j.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
 	}
 
+	j.count++
+
 	return nil
-}
\ No newline at end of file
+}
+
+// Close terminates the JSON array.
+func (j *JSONWriter) Close() error {
+	closing := "\n]\n"
+	if j.count == 0 {
+		closing = "[]\n"
+	}
+
+	if _, err := io.WriteString(j.w, closing); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+
+	return nil
+}
+
+func ToJson(entries []*storage.TimeEntry, filename string) error {
+	return writeFile(FormatJSON, entries, filename)
+}
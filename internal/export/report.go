@@ -0,0 +1,142 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/report"
+)
+
+type reportDocument struct {
+	Title         string        `json:"title"`
+	Start         string        `json:"start,omitempty"`
+	End           string        `json:"end,omitempty"`
+	Entries       int           `json:"entries"`
+	TotalHours    float64       `json:"total_hours"`
+	BillableHours float64       `json:"billable_hours"`
+	Earnings      float64       `json:"earnings"`
+	Days          []reportTotal `json:"days"`
+	Descriptions  []reportTotal `json:"descriptions"`
+	Gaps          []reportGap   `json:"gaps"`
+}
+
+type reportTotal struct {
+	Date        string  `json:"date,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Hours       float64 `json:"hours"`
+	Entries     int     `json:"entries"`
+	Earnings    float64 `json:"earnings"`
+}
+
+type reportGap struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Days  int    `json:"days"`
+}
+
+// WriteReport renders a report in the given export format. CSV puts every
+// figure on its own row, tagged with the section it belongs to.
+func WriteReport(format string, w io.Writer, r *report.Report) error {
+	switch format {
+	case FormatCSV:
+		return writeReportCSV(w, r)
+	case FormatJSON:
+		return writeReportJSON(w, r)
+	default:
+		return fmt.Errorf("unknown format '%s'. Use 'csv' or 'json'", format)
+	}
+}
+
+func writeReportJSON(w io.Writer, r *report.Report) error {
+	doc := reportDocument{
+		Title:         r.Title,
+		Entries:       r.Entries,
+		TotalHours:    roundHours(r.Total),
+		BillableHours: roundHours(r.Billable),
+		Earnings:      r.Earnings,
+		Days:          []reportTotal{},
+		Descriptions:  []reportTotal{},
+		Gaps:          []reportGap{},
+	}
+
+	if !r.Span.Start.IsZero() {
+		doc.Start = formatDay(r.Span.Start)
+	}
+
+	if !r.Span.End.IsZero() {
+		// the span is half-open, so its last day is the one before End
+		doc.End = formatDay(r.Span.End.Add(-time.Nanosecond))
+	}
+
+	for _, day := range r.Days {
+		doc.Days = append(doc.Days, reportTotal{Date: formatDay(day.Day), Hours: roundHours(day.Duration), Entries: day.Entries, Earnings: day.Earnings})
+	}
+
+	for _, total := range r.Descriptions {
+		description := total.Description
+		doc.Descriptions = append(doc.Descriptions, reportTotal{Description: &description, Hours: roundHours(total.Duration), Entries: total.Entries, Earnings: total.Earnings})
+	}
+
+	for _, gap := range r.Gaps {
+		doc.Gaps = append(doc.Gaps, reportGap{Start: formatDay(gap.Start), End: formatDay(gap.End), Days: gap.Days})
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+
+	return nil
+}
+
+func writeReportCSV(w io.Writer, r *report.Report) error {
+	writer := csv.NewWriter(w)
+
+	hours := func(d time.Duration) string {
+		return fmt.Sprintf("%.2f", d.Hours())
+	}
+	money := func(amount float64) string {
+		return fmt.Sprintf("%.2f", amount)
+	}
+
+	records := [][]string{
+		{"Section", "Label", "Hours", "Entries", "Earnings", "Days"},
+		{"summary", "Total", hours(r.Total), strconv.Itoa(r.Entries), money(r.Earnings), ""},
+		{"summary", "Billable", hours(r.Billable), "", "", ""},
+	}
+
+	for _, day := range r.Days {
+		records = append(records, []string{"day", formatDay(day.Day), hours(day.Duration), strconv.Itoa(day.Entries), money(day.Earnings), ""})
+	}
+
+	for _, total := range r.Descriptions {
+		records = append(records, []string{"description", total.Description, hours(total.Duration), strconv.Itoa(total.Entries), money(total.Earnings), ""})
+	}
+
+	for _, gap := range r.Gaps {
+		records = append(records, []string{"gap", fmt.Sprintf("%s..%s", formatDay(gap.Start), formatDay(gap.End)), "", "", "", strconv.Itoa(gap.Days)})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return nil
+}
+
+func formatDay(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func roundHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
@@ -0,0 +1,59 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/report"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReport(t *testing.T) {
+	rate := 50.0
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+
+	entries := []*storage.TimeEntry{
+		{ProjectName: "p", StartTime: start, EndTime: &end, Description: "api", HourlyRate: &rate},
+	}
+
+	span := period.Range{Start: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC)}
+	r := report.Build("Sprint 1", span, entries, time.UTC, span.End)
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteReport(FormatCSV, &buf, r))
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Section", "Label", "Hours", "Entries", "Earnings", "Days"}, records[0])
+		assert.Equal(t, []string{"summary", "Total", "1.50", "1", "75.00", ""}, records[1])
+		assert.Equal(t, []string{"day", "2024-03-04", "1.50", "1", "75.00", ""}, records[3])
+		assert.Equal(t, []string{"description", "api", "1.50", "1", "75.00", ""}, records[4])
+		assert.Equal(t, []string{"gap", "2024-03-05..2024-03-06", "", "", "", "2"}, records[5])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteReport(FormatJSON, &buf, r))
+
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "Sprint 1", doc["title"])
+		assert.Equal(t, "2024-03-04", doc["start"])
+		assert.Equal(t, "2024-03-06", doc["end"])
+		assert.Equal(t, 1.5, doc["billable_hours"])
+		assert.Len(t, doc["days"], 1)
+		assert.Len(t, doc["gaps"], 1)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.Error(t, WriteReport("xml", &bytes.Buffer{}, r))
+	})
+}
@@ -0,0 +1,143 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/report"
+)
+
+type timesheetDocument struct {
+	Start      string         `json:"start"`
+	End        string         `json:"end"`
+	By         string         `json:"by"`
+	Days       []string       `json:"days"`
+	Rows       []timesheetRow `json:"rows"`
+	DayTotals  []float64      `json:"day_totals"`
+	TotalHours float64        `json:"total_hours"`
+	TimeOff    []timesheetOff `json:"time_off"`
+}
+
+type timesheetOff struct {
+	Date string `json:"date"`
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type timesheetRow struct {
+	Label      string    `json:"label"`
+	Hours      []float64 `json:"hours"`
+	TotalHours float64   `json:"total_hours"`
+}
+
+// WriteTimesheet renders a timesheet in the given export format. CSV has a
+// row per timesheet row and a column per day, followed by a row of totals and,
+// when the week has days off, a row of their types; JSON lists the hours of
+// each row in the order of its days, and the days off.
+func WriteTimesheet(format string, w io.Writer, ts *report.Timesheet) error {
+	switch format {
+	case FormatCSV:
+		return writeTimesheetCSV(w, ts)
+	case FormatJSON:
+		return writeTimesheetJSON(w, ts)
+	default:
+		return fmt.Errorf("unknown format '%s'. Use 'csv' or 'json'", format)
+	}
+}
+
+func writeTimesheetJSON(w io.Writer, ts *report.Timesheet) error {
+	hours := func(durations []time.Duration) []float64 {
+		rounded := make([]float64, len(durations))
+		for i, d := range durations {
+			rounded[i] = roundHours(d)
+		}
+		return rounded
+	}
+
+	doc := timesheetDocument{
+		Start:      formatDay(ts.Span.Start),
+		End:        formatDay(ts.Span.End.Add(-time.Nanosecond)),
+		By:         ts.By,
+		Days:       []string{},
+		Rows:       []timesheetRow{},
+		DayTotals:  hours(ts.DayTotals),
+		TotalHours: roundHours(ts.Total),
+		TimeOff:    []timesheetOff{},
+	}
+
+	for _, day := range ts.Days {
+		doc.Days = append(doc.Days, formatDay(day))
+	}
+
+	for _, off := range ts.DaysOff {
+		if off != nil {
+			doc.TimeOff = append(doc.TimeOff, timesheetOff{Date: formatDay(off.Day), Type: off.Kind, Name: off.Name})
+		}
+	}
+
+	for _, row := range ts.Rows {
+		doc.Rows = append(doc.Rows, timesheetRow{Label: row.Label, Hours: hours(row.Days), TotalHours: roundHours(row.Total)})
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+
+	return nil
+}
+
+func writeTimesheetCSV(w io.Writer, ts *report.Timesheet) error {
+	writer := csv.NewWriter(w)
+
+	hours := func(d time.Duration) string {
+		return fmt.Sprintf("%.2f", d.Hours())
+	}
+
+	header := []string{strings.ToUpper(ts.By[:1]) + ts.By[1:]}
+	for _, day := range ts.Days {
+		header = append(header, formatDay(day))
+	}
+	records := [][]string{append(header, "Total")}
+
+	for _, row := range ts.Rows {
+		record := []string{row.Label}
+		for _, d := range row.Days {
+			record = append(record, hours(d))
+		}
+		records = append(records, append(record, hours(row.Total)))
+	}
+
+	totals := []string{"Total"}
+	for _, d := range ts.DayTotals {
+		totals = append(totals, hours(d))
+	}
+	records = append(records, append(totals, hours(ts.Total)))
+
+	off := []string{"Time Off"}
+	anyOff := false
+	for _, day := range ts.DaysOff {
+		kind := ""
+		if day != nil {
+			kind, anyOff = day.Kind, true
+		}
+		off = append(off, kind)
+	}
+	if anyOff {
+		records = append(records, append(off, ""))
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/report"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTimesheet(t *testing.T) {
+	start := time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+
+	entries := []*storage.TimeEntry{
+		{ProjectName: "p", StartTime: start, EndTime: &end, Description: "api"},
+	}
+
+	week := period.Range{Start: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)}
+	ts, err := report.BuildTimesheet(entries, week, report.ByProject, nil, time.UTC, time.Monday, week.End)
+	require.NoError(t, err)
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteTimesheet(FormatCSV, &buf, ts))
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, []string{"Project", "2024-03-04", "2024-03-05", "2024-03-06", "2024-03-07", "2024-03-08", "2024-03-09", "2024-03-10", "Total"}, records[0])
+		assert.Equal(t, []string{"p", "0.00", "1.50", "0.00", "0.00", "0.00", "0.00", "0.00", "1.50"}, records[1])
+		assert.Equal(t, "Total", records[2][0])
+		assert.Equal(t, "1.50", records[2][8])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteTimesheet(FormatJSON, &buf, ts))
+
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "2024-03-04", doc["start"])
+		assert.Equal(t, "2024-03-10", doc["end"])
+		assert.Equal(t, "project", doc["by"])
+		assert.Len(t, doc["days"], 7)
+		assert.Equal(t, 1.5, doc["total_hours"])
+
+		rows := doc["rows"].([]any)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "p", rows[0].(map[string]any)["label"])
+		assert.Equal(t, 1.5, rows[0].(map[string]any)["hours"].([]any)[1])
+	})
+
+	t.Run("days off", func(t *testing.T) {
+		daysOff := report.NewDaysOff([]*storage.TimeOff{
+			{Day: time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC), Kind: storage.TimeOffHoliday, Name: "Founders' Day"},
+		}, nil)
+		ts, err := report.BuildTimesheet(entries, week, report.ByProject, daysOff, time.UTC, time.Monday, week.End)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteTimesheet(FormatCSV, &buf, ts))
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		assert.Equal(t, []string{"Time Off", "", "", "", "", "holiday", "", "", ""}, records[3])
+
+		buf.Reset()
+		require.NoError(t, WriteTimesheet(FormatJSON, &buf, ts))
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, []any{map[string]any{"date": "2024-03-08", "type": "holiday", "name": "Founders' Day"}}, doc["time_off"])
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.Error(t, WriteTimesheet("xml", &bytes.Buffer{}, ts))
+	})
+}
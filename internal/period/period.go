@@ -0,0 +1,209 @@
+package period
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Range is the half-open span of time [Start, End). A zero Start or End
+// leaves that side open.
+type Range struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t falls inside the range.
+func (r Range) Contains(t time.Time) bool {
+	if !r.Start.IsZero() && t.Before(r.Start) {
+		return false
+	}
+	return r.End.IsZero() || t.Before(r.End)
+}
+
+// Day returns the range covering the calendar day of t.
+func Day(t time.Time) Range {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return Range{Start: start, End: start.AddDate(0, 0, 1)}
+}
+
+// Week returns the range covering the Monday-to-Sunday week of t.
+func Week(t time.Time) Range {
+	return WeekStarting(t, time.Monday)
+}
+
+// WeekStarting returns the range covering the week of t, for weeks that
+// start on the given day.
+func WeekStarting(t time.Time, first time.Weekday) Range {
+	offset := (int(t.Weekday()) - int(first) + 7) % 7
+
+	start := Day(t).Start.AddDate(0, 0, -offset)
+	return Range{Start: start, End: start.AddDate(0, 0, 7)}
+}
+
+// Month returns the range covering the calendar month of t.
+func Month(t time.Time) Range {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return Range{Start: start, End: start.AddDate(0, 1, 0)}
+}
+
+// Year returns the range covering the calendar year of t.
+func Year(t time.Time) Range {
+	start := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	return Range{Start: start, End: start.AddDate(1, 0, 0)}
+}
+
+// Previous returns the range of the same length just before r: the previous
+// month for a month, the previous year for a year, and otherwise the same
+// number of days. It reports false for a range that is open on either side.
+func (r Range) Previous() (Range, bool) {
+	if r.Start.IsZero() || r.End.IsZero() {
+		return Range{}, false
+	}
+
+	if r.Start.Day() == 1 && r.End.Day() == 1 && isMidnight(r.Start) && isMidnight(r.End) {
+		months := (r.End.Year()-r.Start.Year())*12 + int(r.End.Month()-r.Start.Month())
+		return Range{Start: r.Start.AddDate(0, -months, 0), End: r.Start}, true
+	}
+
+	if isMidnight(r.Start) && isMidnight(r.End) {
+		days := int(math.Round(r.End.Sub(r.Start).Hours() / 24))
+		return Range{Start: r.Start.AddDate(0, 0, -days), End: r.Start}, true
+	}
+
+	return Range{Start: r.Start.Add(-r.End.Sub(r.Start)), End: r.Start}, true
+}
+
+// PreviousSoFar is Previous, cut to as much of the previous range as has
+// passed of r at now when r is still under way, so a partial period is
+// compared with the same stretch of the one before.
+func (r Range) PreviousSoFar(now time.Time) (Range, bool) {
+	previous, ok := r.Previous()
+	if !ok || !r.Contains(now) {
+		return previous, ok
+	}
+
+	if end := previous.Start.Add(now.Sub(r.Start)); end.Before(previous.End) {
+		previous.End = end
+	}
+
+	return previous, true
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// Parse parses a period relative to now. It accepts today, yesterday,
+// this-week, last-week, this-month, last-month, this-year, last-year, a
+// date (YYYY-MM-DD), an ISO week (YYYY-Www), a month (YYYY-MM) or a year
+// (YYYY). Two periods joined
+// by ".." cover everything from the start of the first to the end of the
+// second, and either side may be left empty to leave it open. Weeks start on
+// Monday.
+func Parse(spec string, now time.Time) (Range, error) {
+	return ParseWithWeekStart(spec, now, time.Monday)
+}
+
+// ParseWithWeekStart is Parse for weeks that start on the given day.
+func ParseWithWeekStart(spec string, now time.Time, first time.Weekday) (Range, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return Range{}, fmt.Errorf("period cannot be empty")
+	}
+
+	if from, to, found := strings.Cut(spec, ".."); found {
+		var r Range
+
+		if strings.TrimSpace(from) != "" {
+			start, err := parseSingle(from, now, first)
+			if err != nil {
+				return Range{}, err
+			}
+			r.Start = start.Start
+		}
+
+		if strings.TrimSpace(to) != "" {
+			last, err := parseSingle(to, now, first)
+			if err != nil {
+				return Range{}, err
+			}
+			r.End = last.End
+		}
+
+		if r.Start.IsZero() && r.End.IsZero() {
+			return Range{}, fmt.Errorf("period '%s' needs a start or an end", spec)
+		}
+
+		if !r.Start.IsZero() && !r.End.IsZero() && !r.End.After(r.Start) {
+			return Range{}, fmt.Errorf("period '%s' ends before it starts", spec)
+		}
+
+		return r, nil
+	}
+
+	return parseSingle(spec, now, first)
+}
+
+func parseSingle(spec string, now time.Time, first time.Weekday) (Range, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+
+	switch spec {
+	case "today":
+		return Day(now), nil
+	case "yesterday":
+		return Day(now.AddDate(0, 0, -1)), nil
+	case "this-week", "week":
+		return WeekStarting(now, first), nil
+	case "last-week":
+		return WeekStarting(now.AddDate(0, 0, -7), first), nil
+	case "this-month", "month":
+		return Month(now), nil
+	case "last-month":
+		return Month(Month(now).Start.AddDate(0, -1, 0)), nil
+	case "this-year", "year":
+		return Year(now), nil
+	case "last-year":
+		return Year(Year(now).Start.AddDate(-1, 0, 0)), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", spec, now.Location()); err == nil {
+		return Day(t), nil
+	}
+
+	if r, ok := parseISOWeek(spec, now.Location(), first); ok {
+		return r, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01", spec, now.Location()); err == nil {
+		return Month(t), nil
+	}
+
+	if t, err := time.ParseInLocation("2006", spec, now.Location()); err == nil {
+		return Year(t), nil
+	}
+
+	return Range{}, fmt.Errorf("invalid period '%s', use a date (YYYY-MM-DD), week (YYYY-Www), month (YYYY-MM), year (YYYY), a name such as this-week or last-month, or a range like 2024-01..2024-03", spec)
+}
+
+// parseISOWeek parses an ISO 8601 week such as 2024-w11. ISO weeks start on
+// Monday, so for weeks starting on another day it returns the week that
+// holds the Monday.
+func parseISOWeek(spec string, loc *time.Location, first time.Weekday) (Range, bool) {
+	var year, week int
+	var rest string
+	if n, _ := fmt.Sscanf(spec, "%4d-w%2d%s", &year, &week, &rest); n != 2 || len(spec) != len("2006-w01") {
+		return Range{}, false
+	}
+
+	// week 1 is the week with the year's first Thursday, so it holds 4 January
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, loc)
+	monday := Week(jan4).Start.AddDate(0, 0, 7*(week-1))
+
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return Range{}, false
+	}
+
+	return WeekStarting(monday, first), true
+}
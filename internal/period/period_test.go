@@ -0,0 +1,150 @@
+package period
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+func TestParse(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2024, 3, 13, 15, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    Range
+		wantErr bool
+	}{
+		{name: "today", spec: "today", want: Range{date(2024, 3, 13), date(2024, 3, 14)}},
+		{name: "yesterday", spec: "yesterday", want: Range{date(2024, 3, 12), date(2024, 3, 13)}},
+		{name: "this week starts on Monday", spec: "this-week", want: Range{date(2024, 3, 11), date(2024, 3, 18)}},
+		{name: "last week", spec: "last-week", want: Range{date(2024, 3, 4), date(2024, 3, 11)}},
+		{name: "this month", spec: "this-month", want: Range{date(2024, 3, 1), date(2024, 4, 1)}},
+		{name: "last month", spec: "last-month", want: Range{date(2024, 2, 1), date(2024, 3, 1)}},
+		{name: "last year", spec: "Last-Year", want: Range{date(2023, 1, 1), date(2024, 1, 1)}},
+		{name: "date", spec: "2024-02-29", want: Range{date(2024, 2, 29), date(2024, 3, 1)}},
+		{name: "ISO week", spec: "2024-W11", want: Range{date(2024, 3, 11), date(2024, 3, 18)}},
+		{name: "ISO week 1 starts in the previous year", spec: "2025-w01", want: Range{date(2024, 12, 30), date(2025, 1, 6)}},
+		{name: "ISO week 53", spec: "2020-W53", want: Range{date(2020, 12, 28), date(2021, 1, 4)}},
+		{name: "ISO week that doesn't exist", spec: "2024-W53", wantErr: true},
+		{name: "month", spec: "2023-12", want: Range{date(2023, 12, 1), date(2024, 1, 1)}},
+		{name: "year", spec: "2022", want: Range{date(2022, 1, 1), date(2023, 1, 1)}},
+		{name: "span of months", spec: "2024-01..2024-02", want: Range{date(2024, 1, 1), date(2024, 3, 1)}},
+		{name: "span of dates includes the last day", spec: "2024-03-01..2024-03-05", want: Range{date(2024, 3, 1), date(2024, 3, 6)}},
+		{name: "open end", spec: "2024-03..", want: Range{Start: date(2024, 3, 1)}},
+		{name: "open start", spec: "..last-month", want: Range{End: date(2024, 3, 1)}},
+		{name: "span ending before it starts", spec: "2024-03..2024-01", wantErr: true},
+		{name: "both sides open", spec: "..", wantErr: true},
+		{name: "unknown", spec: "fortnight", wantErr: true},
+		{name: "empty", spec: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.spec, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Start.Equal(got.Start), "start: got %v, want %v", got.Start, tt.want.Start)
+			assert.True(t, tt.want.End.Equal(got.End), "end: got %v, want %v", got.End, tt.want.End)
+		})
+	}
+}
+
+func TestWeekOnSunday(t *testing.T) {
+	sunday := time.Date(2024, 3, 17, 22, 0, 0, 0, time.Local)
+	assert.Equal(t, Range{date(2024, 3, 11), date(2024, 3, 18)}, Week(sunday))
+}
+
+func TestWeekStarting(t *testing.T) {
+	wednesday := time.Date(2024, 3, 13, 15, 30, 0, 0, time.Local)
+	assert.Equal(t, Range{date(2024, 3, 10), date(2024, 3, 17)}, WeekStarting(wednesday, time.Sunday))
+	assert.Equal(t, Range{date(2024, 3, 9), date(2024, 3, 16)}, WeekStarting(wednesday, time.Saturday))
+	assert.Equal(t, Range{date(2024, 3, 13), date(2024, 3, 20)}, WeekStarting(wednesday, time.Wednesday))
+
+	got, err := ParseWithWeekStart("last-week", wednesday, time.Sunday)
+	assert.NoError(t, err)
+	assert.Equal(t, Range{date(2024, 3, 3), date(2024, 3, 10)}, got)
+
+	got, err = ParseWithWeekStart("2024-W11", wednesday, time.Sunday)
+	assert.NoError(t, err)
+	assert.Equal(t, Range{date(2024, 3, 10), date(2024, 3, 17)}, got, "the week holding the ISO week's Monday")
+}
+
+func TestRangePrevious(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Range
+		want Range
+		open bool
+	}{
+		{name: "day", r: Day(date(2024, 3, 1)), want: Range{date(2024, 2, 29), date(2024, 3, 1)}},
+		{name: "week", r: Week(date(2024, 3, 13)), want: Range{date(2024, 3, 4), date(2024, 3, 11)}},
+		{name: "month of a different length", r: Month(date(2024, 3, 13)), want: Range{date(2024, 2, 1), date(2024, 3, 1)}},
+		{name: "year", r: Year(date(2024, 3, 13)), want: Range{date(2023, 1, 1), date(2024, 1, 1)}},
+		{name: "span of months", r: Range{date(2024, 1, 1), date(2024, 4, 1)}, want: Range{date(2023, 10, 1), date(2024, 1, 1)}},
+		{name: "span of days", r: Range{date(2024, 3, 1), date(2024, 3, 6)}, want: Range{date(2024, 2, 25), date(2024, 3, 1)}},
+		{name: "open", r: Range{Start: date(2024, 3, 1)}, open: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.r.Previous()
+			if tt.open {
+				assert.False(t, ok)
+				return
+			}
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRangePreviousSoFar(t *testing.T) {
+	noon := func(year int, month time.Month, day int) time.Time {
+		return date(year, month, day).Add(12 * time.Hour)
+	}
+
+	tests := []struct {
+		name string
+		r    Range
+		now  time.Time
+		want Range
+	}{
+		{name: "month under way", r: Month(date(2024, 3, 15)), now: noon(2024, 3, 15), want: Range{date(2024, 2, 1), noon(2024, 2, 15)}},
+		{name: "year under way", r: Year(date(2024, 3, 15)), now: noon(2024, 3, 15), want: Range{date(2023, 1, 1), date(2023, 1, 1).Add(noon(2024, 3, 15).Sub(date(2024, 1, 1)))}},
+		{name: "longer than the previous month", r: Month(date(2024, 3, 31)), now: noon(2024, 3, 31), want: Range{date(2024, 2, 1), date(2024, 3, 1)}},
+		{name: "period over", r: Month(date(2024, 2, 15)), now: noon(2024, 3, 15), want: Range{date(2024, 1, 1), date(2024, 2, 1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.r.PreviousSoFar(tt.now)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, ok := Range{Start: date(2024, 3, 1)}.PreviousSoFar(noon(2024, 3, 15))
+	assert.False(t, ok)
+}
+
+func TestRangeContains(t *testing.T) {
+	r := Range{Start: date(2024, 3, 1), End: date(2024, 3, 2)}
+	assert.True(t, r.Contains(date(2024, 3, 1)))
+	assert.False(t, r.Contains(date(2024, 3, 2)))
+	assert.False(t, r.Contains(date(2024, 2, 29)))
+
+	open := Range{Start: date(2024, 3, 1)}
+	assert.True(t, open.Contains(date(2030, 1, 1)))
+}
@@ -0,0 +1,204 @@
+package report
+
+import (
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+)
+
+// Periods goals are set for.
+const (
+	GoalDaily  = "daily"
+	GoalWeekly = "weekly"
+)
+
+// GoalProgress is the time tracked towards a goal on one day or in one week.
+type GoalProgress struct {
+	// Project is the project the goal is for, empty for a goal across all
+	// projects.
+	Project string
+	// Period is GoalDaily or GoalWeekly.
+	Period  string
+	Span    period.Range
+	Target  time.Duration
+	Tracked time.Duration
+	// DaysOff is how many working days off lowered a weekly target.
+	DaysOff int
+}
+
+// Fraction returns how much of the goal has been tracked, 1.0 when it is met.
+func (p GoalProgress) Fraction() float64 {
+	return p.Tracked.Hours() / p.Target.Hours()
+}
+
+// Met reports whether the goal has been met.
+func (p GoalProgress) Met() bool {
+	return p.Tracked >= p.Target
+}
+
+// TrackGoals measures the progress towards the daily and weekly goals on the
+// day and in the week of now, for the goals that are set. The global goals
+// count every entry and the project goals only the entries of project.
+// Entries count towards the day they start on, and a running entry counts up
+// to now, so entries must cover at least the week of now. There are no daily
+// goals on a day off, and each of the workingDays off lowers the weekly goals
+// by its share of the week.
+func TrackGoals(entries []*storage.TimeEntry, global, projectGoals settings.Goals, project string, daysOff DaysOff, workingDays map[time.Weekday]bool, firstWeekday time.Weekday, now time.Time) []GoalProgress {
+	day := period.Day(now)
+	week := period.WeekStarting(now, firstWeekday)
+	dayOff := daysOff.On(now) != nil
+
+	var progress []GoalProgress
+	add := func(project, goalPeriod string, span period.Range, target time.Duration) {
+		if goalPeriod == GoalDaily && dayOff {
+			return
+		}
+
+		off := 0
+		if goalPeriod == GoalWeekly {
+			target, off = weekGoal(target, span, daysOff, workingDays)
+		}
+		if target <= 0 {
+			return
+		}
+
+		var tracked time.Duration
+		for _, entry := range entries {
+			if (project == "" || entry.ProjectName == project) && span.Contains(entry.StartTime) {
+				tracked += entryDuration(entry, now)
+			}
+		}
+
+		progress = append(progress, GoalProgress{Project: project, Period: goalPeriod, Span: span, Target: target, Tracked: tracked, DaysOff: off})
+	}
+
+	add("", GoalDaily, day, global.DailyTarget())
+	add("", GoalWeekly, week, global.WeeklyTarget())
+	if project != "" {
+		add(project, GoalDaily, day, projectGoals.DailyTarget())
+		add(project, GoalWeekly, week, projectGoals.WeeklyTarget())
+	}
+
+	return progress
+}
+
+// Streak is how many days or weeks in a row a goal was met.
+type Streak struct {
+	// Current is the run still going on now. Today, or this week, only adds
+	// to it once the goal is met, but doesn't end it before then.
+	Current int
+	Longest int
+}
+
+// DailyStreak counts the days in a row that the entries of project, or of
+// every project when it is empty, add up to target. Days outside workingDays
+// and daysOff without the goal met neither add to a streak nor end it.
+func DailyStreak(entries []*storage.TimeEntry, project string, target time.Duration, daysOff DaysOff, workingDays map[time.Weekday]bool, loc *time.Location, now time.Time) Streak {
+	if target <= 0 {
+		return Streak{}
+	}
+
+	totals := totalsBy(entries, project, now, func(t time.Time) time.Time {
+		return period.Day(t.In(loc)).Start
+	})
+
+	next := func(day time.Time) time.Time { return day.AddDate(0, 0, 1) }
+	dayOff := func(day time.Time) bool {
+		return !workingDays[day.Weekday()] || daysOff.On(day) != nil
+	}
+	goal := func(time.Time) time.Duration { return target }
+
+	return countStreak(totals, goal, period.Day(now.In(loc)).Start, next, dayOff)
+}
+
+// WeeklyStreak counts the weeks in a row, starting on firstWeekday, that the
+// entries of project, or of every project when it is empty, add up to target,
+// lowered for the working days off in each week. Weeks off entirely neither
+// add to a streak nor end it.
+func WeeklyStreak(entries []*storage.TimeEntry, project string, target time.Duration, daysOff DaysOff, workingDays map[time.Weekday]bool, loc *time.Location, firstWeekday time.Weekday, now time.Time) Streak {
+	if target <= 0 {
+		return Streak{}
+	}
+
+	totals := totalsBy(entries, project, now, func(t time.Time) time.Time {
+		return period.WeekStarting(t.In(loc), firstWeekday).Start
+	})
+
+	next := func(week time.Time) time.Time { return week.AddDate(0, 0, 7) }
+	goal := func(week time.Time) time.Duration {
+		lowered, _ := weekGoal(target, period.WeekStarting(week, firstWeekday), daysOff, workingDays)
+		return lowered
+	}
+	weekOff := func(week time.Time) bool { return goal(week) <= 0 }
+
+	return countStreak(totals, goal, period.WeekStarting(now.In(loc), firstWeekday).Start, next, weekOff)
+}
+
+// totalsBy totals the time of the entries of project by the start of the
+// day or week they start in.
+func totalsBy(entries []*storage.TimeEntry, project string, now time.Time, key func(time.Time) time.Time) map[time.Time]time.Duration {
+	totals := make(map[time.Time]time.Duration)
+	for _, entry := range entries {
+		if project == "" || entry.ProjectName == project {
+			totals[key(entry.StartTime)] += entryDuration(entry, now)
+		}
+	}
+	return totals
+}
+
+// countStreak walks from the first period with tracked time up to current.
+func countStreak(totals map[time.Time]time.Duration, target func(time.Time) time.Duration, current time.Time, next func(time.Time) time.Time, dayOff func(time.Time) bool) Streak {
+	var s Streak
+	if len(totals) == 0 {
+		return s
+	}
+
+	var first time.Time
+	for start := range totals {
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+	}
+
+	for start := first; !start.After(current); start = next(start) {
+		switch {
+		case target(start) > 0 && totals[start] >= target(start):
+			s.Current++
+			s.Longest = max(s.Longest, s.Current)
+		case start.Equal(current), dayOff(start):
+			// still in progress, or a day off
+		default:
+			s.Current = 0
+		}
+	}
+
+	return s
+}
+
+// weekGoal lowers a weekly target by 1/len(workingDays) for each working day
+// off in week, returning the lowered target and the number of working days
+// off.
+func weekGoal(target time.Duration, week period.Range, daysOff DaysOff, workingDays map[time.Weekday]bool) (time.Duration, int) {
+	if len(workingDays) == 0 {
+		return target, 0
+	}
+
+	off := 0
+	for day := week.Start; day.Before(week.End); day = day.AddDate(0, 0, 1) {
+		if workingDays[day.Weekday()] && daysOff.On(day) != nil {
+			off++
+		}
+	}
+
+	days := time.Duration(len(workingDays))
+	return max(target*(days-time.Duration(off))/days, 0), off
+}
+
+func entryDuration(entry *storage.TimeEntry, now time.Time) time.Duration {
+	if entry.EndTime == nil {
+		return now.Sub(entry.StartTime)
+	}
+	return entry.EndTime.Sub(entry.StartTime)
+}
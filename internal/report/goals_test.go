@@ -0,0 +1,140 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackGoals(t *testing.T) {
+	weekdays, err := settings.WorkingHours{}.WorkingDays()
+	require.NoError(t, err)
+
+	// Wednesday afternoon
+	now := date(2024, 3, 13).Add(15 * time.Hour)
+
+	web := entry(date(2024, 3, 13).Add(9*time.Hour), 2*time.Hour, "", nil)
+	web.ProjectName = "web"
+	running := &storage.TimeEntry{ProjectName: "p", StartTime: now.Add(-time.Hour)}
+
+	entries := []*storage.TimeEntry{
+		entry(date(2024, 3, 11).Add(9*time.Hour), 4*time.Hour, "", nil),
+		web,
+		running,
+	}
+
+	global := settings.Goals{Daily: 8, Weekly: 40}
+	project := settings.Goals{Weekly: 10}
+
+	progress := TrackGoals(entries, global, project, "p", nil, weekdays, time.Monday, now)
+	require.Len(t, progress, 3, "the project has no daily goal")
+
+	assert.Equal(t, GoalDaily, progress[0].Period)
+	assert.Empty(t, progress[0].Project)
+	assert.Equal(t, 3*time.Hour, progress[0].Tracked, "today's entries, the running one up to now")
+	assert.InDelta(t, 0.375, progress[0].Fraction(), 0.001)
+	assert.False(t, progress[0].Met())
+
+	assert.Equal(t, GoalWeekly, progress[1].Period)
+	assert.Equal(t, 7*time.Hour, progress[1].Tracked)
+
+	assert.Equal(t, "p", progress[2].Project)
+	assert.Equal(t, 5*time.Hour, progress[2].Tracked, "only the project's entries")
+
+	assert.Empty(t, TrackGoals(entries, settings.Goals{}, settings.Goals{}, "p", nil, weekdays, time.Monday, now))
+
+	t.Run("day off", func(t *testing.T) {
+		daysOff := NewDaysOff([]*storage.TimeOff{{Day: date(2024, 3, 13), Kind: storage.TimeOffVacation}}, nil)
+
+		progress := TrackGoals(entries, global, settings.Goals{}, "", daysOff, weekdays, time.Monday, now)
+		require.Len(t, progress, 1, "no daily goal on a day off")
+		assert.Equal(t, GoalWeekly, progress[0].Period)
+		assert.Equal(t, 32*time.Hour, progress[0].Target)
+		assert.Equal(t, 1, progress[0].DaysOff)
+	})
+
+	t.Run("day off in a four-day week", func(t *testing.T) {
+		fourDays, err := settings.WorkingHours{Days: []string{"Mon", "Tue", "Wed", "Thu"}}.WorkingDays()
+		require.NoError(t, err)
+
+		daysOff := NewDaysOff([]*storage.TimeOff{
+			{Day: date(2024, 3, 13), Kind: storage.TimeOffVacation},
+			{Day: date(2024, 3, 15), Kind: storage.TimeOffVacation},
+		}, nil)
+
+		progress := TrackGoals(entries, settings.Goals{Weekly: 40}, settings.Goals{}, "", daysOff, fourDays, time.Monday, now)
+		require.Len(t, progress, 1)
+		assert.Equal(t, 30*time.Hour, progress[0].Target, "a quarter off for Wednesday, nothing for Friday")
+		assert.Equal(t, 1, progress[0].DaysOff)
+	})
+}
+
+func TestStreaks(t *testing.T) {
+	weekdays, err := settings.WorkingHours{}.WorkingDays()
+	require.NoError(t, err)
+
+	day := func(d int, hours time.Duration) *storage.TimeEntry {
+		return entry(date(2024, 3, d).Add(9*time.Hour), hours*time.Hour, "", nil)
+	}
+
+	entries := []*storage.TimeEntry{
+		// Monday to Wednesday, then a miss on Thursday
+		day(4, 8), day(5, 8), day(6, 8), day(7, 2),
+		// Friday, the weekend off, then Monday and Tuesday
+		day(8, 8), day(11, 8), day(12, 8),
+	}
+
+	t.Run("daily", func(t *testing.T) {
+		// Wednesday, with nothing tracked yet
+		now := date(2024, 3, 13).Add(10 * time.Hour)
+		s := DailyStreak(entries, "", 8*time.Hour, nil, weekdays, time.Local, now)
+		assert.Equal(t, 3, s.Current, "the weekend and today don't end the streak")
+		assert.Equal(t, 3, s.Longest)
+
+		// Thursday, so Wednesday was missed
+		s = DailyStreak(entries, "", 8*time.Hour, nil, weekdays, time.Local, now.AddDate(0, 0, 1))
+		assert.Equal(t, 0, s.Current)
+		assert.Equal(t, 3, s.Longest)
+
+		s = DailyStreak(entries, "other", 8*time.Hour, nil, weekdays, time.Local, now)
+		assert.Equal(t, Streak{}, s, "nothing tracked for the project")
+
+		sick := NewDaysOff([]*storage.TimeOff{{Day: date(2024, 3, 7), Kind: storage.TimeOffSick}}, nil)
+		s = DailyStreak(entries, "", 8*time.Hour, sick, weekdays, time.Local, now)
+		assert.Equal(t, 6, s.Current, "a day off doesn't end the streak")
+
+		// Monday to Thursday, nothing on Friday, then Monday and Tuesday
+		fourDayWeeks := []*storage.TimeEntry{day(4, 8), day(5, 8), day(6, 8), day(7, 8), day(11, 8), day(12, 8)}
+		s = DailyStreak(fourDayWeeks, "", 8*time.Hour, nil, weekdays, time.Local, now)
+		assert.Equal(t, 2, s.Current, "Friday was missed")
+
+		fourDays, err := settings.WorkingHours{Days: []string{"Mon", "Tue", "Wed", "Thu"}}.WorkingDays()
+		require.NoError(t, err)
+		s = DailyStreak(fourDayWeeks, "", 8*time.Hour, nil, fourDays, time.Local, now)
+		assert.Equal(t, 6, s.Current, "Friday isn't a working day")
+	})
+
+	t.Run("weekly", func(t *testing.T) {
+		now := date(2024, 3, 13).Add(10 * time.Hour)
+
+		// 34 hours the first week and 16 so far in the second
+		s := WeeklyStreak(entries, "", 30*time.Hour, nil, weekdays, time.Local, time.Monday, now)
+		assert.Equal(t, 1, s.Current, "this week isn't over yet")
+		assert.Equal(t, 1, s.Longest)
+
+		s = WeeklyStreak(entries, "", 16*time.Hour, nil, weekdays, time.Local, time.Monday, now)
+		assert.Equal(t, 2, s.Current, "this week counts once its goal is met")
+
+		// 34 hours is short of 40, but enough for a week with a day off
+		s = WeeklyStreak(entries, "", 40*time.Hour, nil, weekdays, time.Local, time.Monday, now)
+		assert.Equal(t, 0, s.Longest)
+
+		sick := NewDaysOff([]*storage.TimeOff{{Day: date(2024, 3, 7), Kind: storage.TimeOffSick}}, nil)
+		s = WeeklyStreak(entries, "", 40*time.Hour, sick, weekdays, time.Local, time.Monday, now)
+		assert.Equal(t, 1, s.Longest)
+	})
+}
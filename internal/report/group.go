@@ -0,0 +1,179 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+)
+
+// Dimensions entries can be grouped by.
+const (
+	ByDay         = "day"
+	ByWeekday     = "weekday"
+	ByHour        = "hour"
+	ByProject     = "project"
+	ByMilestone   = "milestone"
+	ByDescription = "description"
+)
+
+// Dimensions lists the dimensions GroupBy accepts, in the order they are
+// usually shown.
+var Dimensions = []string{ByDay, ByWeekday, ByHour, ByProject, ByMilestone, ByDescription}
+
+// Group is the time tracked for one value of a dimension, such as Mondays
+// or one description.
+type Group struct {
+	// Key identifies the group. Unlike Label, it doesn't depend on the other
+	// entries grouped, so groups of different calls to GroupBy can be matched.
+	Key   string
+	Label string
+	// None is set for the group of entries without a milestone or
+	// description.
+	None     bool
+	Duration time.Duration
+	Entries  int
+	Earnings float64
+
+	order int64
+}
+
+// GroupBy totals entries by one dimension. Entries count towards the day
+// they start on, except when grouping by hour, where an entry is split over
+// the hours of the day it covers. Days, weekdays and hours are in calendar
+// order, with weeks starting on firstWeekday, and the other dimensions are
+// largest first. A running entry counts up to now.
+func GroupBy(entries []*storage.TimeEntry, by string, loc *time.Location, firstWeekday time.Weekday, now time.Time) ([]*Group, error) {
+	if err := ValidateDimension(by); err != nil {
+		return nil, err
+	}
+
+	// labels are only formatted once per group, as formatting reads the config
+	groups := make(map[string]*Group)
+	get := func(key string, order int64, label func() string) *Group {
+		if groups[key] == nil {
+			groups[key] = &Group{Key: key, Label: label(), order: order}
+		}
+		return groups[key]
+	}
+	fixed := func(label string) func() string {
+		return func() string { return label }
+	}
+
+	projects := make(map[string]bool)
+	for _, entry := range entries {
+		projects[entry.ProjectName] = true
+	}
+
+	for _, entry := range entries {
+		end := now
+		if entry.EndTime != nil {
+			end = *entry.EndTime
+		}
+		start := entry.StartTime.In(loc)
+
+		var earnings float64
+		if entry.HourlyRate != nil {
+			earnings = entry.RoundedHours() * *entry.HourlyRate
+		}
+
+		switch by {
+		case ByDay:
+			day := period.Day(start).Start
+			label := func() string { return settings.FormatDate(day) + " " + day.Format("Mon") }
+			get(day.Format("2006-01-02"), day.Unix(), label).add(end.Sub(start), earnings)
+
+		case ByWeekday:
+			weekday := start.Weekday()
+			get(weekday.String(), int64((int(weekday)-int(firstWeekday)+7)%7), fixed(weekday.String())).add(end.Sub(start), earnings)
+
+		case ByHour:
+			// split the entry, and its earnings, over the hours it covers
+			total := end.Sub(start)
+			for from := start; from.Before(end); {
+				hour := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), 0, 0, 0, loc)
+
+				to := hour.Add(time.Hour)
+				if to.After(end) {
+					to = end
+				}
+
+				share := earnings * float64(to.Sub(from)) / float64(total)
+				label := func() string { return settings.FormatTime(hour) }
+				get(hour.Format("15"), int64(hour.Hour()), label).add(to.Sub(from), share)
+				from = to
+			}
+
+		case ByProject:
+			get(entry.ProjectName, 0, fixed(entry.ProjectName)).add(end.Sub(start), earnings)
+
+		case ByMilestone:
+			if entry.MilestoneName == nil {
+				group := get("", 0, fixed("(no milestone)"))
+				group.None = true
+				group.add(end.Sub(start), earnings)
+				continue
+			}
+
+			label := *entry.MilestoneName
+			if len(projects) > 1 {
+				label = fmt.Sprintf("%s (%s)", label, entry.ProjectName)
+			}
+			get(entry.ProjectName+"\x00"+*entry.MilestoneName, 0, fixed(label)).add(end.Sub(start), earnings)
+
+		case ByDescription:
+			description := strings.TrimSpace(entry.Description)
+			if description == "" {
+				group := get("", 0, fixed("(no description)"))
+				group.None = true
+				group.add(end.Sub(start), earnings)
+				continue
+			}
+			get(description, 0, fixed(description)).add(end.Sub(start), earnings)
+		}
+	}
+
+	result := make([]*Group, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group)
+	}
+
+	calendar := by == ByDay || by == ByWeekday || by == ByHour
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if calendar {
+			return a.order < b.order
+		}
+		if a.None != b.None {
+			return b.None
+		}
+		if a.Duration != b.Duration {
+			return a.Duration > b.Duration
+		}
+		return a.Label < b.Label
+	})
+
+	return result, nil
+}
+
+// ValidateDimension returns an error unless entries can be grouped by.
+func ValidateDimension(by string) error {
+	switch by {
+	case ByDay, ByWeekday, ByHour, ByProject, ByMilestone, ByDescription:
+		return nil
+	case "tag", "tags":
+		return fmt.Errorf("tmpo doesn't have tags, group by description or milestone instead")
+	default:
+		return fmt.Errorf("invalid grouping '%s', use %s", by, strings.Join(Dimensions, ", "))
+	}
+}
+
+func (g *Group) add(duration time.Duration, earnings float64) {
+	g.Duration += duration
+	g.Entries++
+	g.Earnings += earnings
+}
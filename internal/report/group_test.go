@@ -0,0 +1,88 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupBy(t *testing.T) {
+	rate := 100.0
+	now := date(2024, 3, 20)
+	sprint := "Sprint 1"
+
+	entries := []*storage.TimeEntry{
+		// Monday
+		entry(date(2024, 3, 4).Add(9*time.Hour+30*time.Minute), 2*time.Hour, "review", &rate),
+		entry(date(2024, 3, 4).Add(13*time.Hour), time.Hour, "api", nil),
+		// Sunday
+		entry(date(2024, 3, 10).Add(10*time.Hour), time.Hour, "", nil),
+		// Monday
+		entry(date(2024, 3, 11).Add(9*time.Hour), 30*time.Minute, " review ", &rate),
+	}
+	entries[1].MilestoneName = &sprint
+
+	t.Run("weekdays in calendar order", func(t *testing.T) {
+		groups, err := GroupBy(entries, ByWeekday, time.Local, time.Monday, now)
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "Monday", groups[0].Label)
+		assert.Equal(t, 3*time.Hour+30*time.Minute, groups[0].Duration)
+		assert.Equal(t, 3, groups[0].Entries)
+		assert.InDelta(t, 250.0, groups[0].Earnings, 0.001)
+		assert.Equal(t, "Sunday", groups[1].Label)
+
+		groups, err = GroupBy(entries, ByWeekday, time.Local, time.Sunday, now)
+		require.NoError(t, err)
+		assert.Equal(t, "Sunday", groups[0].Label, "weeks can start on Sunday")
+	})
+
+	t.Run("hours split entries", func(t *testing.T) {
+		groups, err := GroupBy(entries, ByHour, time.Local, time.Monday, now)
+		require.NoError(t, err)
+
+		// 9:00 (30m + 30m), 10:00 (60m + 60m), 11:00 (30m), 13:00 (60m)
+		require.Len(t, groups, 4)
+		assert.Equal(t, time.Hour, groups[0].Duration)
+		assert.Equal(t, 2, groups[0].Entries)
+		assert.Equal(t, 2*time.Hour, groups[1].Duration)
+		assert.Equal(t, 30*time.Minute, groups[2].Duration)
+		assert.Equal(t, time.Hour, groups[3].Duration)
+
+		// the 2 hour review earns 200, half of it from 9:30 to 10:00 and 10:30 to 11:00
+		assert.InDelta(t, 100.0, groups[0].Earnings, 0.001)
+		assert.InDelta(t, 100.0, groups[1].Earnings, 0.001)
+		assert.InDelta(t, 50.0, groups[2].Earnings, 0.001)
+	})
+
+	t.Run("descriptions largest first", func(t *testing.T) {
+		groups, err := GroupBy(entries, ByDescription, time.Local, time.Monday, now)
+		require.NoError(t, err)
+		require.Len(t, groups, 3)
+		assert.Equal(t, "review", groups[0].Label)
+		assert.Equal(t, 2*time.Hour+30*time.Minute, groups[0].Duration)
+		assert.Equal(t, "api", groups[1].Label)
+		assert.Equal(t, "(no description)", groups[2].Label)
+		assert.True(t, groups[2].None)
+	})
+
+	t.Run("entries without a milestone come last", func(t *testing.T) {
+		groups, err := GroupBy(entries, ByMilestone, time.Local, time.Monday, now)
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "Sprint 1", groups[0].Label)
+		assert.True(t, groups[1].None)
+		assert.Equal(t, 3*time.Hour+30*time.Minute, groups[1].Duration)
+	})
+
+	t.Run("invalid dimensions", func(t *testing.T) {
+		_, err := GroupBy(nil, "tag", time.Local, time.Monday, now)
+		assert.ErrorContains(t, err, "doesn't have tags")
+
+		_, err = GroupBy(nil, "month", time.Local, time.Monday, now)
+		assert.ErrorContains(t, err, "invalid grouping")
+	})
+}
@@ -0,0 +1,135 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+)
+
+// Overtime measures the time tracked in a period against a working-hours
+// policy, week by week.
+type Overtime struct {
+	Span  period.Range
+	Weeks []*OvertimeWeek
+
+	Tracked  time.Duration
+	Expected time.Duration
+	// Overtime and Undertime add up the weeks over and under their expected
+	// hours, so they don't cancel out like the balance does.
+	Overtime  time.Duration
+	Undertime time.Duration
+	// Weekend is the time tracked on days that aren't working days.
+	Weekend time.Duration
+	// TimeOff is the time tracked on days off.
+	TimeOff time.Duration
+	// DaysOff lists the days off that fall on working days, in order.
+	DaysOff []*storage.TimeOff
+}
+
+// OvertimeWeek is the part of a week inside the period of an overtime
+// report.
+type OvertimeWeek struct {
+	// Start is the first day of the week inside the period.
+	Start    time.Time
+	Tracked  time.Duration
+	Expected time.Duration
+	Weekend  time.Duration
+	TimeOff  time.Duration
+	// Cumulative is the balance of this week and the weeks before it.
+	Cumulative time.Duration
+}
+
+// Balance returns the time tracked over (positive) or under (negative) the
+// expected hours.
+func (o *Overtime) Balance() time.Duration {
+	return o.Tracked - o.Expected
+}
+
+// Balance returns the time tracked over (positive) or under (negative) the
+// week's expected hours.
+func (w *OvertimeWeek) Balance() time.Duration {
+	return w.Tracked - w.Expected
+}
+
+// BuildOvertime measures entries against the working-hours policy. Every
+// working day that isn't a day off is expected to have the standard daily
+// hours, and a week no more than the standard weekly hours. Days after today
+// aren't expected yet and are left out, as is the rest of an open period.
+// Entries count towards the day they start on, in loc, and a running entry
+// counts up to now.
+func BuildOvertime(entries []*storage.TimeEntry, span period.Range, policy settings.WorkingHours, daysOff DaysOff, loc *time.Location, firstWeekday time.Weekday, now time.Time) (*Overtime, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	if span.Start.IsZero() {
+		return nil, fmt.Errorf("overtime needs a period with a start")
+	}
+
+	workingDays, err := policy.WorkingDays()
+	if err != nil {
+		return nil, err
+	}
+	dayTarget := policy.DayTarget(len(workingDays))
+
+	end := period.Day(now.In(loc)).End
+	if !span.End.IsZero() && span.End.Before(end) {
+		end = span.End
+	}
+
+	tracked := make(map[time.Time]time.Duration)
+	for _, entry := range entries {
+		tracked[period.Day(entry.StartTime.In(loc)).Start] += entryDuration(entry, now)
+	}
+
+	o := &Overtime{Span: span}
+
+	var week *OvertimeWeek
+	for day := period.Day(span.Start.In(loc)).Start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if week == nil || !day.Before(period.WeekStarting(week.Start, firstWeekday).End) {
+			week = &OvertimeWeek{Start: day}
+			o.Weeks = append(o.Weeks, week)
+		}
+
+		worked := tracked[day]
+		week.Tracked += worked
+
+		off := daysOff.On(day)
+		switch {
+		case off != nil:
+			week.TimeOff += worked
+			if workingDays[day.Weekday()] {
+				o.DaysOff = append(o.DaysOff, off)
+			}
+		case !workingDays[day.Weekday()]:
+			week.Weekend += worked
+		default:
+			week.Expected += dayTarget
+		}
+	}
+
+	var cumulative time.Duration
+	for _, week := range o.Weeks {
+		if limit := policy.WeekTarget(); limit > 0 && week.Expected > limit {
+			week.Expected = limit
+		}
+
+		cumulative += week.Balance()
+		week.Cumulative = cumulative
+
+		o.Tracked += week.Tracked
+		o.Expected += week.Expected
+		o.Weekend += week.Weekend
+		o.TimeOff += week.TimeOff
+		if balance := week.Balance(); balance > 0 {
+			o.Overtime += balance
+		} else {
+			o.Undertime -= balance
+		}
+	}
+
+	return o, nil
+}
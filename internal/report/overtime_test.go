@@ -0,0 +1,86 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildOvertime(t *testing.T) {
+	day := func(d int, hours time.Duration) *storage.TimeEntry {
+		return entry(date(2024, 3, d).Add(9*time.Hour), hours*time.Hour, "", nil)
+	}
+
+	entries := []*storage.TimeEntry{
+		// Monday 4 March to Friday, with a holiday on Friday
+		day(4, 10), day(5, 8), day(6, 8), day(7, 8), day(8, 1),
+		// Saturday
+		day(9, 3),
+		// Monday 11 March, then nothing until Wednesday
+		day(11, 6),
+	}
+
+	policy := settings.WorkingHours{Daily: 8, Weekly: 40}
+	daysOff := NewDaysOff(nil, map[string]string{"2024-03-08": "Founders' Day"})
+	span := period.Range{Start: date(2024, 3, 4), End: date(2024, 3, 18)}
+	// Wednesday 13 March
+	now := date(2024, 3, 13).Add(20 * time.Hour)
+
+	o, err := BuildOvertime(entries, span, policy, daysOff, time.Local, time.Monday, now)
+	require.NoError(t, err)
+	require.Len(t, o.Weeks, 2, "the rest of the second week hasn't happened yet")
+
+	first := o.Weeks[0]
+	assert.Equal(t, date(2024, 3, 4), first.Start)
+	assert.Equal(t, 38*time.Hour, first.Tracked)
+	assert.Equal(t, 32*time.Hour, first.Expected, "the holiday isn't expected to be worked")
+	assert.Equal(t, 3*time.Hour, first.Weekend)
+	assert.Equal(t, time.Hour, first.TimeOff)
+	assert.Equal(t, 6*time.Hour, first.Balance())
+
+	second := o.Weeks[1]
+	assert.Equal(t, 24*time.Hour, second.Expected, "Monday to Wednesday")
+	assert.Equal(t, -18*time.Hour, second.Balance())
+	assert.Equal(t, -12*time.Hour, second.Cumulative)
+
+	assert.Equal(t, 6*time.Hour, o.Overtime)
+	assert.Equal(t, 18*time.Hour, o.Undertime)
+	assert.Equal(t, -12*time.Hour, o.Balance())
+	require.Len(t, o.DaysOff, 1)
+	assert.True(t, o.DaysOff[0].Day.Equal(date(2024, 3, 8)))
+	assert.Equal(t, "Founders' Day", o.DaysOff[0].Name)
+
+	t.Run("weekly hours cap the week", func(t *testing.T) {
+		long := settings.WorkingHours{Daily: 9, Weekly: 40}
+		o, err := BuildOvertime(entries, period.Week(date(2024, 3, 4)), long, nil, time.Local, time.Monday, now)
+		require.NoError(t, err)
+		assert.Equal(t, 40*time.Hour, o.Expected)
+	})
+
+	t.Run("time off", func(t *testing.T) {
+		calendar := []*storage.TimeOff{
+			{Day: date(2024, 3, 11), Kind: storage.TimeOffVacation},
+			{Day: date(2024, 3, 8), Kind: storage.TimeOffSick},
+		}
+		o, err := BuildOvertime(entries, span, policy, NewDaysOff(calendar, map[string]string{"2024-03-08": "Founders' Day"}), time.Local, time.Monday, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, 16*time.Hour, o.Weeks[1].Expected, "vacation isn't expected to be worked")
+		assert.Equal(t, 6*time.Hour, o.Weeks[1].TimeOff)
+		require.Len(t, o.DaysOff, 2)
+		assert.Equal(t, storage.TimeOffSick, o.DaysOff[0].Kind, "the calendar wins over the holidays file")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := BuildOvertime(entries, span, settings.WorkingHours{}, nil, time.Local, time.Monday, now)
+		assert.ErrorContains(t, err, "working_hours")
+
+		_, err = BuildOvertime(entries, period.Range{End: span.End}, policy, nil, time.Local, time.Monday, now)
+		assert.Error(t, err)
+	})
+}
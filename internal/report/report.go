@@ -0,0 +1,156 @@
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+)
+
+// Report summarises the time entries of a period, such as a milestone.
+type Report struct {
+	Title string
+	// Span is the period the report covers; gaps are only looked for inside it.
+	Span period.Range
+
+	Entries int
+	Total   time.Duration
+	// Billable is the time of entries with an hourly rate.
+	Billable time.Duration
+	Earnings float64
+
+	// Days holds one total per day with tracked time, in order.
+	Days []*Total
+	// Descriptions holds one total per description, largest first.
+	Descriptions []*Total
+	// Gaps are runs of weekdays inside the span without any tracked time.
+	Gaps []Gap
+}
+
+// Total is the time tracked for one day or one description.
+type Total struct {
+	// Day is set for a day total.
+	Day time.Time
+	// Description is set for a description total; it is empty for entries
+	// without one.
+	Description string
+	Duration    time.Duration
+	Entries     int
+	Earnings    float64
+}
+
+// Gap is a run of weekdays without tracked time. Weekends inside the run
+// don't end it and aren't counted.
+type Gap struct {
+	Start time.Time
+	End   time.Time
+	Days  int
+}
+
+// Build summarises entries. Days are taken in loc, and a running entry counts
+// up to now.
+func Build(title string, span period.Range, entries []*storage.TimeEntry, loc *time.Location, now time.Time) *Report {
+	r := &Report{Title: title, Span: span, Entries: len(entries)}
+
+	days := make(map[time.Time]*Total)
+	descriptions := make(map[string]*Total)
+
+	for _, entry := range entries {
+		duration := entry.Duration()
+		if entry.IsRunning() {
+			duration = now.Sub(entry.StartTime)
+		}
+
+		var earnings float64
+		if entry.HourlyRate != nil {
+			earnings = entry.RoundedHours() * *entry.HourlyRate
+			r.Billable += duration
+			r.Earnings += earnings
+		}
+		r.Total += duration
+
+		day := period.Day(entry.StartTime.In(loc)).Start
+		if days[day] == nil {
+			days[day] = &Total{Day: day}
+		}
+		days[day].add(duration, earnings)
+
+		description := strings.TrimSpace(entry.Description)
+		if descriptions[description] == nil {
+			descriptions[description] = &Total{Description: description}
+		}
+		descriptions[description].add(duration, earnings)
+	}
+
+	for _, total := range days {
+		r.Days = append(r.Days, total)
+	}
+	sort.Slice(r.Days, func(i, j int) bool {
+		return r.Days[i].Day.Before(r.Days[j].Day)
+	})
+
+	for _, total := range descriptions {
+		r.Descriptions = append(r.Descriptions, total)
+	}
+	sort.Slice(r.Descriptions, func(i, j int) bool {
+		a, b := r.Descriptions[i], r.Descriptions[j]
+		if a.Duration != b.Duration {
+			return a.Duration > b.Duration
+		}
+		return a.Description < b.Description
+	})
+
+	r.Gaps = findGaps(span, days, loc, now)
+
+	return r
+}
+
+func (t *Total) add(duration time.Duration, earnings float64) {
+	t.Duration += duration
+	t.Entries++
+	t.Earnings += earnings
+}
+
+// findGaps returns the runs of weekdays in span, up to today, without any
+// tracked time.
+func findGaps(span period.Range, days map[time.Time]*Total, loc *time.Location, now time.Time) []Gap {
+	if span.Start.IsZero() {
+		return nil
+	}
+
+	end := span.End
+	if today := period.Day(now.In(loc)).Start; end.IsZero() || end.After(today) {
+		end = today
+	}
+
+	var gaps []Gap
+	var current *Gap
+
+	for day := period.Day(span.Start.In(loc)).Start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+
+		if days[day] != nil {
+			if current != nil {
+				gaps = append(gaps, *current)
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil {
+			current = &Gap{Start: day}
+		}
+		current.End = day
+		current.Days++
+	}
+
+	if current != nil {
+		gaps = append(gaps, *current)
+	}
+
+	return gaps
+}
@@ -0,0 +1,87 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+func entry(start time.Time, length time.Duration, description string, rate *float64) *storage.TimeEntry {
+	end := start.Add(length)
+	return &storage.TimeEntry{ProjectName: "p", StartTime: start, EndTime: &end, Description: description, HourlyRate: rate}
+}
+
+func TestBuild(t *testing.T) {
+	rate := 100.0
+	// Monday 4 March to Friday 15 March 2024
+	span := period.Range{Start: date(2024, 3, 4), End: date(2024, 3, 16)}
+	now := date(2024, 3, 20)
+
+	entries := []*storage.TimeEntry{
+		entry(date(2024, 3, 4).Add(9*time.Hour), 2*time.Hour, "api", &rate),
+		entry(date(2024, 3, 4).Add(13*time.Hour), time.Hour, "review", nil),
+		entry(date(2024, 3, 5).Add(9*time.Hour), 3*time.Hour, "api", &rate),
+		entry(date(2024, 3, 11).Add(9*time.Hour), time.Hour, "", nil),
+		entry(date(2024, 3, 15).Add(9*time.Hour), 30*time.Minute, "review", &rate),
+	}
+
+	r := Build("Sprint 1", span, entries, time.Local, now)
+
+	t.Run("totals", func(t *testing.T) {
+		assert.Equal(t, "Sprint 1", r.Title)
+		assert.Equal(t, 5, r.Entries)
+		assert.Equal(t, 7*time.Hour+30*time.Minute, r.Total)
+		assert.Equal(t, 5*time.Hour+30*time.Minute, r.Billable)
+		assert.InDelta(t, 550.0, r.Earnings, 0.001)
+	})
+
+	t.Run("days in order", func(t *testing.T) {
+		require.Len(t, r.Days, 4)
+		assert.True(t, r.Days[0].Day.Equal(date(2024, 3, 4)))
+		assert.Equal(t, 3*time.Hour, r.Days[0].Duration)
+		assert.Equal(t, 2, r.Days[0].Entries)
+		assert.True(t, r.Days[3].Day.Equal(date(2024, 3, 15)))
+	})
+
+	t.Run("descriptions by time", func(t *testing.T) {
+		require.Len(t, r.Descriptions, 3)
+		assert.Equal(t, "api", r.Descriptions[0].Description)
+		assert.Equal(t, 5*time.Hour, r.Descriptions[0].Duration)
+		assert.Equal(t, "review", r.Descriptions[1].Description)
+		assert.Equal(t, 2, r.Descriptions[1].Entries)
+		assert.Equal(t, "", r.Descriptions[2].Description)
+	})
+
+	t.Run("gaps skip weekends", func(t *testing.T) {
+		require.Len(t, r.Gaps, 2)
+		// Wednesday to Friday of the first week
+		assert.True(t, r.Gaps[0].Start.Equal(date(2024, 3, 6)))
+		assert.True(t, r.Gaps[0].End.Equal(date(2024, 3, 8)))
+		assert.Equal(t, 3, r.Gaps[0].Days)
+		// Tuesday to Thursday of the second week
+		assert.True(t, r.Gaps[1].Start.Equal(date(2024, 3, 12)))
+		assert.Equal(t, 3, r.Gaps[1].Days)
+	})
+
+	t.Run("gaps stop at today", func(t *testing.T) {
+		open := period.Range{Start: date(2024, 3, 4)}
+		r := Build("", open, entries[:1], time.Local, date(2024, 3, 6).Add(10*time.Hour))
+		require.Len(t, r.Gaps, 1)
+		assert.True(t, r.Gaps[0].Start.Equal(date(2024, 3, 5)))
+		assert.Equal(t, 1, r.Gaps[0].Days)
+	})
+
+	t.Run("weekend gap crossing", func(t *testing.T) {
+		r := Build("", period.Range{Start: date(2024, 3, 8), End: date(2024, 3, 12)}, nil, time.Local, now)
+		require.Len(t, r.Gaps, 1)
+		assert.Equal(t, 2, r.Gaps[0].Days)
+	})
+}
@@ -0,0 +1,36 @@
+package report
+
+import (
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/storage"
+)
+
+// DaysOff are the days off that goals, overtime and timesheets expect no
+// work on, by date (YYYY-MM-DD).
+type DaysOff map[string]*storage.TimeOff
+
+// NewDaysOff merges the time-off calendar with the holidays of the holidays
+// file, which are named by date. A day in the calendar wins over the file.
+func NewDaysOff(calendar []*storage.TimeOff, holidays map[string]string) DaysOff {
+	days := make(DaysOff, len(calendar)+len(holidays))
+
+	for date, name := range holidays {
+		day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			continue
+		}
+		days[date] = &storage.TimeOff{Day: day, Kind: storage.TimeOffHoliday, Name: name}
+	}
+
+	for _, day := range calendar {
+		days[day.Day.Format("2006-01-02")] = day
+	}
+
+	return days
+}
+
+// On returns the day off on the day of t, or nil when it is a normal day.
+func (d DaysOff) On(t time.Time) *storage.TimeOff {
+	return d[t.Format("2006-01-02")]
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+)
+
+// Timesheet is a grid of the time tracked in a period, with one row per
+// project, milestone or description and one column per day.
+type Timesheet struct {
+	Span period.Range
+	// By is the dimension of the rows.
+	By   string
+	Days []time.Time
+	// DaysOff holds the day off of each day, in the order of Days, nil for
+	// normal days.
+	DaysOff []*storage.TimeOff
+	Rows    []*TimesheetRow
+	// DayTotals holds the total of each day, in the order of Days.
+	DayTotals []time.Duration
+	Total     time.Duration
+}
+
+// TimesheetRow is the time of one row of a timesheet on each day.
+type TimesheetRow struct {
+	Label string
+	// None is set for the row of entries without a milestone or description.
+	None bool
+	// Days holds the time of each day, in the order of the timesheet's Days.
+	Days  []time.Duration
+	Total time.Duration
+}
+
+// BuildTimesheet totals entries by day and by project, milestone or
+// description, grouping them as GroupBy does, and marks the days off. The
+// span must be closed, and rows are ordered largest first.
+func BuildTimesheet(entries []*storage.TimeEntry, span period.Range, by string, daysOff DaysOff, loc *time.Location, firstWeekday time.Weekday, now time.Time) (*Timesheet, error) {
+	if err := ValidateDimension(by); err != nil {
+		return nil, err
+	}
+	if by != ByProject && by != ByMilestone && by != ByDescription {
+		return nil, fmt.Errorf("timesheet rows can be %s, %s or %s, not %s", ByProject, ByMilestone, ByDescription, by)
+	}
+	if span.Start.IsZero() || span.End.IsZero() {
+		return nil, fmt.Errorf("a timesheet needs a period with a start and an end")
+	}
+
+	ts := &Timesheet{Span: span, By: by}
+
+	column := make(map[time.Time]int)
+	for day := period.Day(span.Start.In(loc)).Start; day.Before(span.End); day = day.AddDate(0, 0, 1) {
+		column[day] = len(ts.Days)
+		ts.Days = append(ts.Days, day)
+		ts.DaysOff = append(ts.DaysOff, daysOff.On(day))
+	}
+	ts.DayTotals = make([]time.Duration, len(ts.Days))
+
+	// group each day on its own, so the cells add up the same way the rows do
+	daily := make(map[time.Time][]*storage.TimeEntry)
+	var inSpan []*storage.TimeEntry
+	for _, entry := range entries {
+		day := period.Day(entry.StartTime.In(loc)).Start
+		if _, ok := column[day]; ok {
+			daily[day] = append(daily[day], entry)
+			inSpan = append(inSpan, entry)
+		}
+	}
+
+	groups, err := GroupBy(inSpan, by, loc, firstWeekday, now)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[string]*TimesheetRow)
+	for _, group := range groups {
+		row := &TimesheetRow{Label: group.Label, None: group.None, Days: make([]time.Duration, len(ts.Days))}
+		rows[group.Key] = row
+		ts.Rows = append(ts.Rows, row)
+	}
+
+	for day, dayEntries := range daily {
+		i := column[day]
+		dayGroups, err := GroupBy(dayEntries, by, loc, firstWeekday, now)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, group := range dayGroups {
+			row := rows[group.Key]
+			row.Days[i] += group.Duration
+			row.Total += group.Duration
+			ts.DayTotals[i] += group.Duration
+			ts.Total += group.Duration
+		}
+	}
+
+	return ts, nil
+}
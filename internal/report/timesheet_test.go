@@ -0,0 +1,79 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/period"
+	"github.com/DylanDevelops/tmpo/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTimesheet(t *testing.T) {
+	// Monday 4 March to Sunday 10 March 2024
+	week := period.Week(date(2024, 3, 4))
+	now := date(2024, 3, 20)
+
+	api := entry(date(2024, 3, 4).Add(9*time.Hour), 2*time.Hour, "api", nil)
+	web := entry(date(2024, 3, 6).Add(9*time.Hour), time.Hour, "", nil)
+	web.ProjectName = "web"
+
+	entries := []*storage.TimeEntry{
+		api,
+		entry(date(2024, 3, 6).Add(13*time.Hour), 30*time.Minute, "api", nil),
+		web,
+		// the week before isn't part of the timesheet
+		entry(date(2024, 3, 1).Add(9*time.Hour), 4*time.Hour, "old", nil),
+	}
+
+	t.Run("by project", func(t *testing.T) {
+		ts, err := BuildTimesheet(entries, week, ByProject, nil, time.Local, time.Monday, now)
+		require.NoError(t, err)
+
+		require.Len(t, ts.Days, 7)
+		assert.Equal(t, date(2024, 3, 4), ts.Days[0])
+		assert.Equal(t, date(2024, 3, 10), ts.Days[6])
+
+		require.Len(t, ts.Rows, 2)
+		assert.Equal(t, "p", ts.Rows[0].Label)
+		assert.Equal(t, 2*time.Hour, ts.Rows[0].Days[0])
+		assert.Equal(t, 30*time.Minute, ts.Rows[0].Days[2])
+		assert.Equal(t, 2*time.Hour+30*time.Minute, ts.Rows[0].Total)
+		assert.Equal(t, "web", ts.Rows[1].Label)
+		assert.Equal(t, time.Hour, ts.Rows[1].Days[2])
+
+		assert.Equal(t, []time.Duration{2 * time.Hour, 0, 90 * time.Minute, 0, 0, 0, 0}, ts.DayTotals)
+		assert.Equal(t, 3*time.Hour+30*time.Minute, ts.Total)
+	})
+
+	t.Run("days off", func(t *testing.T) {
+		daysOff := NewDaysOff([]*storage.TimeOff{{Day: date(2024, 3, 8), Kind: storage.TimeOffVacation}}, nil)
+		ts, err := BuildTimesheet(entries, week, ByProject, daysOff, time.Local, time.Monday, now)
+		require.NoError(t, err)
+
+		require.Len(t, ts.DaysOff, 7)
+		assert.Nil(t, ts.DaysOff[0])
+		require.NotNil(t, ts.DaysOff[4])
+		assert.Equal(t, storage.TimeOffVacation, ts.DaysOff[4].Kind)
+	})
+
+	t.Run("by description", func(t *testing.T) {
+		ts, err := BuildTimesheet(entries, week, ByDescription, nil, time.Local, time.Monday, now)
+		require.NoError(t, err)
+		require.Len(t, ts.Rows, 2)
+		assert.Equal(t, "api", ts.Rows[0].Label)
+		assert.True(t, ts.Rows[1].None)
+	})
+
+	t.Run("invalid rows", func(t *testing.T) {
+		_, err := BuildTimesheet(entries, week, ByWeekday, nil, time.Local, time.Monday, now)
+		assert.ErrorContains(t, err, "timesheet rows")
+
+		_, err = BuildTimesheet(entries, week, "tag", nil, time.Local, time.Monday, now)
+		assert.ErrorContains(t, err, "doesn't have tags")
+
+		_, err = BuildTimesheet(entries, period.Range{Start: week.Start}, ByProject, nil, time.Local, time.Monday, now)
+		assert.Error(t, err)
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"go.yaml.in/yaml/v3"
 )
@@ -14,6 +17,17 @@ type Config struct {
 	HourlyRate float64 `yaml:"hourly_rate,omitempty"`
 	Description string `yaml:"description,omitempty"`
 	ExportPath  string `yaml:"export_path,omitempty"`
+	Milestones []ScheduledMilestone `yaml:"milestones,omitempty"`
+	Goals      Goals                `yaml:"goals,omitempty"`
+}
+
+// ScheduledMilestone is a milestone planned ahead in .tmporc, such as a sprint
+// of a sprint calendar. It runs from the start of its Start day to the end of
+// its End day.
+type ScheduledMilestone struct {
+	Name  string `yaml:"name"`
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
 }
 
 // IMPORTANT: When adding new fields to Config, update this template.
@@ -31,6 +45,17 @@ description: "%s"
 
 # [OPTIONAL] Default export path for this project (overrides global export path)
 export_path: "%s"
+
+# [OPTIONAL] Milestones planned ahead, started and finished automatically
+# milestones:
+#   - name: Sprint 1
+#     start: 2024-03-04
+#     end: 2024-03-15
+
+# [OPTIONAL] Target hours per day and per week for this project
+# goals:
+#   daily: 4
+#   weekly: 20
 `
 
 func Load(path string) (*Config, error) {
@@ -47,6 +72,66 @@ func Load(path string) (*Config, error) {
 	return &config, nil
 }
 
+// Span returns when the milestone starts and ends in loc.
+func (m ScheduledMilestone) Span(loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(m.Start), loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("milestone '%s' has an invalid start '%s', use YYYY-MM-DD", m.Name, m.Start)
+	}
+
+	end, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(m.End), loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("milestone '%s' has an invalid end '%s', use YYYY-MM-DD", m.Name, m.End)
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("milestone '%s' ends before it starts", m.Name)
+	}
+
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+// ValidateSchedule checks that every scheduled milestone has a name and
+// valid dates, and that no two of them share a name or overlap.
+func ValidateSchedule(milestones []ScheduledMilestone, loc *time.Location) error {
+	type span struct {
+		name       string
+		start, end time.Time
+	}
+
+	seen := make(map[string]bool)
+	var spans []span
+
+	for _, m := range milestones {
+		if strings.TrimSpace(m.Name) == "" {
+			return fmt.Errorf("scheduled milestones need a name")
+		}
+
+		if seen[m.Name] {
+			return fmt.Errorf("milestone '%s' is scheduled more than once", m.Name)
+		}
+		seen[m.Name] = true
+
+		start, end, err := m.Span(loc)
+		if err != nil {
+			return err
+		}
+		spans = append(spans, span{m.Name, start, end})
+	}
+
+	sort.Slice(spans, func(a, b int) bool {
+		return spans[a].start.Before(spans[b].start)
+	})
+
+	for i := 1; i < len(spans); i++ {
+		if spans[i].start.Before(spans[i-1].end) {
+			return fmt.Errorf("scheduled milestones '%s' and '%s' overlap", spans[i-1].name, spans[i].name)
+		}
+	}
+
+	return nil
+}
+
 func (c *Config) Save(path string) error {
 	data, err := yaml.Marshal(c)
 	if err != nil {
@@ -3,7 +3,9 @@ package settings
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -331,3 +333,139 @@ func TestFindAndLoad(t *testing.T) {
 		assert.Equal(t, expectedPath, actualPath)
 	})
 }
+
+func TestScheduledMilestones(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("loads scheduled milestones", func(t *testing.T) {
+		configPath := filepath.Join(tmpDir, "scheduled.tmporc")
+		content := `project_name: sprints
+milestones:
+  - name: Sprint 1
+    start: 2024-03-04
+    end: 2024-03-15
+  - name: Sprint 2
+    start: 2024-03-18
+    end: 2024-03-29
+`
+		err := os.WriteFile(configPath, []byte(content), 0644)
+		assert.NoError(t, err)
+
+		cfg, err := Load(configPath)
+		assert.NoError(t, err)
+		assert.Len(t, cfg.Milestones, 2)
+		assert.Equal(t, ScheduledMilestone{Name: "Sprint 1", Start: "2024-03-04", End: "2024-03-15"}, cfg.Milestones[0])
+		assert.NoError(t, ValidateSchedule(cfg.Milestones, time.UTC))
+
+		start, end, err := cfg.Milestones[0].Span(time.UTC)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), start)
+		assert.Equal(t, time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC), end, "the end day is included")
+	})
+
+	t.Run("rejects invalid schedules", func(t *testing.T) {
+		tests := map[string][]ScheduledMilestone{
+			"need a name":           {{Start: "2024-03-04", End: "2024-03-15"}},
+			"invalid start":         {{Name: "Sprint 1", Start: "March 4", End: "2024-03-15"}},
+			"ends before it starts": {{Name: "Sprint 1", Start: "2024-03-15", End: "2024-03-04"}},
+			"scheduled more than once": {
+				{Name: "Sprint 1", Start: "2024-03-04", End: "2024-03-08"},
+				{Name: "Sprint 1", Start: "2024-03-11", End: "2024-03-15"},
+			},
+			"overlap": {
+				{Name: "Sprint 2", Start: "2024-03-15", End: "2024-03-29"},
+				{Name: "Sprint 1", Start: "2024-03-04", End: "2024-03-15"},
+			},
+		}
+
+		for want, milestones := range tests {
+			err := ValidateSchedule(milestones, time.UTC)
+			if assert.Error(t, err, want) {
+				assert.Contains(t, err.Error(), want)
+			}
+		}
+	})
+}
+
+func TestFirstWeekday(t *testing.T) {
+	tests := map[string]time.Weekday{
+		"":         time.Monday,
+		"Sunday":   time.Sunday,
+		"saturday": time.Saturday,
+		" Wed ":    time.Wednesday,
+		"someday":  time.Monday,
+		"s":        time.Monday,
+	}
+
+	for weekStart, want := range tests {
+		cfg := &GlobalConfig{WeekStart: weekStart}
+		assert.Equal(t, want, cfg.FirstWeekday(), "week_start %q", weekStart)
+	}
+
+	_, err := ParseWeekday("someday")
+	assert.Error(t, err)
+}
+
+func TestGoals(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "goals.tmporc")
+	content := `project_name: goals-project
+goals:
+  daily: 4.5
+  weekly: 20
+`
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	cfg, err := Load(configPath)
+	assert.NoError(t, err)
+	assert.True(t, cfg.Goals.IsSet())
+	assert.Equal(t, 4*time.Hour+30*time.Minute, cfg.Goals.DailyTarget())
+	assert.Equal(t, 20*time.Hour, cfg.Goals.WeeklyTarget())
+
+	unset := Goals{Weekly: -1}
+	assert.False(t, unset.IsSet())
+	assert.Zero(t, unset.WeeklyTarget(), "negative goals aren't set")
+}
+
+func TestWorkingHours(t *testing.T) {
+	t.Run("working days default to Monday to Friday", func(t *testing.T) {
+		days, err := WorkingHours{Weekly: 40}.WorkingDays()
+		assert.NoError(t, err)
+		assert.Len(t, days, 5)
+		assert.True(t, days[time.Monday])
+		assert.False(t, days[time.Saturday])
+	})
+
+	t.Run("day target", func(t *testing.T) {
+		assert.Equal(t, 8*time.Hour, WorkingHours{Weekly: 40}.DayTarget(5))
+		assert.Equal(t, 9*time.Hour, WorkingHours{Daily: 9, Weekly: 40}.DayTarget(5))
+		assert.Equal(t, 10*time.Hour, WorkingHours{Weekly: 40, Days: []string{"Mon", "Tue", "Wed", "Thu"}}.DayTarget(4))
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		assert.NoError(t, WorkingHours{Daily: 8, Days: []string{"Sun", "monday"}}.Validate())
+		assert.ErrorContains(t, WorkingHours{}.Validate(), "set daily or weekly hours")
+		assert.ErrorContains(t, WorkingHours{Weekly: -1}.Validate(), "negative")
+		assert.ErrorContains(t, WorkingHours{Daily: 8, Days: []string{"Funday"}}.Validate(), "invalid day")
+	})
+
+	t.Run("holidays", func(t *testing.T) {
+		holidays, err := ParseHolidays(strings.NewReader("# 2024\n2024-12-25 Christmas Day\n\n2024-12-26\t\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"2024-12-25": "Christmas Day", "2024-12-26": "Holiday"}, holidays)
+
+		_, err = ParseHolidays(strings.NewReader("2024-12-25\nchristmas\n"))
+		assert.ErrorContains(t, err, "line 2")
+	})
+
+	t.Run("holidays file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "holidays.txt")
+		assert.NoError(t, os.WriteFile(path, []byte("2024-01-01 New Year's Day\n"), 0644))
+
+		holidays, err := WorkingHours{Daily: 8, Holidays: path}.LoadHolidays()
+		assert.NoError(t, err)
+		assert.Equal(t, "New Year's Day", holidays["2024-01-01"])
+
+		_, err = WorkingHours{Daily: 8, Holidays: filepath.Join(t.TempDir(), "missing")}.LoadHolidays()
+		assert.Error(t, err)
+	})
+}
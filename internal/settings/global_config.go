@@ -4,18 +4,50 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/DylanDevelops/tmpo/internal/currency"
 	"go.yaml.in/yaml/v3"
 )
 
+// DefaultSnapshotRetention is how many automatic database snapshots are kept
+// when snapshot_retention is not set.
+const DefaultSnapshotRetention = 10
+
+// DefaultTrashRetentionDays is how long deleted entries stay in the trash
+// when trash_retention_days is not set.
+const DefaultTrashRetentionDays = 30
+
+// Overlap policies decide what happens when a manual or edited entry overlaps
+// existing entries.
+const (
+	OverlapPolicyWarn  = "warn"
+	OverlapPolicyError = "error"
+)
+
 type GlobalConfig struct {
 	Currency   string `yaml:"currency"`
 	DateFormat string `yaml:"date_format,omitempty"`
 	TimeFormat string `yaml:"time_format,omitempty"`
 	Timezone   string `yaml:"timezone,omitempty"`
 	ExportPath string `yaml:"export_path,omitempty"`
+	// SnapshotRetention is the number of automatic snapshots to keep. Zero
+	// uses DefaultSnapshotRetention and a negative value disables snapshots.
+	SnapshotRetention int `yaml:"snapshot_retention,omitempty"`
+	// TrashRetentionDays is how many days deleted rows are kept before being
+	// purged. Zero uses DefaultTrashRetentionDays and a negative value keeps
+	// them until the trash is emptied.
+	TrashRetentionDays int `yaml:"trash_retention_days,omitempty"`
+	// OverlapPolicy is OverlapPolicyWarn (the default) or OverlapPolicyError.
+	OverlapPolicy string `yaml:"overlap_policy,omitempty"`
+	// WeekStart is the day weeks start on, such as Monday (the default) or
+	// Sunday.
+	WeekStart string `yaml:"week_start,omitempty"`
+	// Goals are the targets for the time tracked across all projects.
+	Goals Goals `yaml:"goals,omitempty"`
+	// WorkingHours is the policy overtime is measured against.
+	WorkingHours WorkingHours `yaml:"working_hours,omitempty"`
 }
 
 func DefaultGlobalConfig() *GlobalConfig {
@@ -92,6 +124,82 @@ func (gc *GlobalConfig) Save() error {
 	return nil
 }
 
+// SnapshotsToKeep resolves SnapshotRetention, applying the default when unset.
+func (gc *GlobalConfig) SnapshotsToKeep() int {
+	if gc.SnapshotRetention == 0 {
+		return DefaultSnapshotRetention
+	}
+
+	return gc.SnapshotRetention
+}
+
+// TrashDaysToKeep resolves TrashRetentionDays, applying the default when unset.
+func (gc *GlobalConfig) TrashDaysToKeep() int {
+	if gc.TrashRetentionDays == 0 {
+		return DefaultTrashRetentionDays
+	}
+
+	return gc.TrashRetentionDays
+}
+
+// RejectsOverlaps reports whether overlapping entries are an error rather
+// than a warning.
+func (gc *GlobalConfig) RejectsOverlaps() bool {
+	return gc.OverlapPolicy == OverlapPolicyError
+}
+
+// FirstWeekday resolves WeekStart, falling back to Monday when none is set or
+// the name is not a day of the week.
+func (gc *GlobalConfig) FirstWeekday() time.Weekday {
+	day, err := ParseWeekday(gc.WeekStart)
+	if err != nil {
+		return time.Monday
+	}
+
+	return day
+}
+
+// WeekStart returns the configured first day of the week, Monday by default.
+func WeekStart() time.Weekday {
+	cfg, err := LoadGlobalConfig()
+	if err != nil {
+		return time.Monday
+	}
+
+	return cfg.FirstWeekday()
+}
+
+// ParseWeekday parses the name of a day of the week, such as "sunday" or
+// "Sun".
+func ParseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if len(name) >= 3 {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.HasPrefix(strings.ToLower(day.String()), name) {
+				return day, nil
+			}
+		}
+	}
+
+	return time.Monday, fmt.Errorf("invalid day '%s', use a day of the week such as Monday or Sunday", name)
+}
+
+// Location returns the configured timezone, falling back to the system
+// timezone when none is set or the name is not a valid IANA zone.
+func (gc *GlobalConfig) Location() *time.Location {
+	if gc.Timezone == "" {
+		return time.Local
+	}
+
+	loc, err := time.LoadLocation(gc.Timezone)
+	if err != nil {
+		return time.Local
+	}
+
+	return loc
+}
+
 func FormatTime(t time.Time) string {
 	cfg, err := LoadGlobalConfig()
 	if err != nil || cfg.TimeFormat == "" || cfg.TimeFormat == "Keep current" {
@@ -0,0 +1,33 @@
+package settings
+
+import "time"
+
+// Goals are targets for the hours tracked per day and per week. A goal of
+// zero isn't set.
+type Goals struct {
+	Daily  float64 `yaml:"daily,omitempty"`
+	Weekly float64 `yaml:"weekly,omitempty"`
+}
+
+// IsSet reports whether there is a daily or a weekly goal.
+func (g Goals) IsSet() bool {
+	return g.Daily > 0 || g.Weekly > 0
+}
+
+// DailyTarget returns the daily goal as a duration, zero when unset.
+func (g Goals) DailyTarget() time.Duration {
+	return hoursToDuration(g.Daily)
+}
+
+// WeeklyTarget returns the weekly goal as a duration, zero when unset.
+func (g Goals) WeeklyTarget() time.Duration {
+	return hoursToDuration(g.Weekly)
+}
+
+func hoursToDuration(hours float64) time.Duration {
+	if hours <= 0 {
+		return 0
+	}
+
+	return time.Duration(hours * float64(time.Hour))
+}
@@ -0,0 +1,145 @@
+package settings
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WorkingHours is the working-hours policy overtime is measured against.
+type WorkingHours struct {
+	// Daily is the standard hours of a working day. When only Weekly is set,
+	// a working day is Weekly divided by the number of working days.
+	Daily float64 `yaml:"daily,omitempty"`
+	// Weekly is the standard hours of a week. It caps what a week is expected
+	// to add up to, such as 40 hours with 9 hour days.
+	Weekly float64 `yaml:"weekly,omitempty"`
+	// Days are the working days, such as Mon or Tuesday. Monday to Friday
+	// when empty.
+	Days []string `yaml:"days,omitempty"`
+	// Holidays is the path of a file of days off, one YYYY-MM-DD date per
+	// line, optionally followed by the holiday's name.
+	Holidays string `yaml:"holidays,omitempty"`
+}
+
+// IsSet reports whether standard daily or weekly hours are set.
+func (w WorkingHours) IsSet() bool {
+	return w.Daily > 0 || w.Weekly > 0
+}
+
+// WorkingDays resolves Days, defaulting to Monday to Friday.
+func (w WorkingHours) WorkingDays() (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool)
+
+	if len(w.Days) == 0 {
+		for day := time.Monday; day <= time.Friday; day++ {
+			days[day] = true
+		}
+		return days, nil
+	}
+
+	for _, name := range w.Days {
+		day, err := ParseWeekday(name)
+		if err != nil {
+			return nil, fmt.Errorf("working_hours: %w", err)
+		}
+		days[day] = true
+	}
+
+	return days, nil
+}
+
+// DayTarget returns the standard hours of a working day.
+func (w WorkingHours) DayTarget(workingDays int) time.Duration {
+	if w.Daily > 0 {
+		return hoursToDuration(w.Daily)
+	}
+	if workingDays == 0 {
+		return 0
+	}
+	return hoursToDuration(w.Weekly / float64(workingDays))
+}
+
+// WeekTarget returns the standard hours of a week, zero when unset.
+func (w WorkingHours) WeekTarget() time.Duration {
+	return hoursToDuration(w.Weekly)
+}
+
+// Validate checks that the policy sets standard hours, that none of them
+// are negative, and that the working days are days of the week.
+func (w WorkingHours) Validate() error {
+	if w.Daily < 0 || w.Weekly < 0 {
+		return fmt.Errorf("working_hours: standard hours can't be negative")
+	}
+
+	if !w.IsSet() {
+		return fmt.Errorf("working_hours: set daily or weekly hours")
+	}
+
+	if w.Daily > 24 {
+		return fmt.Errorf("working_hours: a day can't have more than 24 hours")
+	}
+
+	_, err := w.WorkingDays()
+	return err
+}
+
+// LoadHolidays reads the holidays file, returning the name of each holiday
+// by its date (YYYY-MM-DD). Holidays without a name are named "Holiday". It
+// returns no holidays when no file is set.
+func (w WorkingHours) LoadHolidays() (map[string]string, error) {
+	if w.Holidays == "" {
+		return map[string]string{}, nil
+	}
+
+	path := w.Holidays
+	if strings.HasPrefix(path, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holidays file: %w", err)
+	}
+	defer file.Close()
+
+	return ParseHolidays(file)
+}
+
+// ParseHolidays parses a holidays file. Blank lines and lines starting with
+// # are ignored.
+func ParseHolidays(r io.Reader) (map[string]string, error) {
+	holidays := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		day, err := time.Parse("2006-01-02", fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("holidays file line %d: invalid date '%s', use YYYY-MM-DD", line, fields[0])
+		}
+
+		name := strings.Join(fields[1:], " ")
+		if name == "" {
+			name = "Holiday"
+		}
+		holidays[day.Format("2006-01-02")] = name
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read holidays file: %w", err)
+	}
+
+	return holidays, nil
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Entry sources record how an entry was first created.
+const (
+	SourceTimer     = "timer"
+	SourceManual    = "manual"
+	SourceImport    = "import"
+	SourceResume    = "resume"
+	SourceDuplicate = "duplicate"
+)
+
+// Audited entry fields. Time values are stored as RFC 3339.
+const (
+	FieldProject     = "project"
+	FieldStart       = "start"
+	FieldEnd         = "end"
+	FieldDescription = "description"
+	FieldRate        = "rate"
+	FieldMilestone   = "milestone"
+)
+
+// Via values for edits replayed from the journal rather than made directly.
+const (
+	ViaUndo = "undo"
+	ViaRedo = "redo"
+)
+
+// EntryEdit is one field change made to an existing entry. Edits are only
+// ever appended, so an entry's edits are its complete change history.
+type EntryEdit struct {
+	ID      int64
+	EntryID int64
+	Field   string
+	// Before and After are nil when the field was empty.
+	Before *string
+	After  *string
+	// Via is the operation that made the change, such as "edit", "undo"
+	// or "repair".
+	Via      string
+	EditedAt time.Time
+}
+
+// auditedEntry reads an entry for auditing inside a transaction, including
+// trashed entries. It returns nil if the entry doesn't exist.
+func auditedEntry(q querier, id int64) (*TimeEntry, error) {
+	entry, err := scanEntry(q.QueryRow("SELECT "+entryColumns+" FROM time_entries WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// appendEdits records every audited field that differs between before and
+// after.
+func appendEdits(q querier, before, after *TimeEntry, via string) error {
+	now := time.Now()
+
+	for _, field := range []string{FieldProject, FieldStart, FieldEnd, FieldDescription, FieldRate, FieldMilestone} {
+		old, current := auditValue(before, field), auditValue(after, field)
+		if textEqual(old, current) {
+			continue
+		}
+
+		_, err := q.Exec(
+			"INSERT INTO entry_edits (entry_id, field, old_value, new_value, via, edited_at) VALUES (?, ?, ?, ?, ?, ?)",
+			after.ID, field, old, current, via, now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record edit: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// auditStep compares an entry against its state before a change and records
+// the difference.
+func auditStep(q querier, before *TimeEntry, id int64, via string) error {
+	after, err := auditedEntry(q, id)
+	if err != nil {
+		return err
+	}
+
+	return appendEdits(q, before, after, via)
+}
+
+func auditValue(entry *TimeEntry, field string) *string {
+	var value string
+
+	switch field {
+	case FieldProject:
+		value = entry.ProjectName
+	case FieldStart:
+		value = entry.StartTime.Format(time.RFC3339)
+	case FieldEnd:
+		if entry.EndTime == nil {
+			return nil
+		}
+		value = entry.EndTime.Format(time.RFC3339)
+	case FieldDescription:
+		value = entry.Description
+	case FieldRate:
+		if entry.HourlyRate == nil {
+			return nil
+		}
+		value = strconv.FormatFloat(*entry.HourlyRate, 'f', -1, 64)
+	case FieldMilestone:
+		if entry.MilestoneName == nil {
+			return nil
+		}
+		value = *entry.MilestoneName
+	}
+
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+// GetEntryEdits returns the recorded edits of an entry, oldest first.
+func (d *Database) GetEntryEdits(entryID int64) ([]*EntryEdit, error) {
+	rows, err := d.db.Query(
+		"SELECT id, entry_id, field, old_value, new_value, via, edited_at FROM entry_edits WHERE entry_id = ? ORDER BY id",
+		entryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query edits: %w", err)
+	}
+	defer rows.Close()
+
+	var edits []*EntryEdit
+	for rows.Next() {
+		var edit EntryEdit
+		if err := rows.Scan(&edit.ID, &edit.EntryID, &edit.Field, &edit.Before, &edit.After, &edit.Via, &edit.EditedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan edit: %w", err)
+		}
+		edits = append(edits, &edit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read edits: %w", err)
+	}
+
+	return edits, nil
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntrySource(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+	timer, err := db.CreateEntry("p", "live", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, SourceTimer, timer.Source)
+	assert.NotNil(t, timer.CreatedAt)
+	require.NoError(t, db.StopEntry(timer.ID))
+
+	resumed, err := db.ResumeEntry(timer)
+	require.NoError(t, err)
+	assert.Equal(t, SourceResume, resumed.Source)
+	assert.Equal(t, "live", resumed.Description)
+	require.NoError(t, db.StopEntry(resumed.ID))
+
+	manual, err := db.CreateManualEntry("p", "later", base, base.Add(2*time.Hour), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, SourceManual, manual.Source)
+
+	t.Run("split entries keep their source", func(t *testing.T) {
+		_, err := db.CarveOut(base.Add(30*time.Minute), base.Add(time.Hour), 0)
+		require.NoError(t, err)
+
+		entries, err := db.GetEntriesByFilter(EntryFilter{Start: base, End: base.Add(3 * time.Hour)})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		for _, entry := range entries {
+			assert.Equal(t, SourceManual, entry.Source)
+		}
+	})
+}
+
+func TestEntryEdits(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+	t.Run("records each changed field", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "original", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		assert.False(t, entry.Edited)
+
+		end := base.Add(2 * time.Hour)
+		milestone := "Sprint"
+		edited := *entry
+		edited.EndTime = &end
+		edited.Description = "changed"
+		edited.MilestoneName = &milestone
+		require.NoError(t, db.UpdateTimeEntry(entry.ID, &edited))
+
+		edits, err := db.GetEntryEdits(entry.ID)
+		require.NoError(t, err)
+		require.Len(t, edits, 3)
+
+		assert.Equal(t, FieldEnd, edits[0].Field)
+		assert.Equal(t, base.Add(time.Hour).Format(time.RFC3339), *edits[0].Before)
+		assert.Equal(t, end.Format(time.RFC3339), *edits[0].After)
+		assert.Equal(t, OpEdit, edits[0].Via)
+
+		assert.Equal(t, FieldDescription, edits[1].Field)
+		assert.Equal(t, "original", *edits[1].Before)
+		assert.Equal(t, "changed", *edits[1].After)
+
+		assert.Equal(t, FieldMilestone, edits[2].Field)
+		assert.Nil(t, edits[2].Before)
+		assert.Equal(t, "Sprint", *edits[2].After)
+
+		got, err := db.GetEntry(entry.ID)
+		require.NoError(t, err)
+		assert.True(t, got.Edited)
+	})
+
+	t.Run("saving without changes records nothing", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "same", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, db.UpdateTimeEntry(entry.ID, entry))
+
+		edits, err := db.GetEntryEdits(entry.ID)
+		require.NoError(t, err)
+		assert.Empty(t, edits)
+	})
+
+	t.Run("stopping a timer is not an edit", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateEntry("p", "", nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, db.StopEntry(entry.ID))
+
+		got, err := db.GetEntry(entry.ID)
+		require.NoError(t, err)
+		assert.False(t, got.Edited)
+	})
+
+	t.Run("undo and redo are appended", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "original", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		edited := *entry
+		edited.Description = "changed"
+		require.NoError(t, db.UpdateTimeEntry(entry.ID, &edited))
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+		_, err = db.Redo()
+		require.NoError(t, err)
+
+		edits, err := db.GetEntryEdits(entry.ID)
+		require.NoError(t, err)
+		require.Len(t, edits, 3)
+		assert.Equal(t, []string{OpEdit, ViaUndo, ViaRedo}, []string{edits[0].Via, edits[1].Via, edits[2].Via})
+		assert.Equal(t, "original", *edits[1].After)
+		assert.Equal(t, "changed", *edits[2].After)
+	})
+
+	t.Run("overlap trims are recorded", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "", base, base.Add(2*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		_, err = db.CarveOut(base.Add(time.Hour), base.Add(3*time.Hour), 0)
+		require.NoError(t, err)
+
+		edits, err := db.GetEntryEdits(entry.ID)
+		require.NoError(t, err)
+		require.Len(t, edits, 1)
+		assert.Equal(t, FieldEnd, edits[0].Field)
+		assert.Equal(t, OpResolveOverlap, edits[0].Via)
+	})
+}
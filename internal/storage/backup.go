@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// autoSnapshotPrefix marks snapshots taken automatically so that pruning
+// never touches backups created explicitly with 'tmpo db backup'.
+const autoSnapshotPrefix = "auto-"
+
+// Snapshot describes a backup file in the backups directory.
+type Snapshot struct {
+	Path      string
+	CreatedAt time.Time
+	Size      int64
+	Automatic bool
+}
+
+// BackupDir returns the directory holding backups and automatic snapshots.
+func BackupDir() (string, error) {
+	dbPath, err := DatabasePath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(filepath.Dir(dbPath), "backups"), nil
+}
+
+// Backup writes a consistent copy of the database to dest using VACUUM INTO,
+// which is safe to run while the database is open.
+func (d *Database) Backup(dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("backup file %s already exists", dest)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	if _, err := d.db.Exec("VACUUM INTO ?", dest); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	return nil
+}
+
+// Snapshot takes an automatic backup before a destructive operation and
+// prunes old snapshots beyond the configured retention. It returns the path
+// of the new snapshot, or an empty string when snapshots are disabled.
+func (d *Database) Snapshot(reason string) (string, error) {
+	if d.path == "" || d.snapshotRetention < 0 {
+		return "", nil
+	}
+
+	dir := filepath.Join(filepath.Dir(d.path), "backups")
+	// names sort by age; a snapshot taken within the same clock tick as
+	// another waits for the next one rather than reusing its name
+	var dest string
+	for {
+		name := fmt.Sprintf("%s%s-%s.db", autoSnapshotPrefix, time.Now().Format("20060102-150405.000000000"), reason)
+		dest = filepath.Join(dir, name)
+		if _, err := os.Stat(dest); os.IsNotExist(err) {
+			break
+		}
+	}
+
+	if err := d.Backup(dest); err != nil {
+		return "", fmt.Errorf("failed to take %s snapshot: %w", reason, err)
+	}
+
+	if err := pruneSnapshots(dir, d.snapshotRetention); err != nil {
+		return dest, err
+	}
+
+	return dest, nil
+}
+
+func pruneSnapshots(dir string, keep int) error {
+	matches, err := filepath.Glob(filepath.Join(dir, autoSnapshotPrefix+"*.db"))
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	// names start with a sortable timestamp, so lexical order is age order
+	sort.Strings(matches)
+
+	for len(matches) > keep {
+		if err := os.Remove(matches[0]); err != nil {
+			return fmt.Errorf("failed to remove old snapshot: %w", err)
+		}
+		matches = matches[1:]
+	}
+
+	return nil
+}
+
+// ListSnapshots returns every backup in the backups directory, newest first.
+func ListSnapshots() ([]Snapshot, error) {
+	dir, err := BackupDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".db" {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup info: %w", err)
+		}
+
+		snapshots = append(snapshots, Snapshot{
+			Path:      filepath.Join(dir, file.Name()),
+			CreatedAt: info.ModTime(),
+			Size:      info.Size(),
+			Automatic: strings.HasPrefix(file.Name(), autoSnapshotPrefix),
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// RestoreFrom replaces the database with the backup at src. The current data
+// is snapshotted first, and the Database is closed afterwards, so callers must
+// not use it again. It returns the path of the pre-restore snapshot.
+func (d *Database) RestoreFrom(src string) (string, error) {
+	if d.path == "" {
+		return "", fmt.Errorf("cannot restore into an in-memory database")
+	}
+
+	if err := validateBackup(src); err != nil {
+		return "", err
+	}
+
+	snapshot, err := d.Snapshot("restore")
+	if err != nil {
+		return "", err
+	}
+
+	if err := d.db.Close(); err != nil {
+		return snapshot, fmt.Errorf("failed to close database: %w", err)
+	}
+
+	// copy next to the database first so the final rename is atomic
+	tmp := d.path + ".restore"
+	if err := copyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return snapshot, err
+	}
+
+	if err := os.Rename(tmp, d.path); err != nil {
+		os.Remove(tmp)
+		return snapshot, fmt.Errorf("failed to replace database: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// validateBackup makes sure src is an intact SQLite file holding tmpo data.
+func validateBackup(src string) error {
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("backup file not found: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", src)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("%s is not a valid tmpo database: %w", src, err)
+	}
+
+	if result != "ok" {
+		return fmt.Errorf("%s failed the integrity check: %s", src, result)
+	}
+
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'time_entries'").Scan(&tables); err != nil {
+		return fmt.Errorf("%s is not a valid tmpo database: %w", src, err)
+	}
+
+	if tables == 0 {
+		return fmt.Errorf("%s does not contain tmpo data", src)
+	}
+
+	return nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create database file: %w", err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy backup: %w", err)
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupFileDB creates a migrated database file inside a temporary directory.
+func setupFileDB(t *testing.T, retention int) *Database {
+	path := filepath.Join(t.TempDir(), "tmpo.db")
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	require.NoError(t, migrate(db))
+
+	return &Database{db: db, path: path, snapshotRetention: retention}
+}
+
+func TestBackup(t *testing.T) {
+	db := setupFileDB(t, 5)
+	defer db.Close()
+
+	_, err := db.CreateEntry("backed-up", "", nil, nil)
+	require.NoError(t, err)
+
+	dest := filepath.Join(t.TempDir(), "nested", "backup.db")
+	assert.NoError(t, db.Backup(dest))
+	assert.NoError(t, validateBackup(dest))
+
+	// refuses to overwrite an existing file
+	assert.Error(t, db.Backup(dest))
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Run("keeps only the configured number of snapshots", func(t *testing.T) {
+		db := setupFileDB(t, 2)
+		defer db.Close()
+
+		for i := 0; i < 4; i++ {
+			path, err := db.Snapshot("test")
+			require.NoError(t, err)
+			assert.FileExists(t, path)
+		}
+
+		matches, err := filepath.Glob(filepath.Join(filepath.Dir(db.path), "backups", "auto-*.db"))
+		require.NoError(t, err)
+		assert.Len(t, matches, 2)
+	})
+
+	t.Run("gives snapshots taken back to back their own names", func(t *testing.T) {
+		db := setupFileDB(t, 10)
+		defer db.Close()
+
+		var paths []string
+		for i := 0; i < 5; i++ {
+			path, err := db.Snapshot("test")
+			require.NoError(t, err)
+			paths = append(paths, path)
+		}
+
+		assert.True(t, sort.StringsAreSorted(paths), "names sort oldest first")
+		assert.Len(t, slices.Compact(paths), 5)
+	})
+
+	t.Run("does not prune manual backups", func(t *testing.T) {
+		db := setupFileDB(t, 1)
+		defer db.Close()
+
+		manual := filepath.Join(filepath.Dir(db.path), "backups", "tmpo-backup-manual.db")
+		require.NoError(t, db.Backup(manual))
+
+		for i := 0; i < 3; i++ {
+			_, err := db.Snapshot("test")
+			require.NoError(t, err)
+		}
+
+		assert.FileExists(t, manual)
+	})
+
+	t.Run("is skipped when disabled", func(t *testing.T) {
+		db := setupFileDB(t, -1)
+		defer db.Close()
+
+		path, err := db.Snapshot("test")
+		assert.NoError(t, err)
+		assert.Empty(t, path)
+	})
+
+	t.Run("is skipped for in-memory databases", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		path, err := db.Snapshot("test")
+		assert.NoError(t, err)
+		assert.Empty(t, path)
+	})
+
+	t.Run("is taken before deleting an entry", func(t *testing.T) {
+		db := setupFileDB(t, 5)
+		defer db.Close()
+
+		entry, err := db.CreateEntry("project", "", nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, db.DeleteTimeEntry(entry.ID))
+
+		matches, err := filepath.Glob(filepath.Join(filepath.Dir(db.path), "backups", "auto-*-delete.db"))
+		require.NoError(t, err)
+		assert.Len(t, matches, 1)
+	})
+
+	t.Run("is taken before emptying the trash", func(t *testing.T) {
+		db := setupFileDB(t, 5)
+		defer db.Close()
+
+		entry, err := db.CreateEntry("project", "", nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, db.DeleteTimeEntry(entry.ID))
+
+		_, _, err = db.EmptyTrash()
+		require.NoError(t, err)
+
+		matches, err := filepath.Glob(filepath.Join(filepath.Dir(db.path), "backups", "auto-*-empty-trash.db"))
+		require.NoError(t, err)
+		assert.Len(t, matches, 1)
+	})
+
+	t.Run("is not taken when adding an entry", func(t *testing.T) {
+		db := setupFileDB(t, 5)
+		defer db.Close()
+
+		_, err := db.CreateEntry("project", "", nil, nil)
+		require.NoError(t, err)
+
+		matches, err := filepath.Glob(filepath.Join(filepath.Dir(db.path), "backups", "auto-*.db"))
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+}
+
+func TestRestoreFrom(t *testing.T) {
+	t.Run("replaces the database with the backup", func(t *testing.T) {
+		db := setupFileDB(t, 5)
+
+		_, err := db.CreateEntry("before-backup", "", nil, nil)
+		require.NoError(t, err)
+
+		backup := filepath.Join(t.TempDir(), "backup.db")
+		require.NoError(t, db.Backup(backup))
+
+		_, err = db.CreateEntry("after-backup", "", nil, nil)
+		require.NoError(t, err)
+
+		path := db.path
+		snapshot, err := db.RestoreFrom(backup)
+		require.NoError(t, err)
+		assert.FileExists(t, snapshot)
+
+		raw, err := sql.Open("sqlite", path)
+		require.NoError(t, err)
+		restored := &Database{db: raw, path: path}
+		defer restored.Close()
+
+		projects, err := restored.GetAllProjects()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"before-backup"}, projects)
+	})
+
+	t.Run("rejects files that are not tmpo databases", func(t *testing.T) {
+		db := setupFileDB(t, 5)
+		defer db.Close()
+
+		bogus := filepath.Join(t.TempDir(), "bogus.db")
+		require.NoError(t, os.WriteFile(bogus, []byte("not a database"), 0644))
+
+		_, err := db.RestoreFrom(bogus)
+		assert.Error(t, err)
+
+		// the database is still usable after a rejected restore
+		_, err = db.GetAllProjects()
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// MilestonePlan is the optional budget and deadline of a milestone. DueDate
+// is the start of the day the milestone is due.
+type MilestonePlan struct {
+	BudgetHours  *float64
+	BudgetAmount *float64
+	DueDate      *time.Time
+}
+
+func (p MilestonePlan) validate() error {
+	if p.BudgetHours != nil && *p.BudgetHours <= 0 {
+		return fmt.Errorf("hour budget must be greater than zero")
+	}
+
+	if p.BudgetAmount != nil && *p.BudgetAmount <= 0 {
+		return fmt.Errorf("money budget must be greater than zero")
+	}
+
+	return nil
+}
+
+func (p MilestonePlan) dueDate() sql.NullTime {
+	if p.DueDate == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *p.DueDate, Valid: true}
+}
+
+// Plan returns the milestone's current budget and due date.
+func (m *Milestone) Plan() MilestonePlan {
+	return MilestonePlan{BudgetHours: m.BudgetHours, BudgetAmount: m.BudgetAmount, DueDate: m.DueDate}
+}
+
+// SetMilestonePlan replaces a milestone's budget and due date. Nil fields
+// clear them.
+func (d *Database) SetMilestonePlan(id int64, plan MilestonePlan) error {
+	if err := plan.validate(); err != nil {
+		return err
+	}
+
+	return d.record(OpMilestoneEdit, func(j *journal) (string, error) {
+		before, err := j.track(tableMilestones, id)
+		if err != nil {
+			return "", err
+		}
+
+		if before == nil || before["deleted_at"] != nil {
+			return "", fmt.Errorf("milestone #%d not found", id)
+		}
+
+		if _, err := j.tx.Exec(
+			"UPDATE milestones SET budget_hours = ?, budget_amount = ?, due_date = ? WHERE id = ?",
+			plan.BudgetHours, plan.BudgetAmount, plan.dueDate(), id,
+		); err != nil {
+			return "", fmt.Errorf("failed to update milestone: %w", err)
+		}
+
+		return fmt.Sprintf("Edited budget of milestone %s in %s", before.text("name"), before.text("project_name")), nil
+	})
+}
+
+// MilestoneProgress is how far a milestone has used its budget.
+type MilestoneProgress struct {
+	Milestone *Milestone
+	Entries   int
+	// Tracked includes the running entry up to now.
+	Tracked time.Duration
+	// Earned only counts entries with an hourly rate.
+	Earned float64
+
+	now time.Time
+}
+
+// GetMilestoneProgress totals the entries of a milestone, including those of
+// the milestones nested inside it.
+func (d *Database) GetMilestoneProgress(m *Milestone) (*MilestoneProgress, error) {
+	entries, err := d.GetEntriesInMilestoneTree(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMilestoneProgress(m, entries, time.Now()), nil
+}
+
+// NewMilestoneProgress totals the given entries of a milestone as of now.
+func NewMilestoneProgress(m *Milestone, entries []*TimeEntry, now time.Time) *MilestoneProgress {
+	progress := &MilestoneProgress{Milestone: m, Entries: len(entries), now: now}
+
+	for _, entry := range entries {
+		duration := entry.Duration()
+		if entry.IsRunning() {
+			duration = now.Sub(entry.StartTime)
+		}
+		progress.Tracked += duration
+
+		if entry.HourlyRate != nil {
+			progress.Earned += math.Round(duration.Hours()*100) / 100 * *entry.HourlyRate
+		}
+	}
+
+	return progress
+}
+
+// HoursUsed returns the share of the hour budget used, and false if the
+// milestone has no hour budget.
+func (p *MilestoneProgress) HoursUsed() (float64, bool) {
+	if p.Milestone.BudgetHours == nil {
+		return 0, false
+	}
+	return p.Tracked.Hours() / *p.Milestone.BudgetHours, true
+}
+
+// AmountUsed returns the share of the money budget used, and false if the
+// milestone has no money budget.
+func (p *MilestoneProgress) AmountUsed() (float64, bool) {
+	if p.Milestone.BudgetAmount == nil {
+		return 0, false
+	}
+	return p.Earned / *p.Milestone.BudgetAmount, true
+}
+
+// OverBudget reports whether either budget has been exceeded.
+func (p *MilestoneProgress) OverBudget() bool {
+	hours, ok := p.HoursUsed()
+	if ok && hours > 1 {
+		return true
+	}
+
+	amount, ok := p.AmountUsed()
+	return ok && amount > 1
+}
+
+// Overdue reports whether an active milestone is still running after its
+// due date has ended.
+func (p *MilestoneProgress) Overdue() bool {
+	due := p.Milestone.DueDate
+	return p.Milestone.IsActive() && due != nil && !p.now.Before(due.AddDate(0, 0, 1))
+}
+
+// BurnRate returns the time tracked per calendar day since the milestone
+// started, counting at least one day.
+func (p *MilestoneProgress) BurnRate() time.Duration {
+	end := p.now
+	if p.Milestone.EndTime != nil {
+		end = *p.Milestone.EndTime
+	}
+
+	days := end.Sub(p.Milestone.StartTime).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+
+	return time.Duration(float64(p.Tracked) / days)
+}
+
+// ProjectedCompletion returns when an active milestone will use up its hour
+// budget at the current burn rate, and false if that can't be projected.
+func (p *MilestoneProgress) ProjectedCompletion() (time.Time, bool) {
+	used, ok := p.HoursUsed()
+	if !ok || used >= 1 || !p.Milestone.IsActive() {
+		return time.Time{}, false
+	}
+
+	rate := p.BurnRate()
+	if rate <= 0 {
+		return time.Time{}, false
+	}
+
+	remaining := time.Duration(*p.Milestone.BudgetHours*float64(time.Hour)) - p.Tracked
+	days := float64(remaining) / float64(rate)
+
+	return p.now.Add(time.Duration(days * 24 * float64(time.Hour))), true
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMilestonePlan(t *testing.T) {
+	t.Run("create and update plan", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		hours := 40.0
+		due := time.Date(2024, 3, 15, 0, 0, 0, 0, time.Local)
+		milestone, err := db.CreateMilestoneWithPlan("p", "Sprint 1", nil, MilestonePlan{BudgetHours: &hours, DueDate: &due})
+		require.NoError(t, err)
+		require.NotNil(t, milestone.BudgetHours)
+		assert.Equal(t, 40.0, *milestone.BudgetHours)
+		assert.Nil(t, milestone.BudgetAmount)
+		require.NotNil(t, milestone.DueDate)
+		assert.True(t, milestone.DueDate.Equal(due))
+
+		amount := 5000.0
+		plan := milestone.Plan()
+		plan.BudgetAmount = &amount
+		plan.DueDate = nil
+		require.NoError(t, db.SetMilestonePlan(milestone.ID, plan))
+
+		got, err := db.GetMilestone(milestone.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 40.0, *got.BudgetHours)
+		assert.Equal(t, 5000.0, *got.BudgetAmount)
+		assert.Nil(t, got.DueDate)
+
+		op, err := db.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, OpMilestoneEdit, op.Kind)
+
+		got, err = db.GetMilestone(milestone.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.BudgetAmount)
+		assert.NotNil(t, got.DueDate)
+	})
+
+	t.Run("rejects non-positive budgets", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		zero := 0.0
+		_, err := db.CreateMilestoneWithPlan("p", "M", nil, MilestonePlan{BudgetHours: &zero})
+		assert.Error(t, err)
+
+		milestone, err := db.CreateMilestone("p", "M")
+		require.NoError(t, err)
+		assert.Error(t, db.SetMilestonePlan(milestone.ID, MilestonePlan{BudgetAmount: &zero}))
+	})
+}
+
+func TestMilestoneProgress(t *testing.T) {
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	now := start.AddDate(0, 0, 4)
+	rate := 100.0
+
+	entry := func(offset, length time.Duration) *TimeEntry {
+		end := start.Add(offset + length)
+		return &TimeEntry{StartTime: start.Add(offset), EndTime: &end, HourlyRate: &rate}
+	}
+
+	// 20 hours over 4 days
+	entries := []*TimeEntry{
+		entry(0, 8*time.Hour),
+		entry(24*time.Hour, 8*time.Hour),
+		entry(48*time.Hour, 4*time.Hour),
+	}
+
+	t.Run("without budget", func(t *testing.T) {
+		progress := NewMilestoneProgress(&Milestone{StartTime: start}, entries, now)
+		assert.Equal(t, 20*time.Hour, progress.Tracked)
+		assert.Equal(t, 2000.0, progress.Earned)
+		assert.Equal(t, 3, progress.Entries)
+
+		_, ok := progress.HoursUsed()
+		assert.False(t, ok)
+		assert.False(t, progress.OverBudget())
+		assert.Equal(t, 5*time.Hour, progress.BurnRate())
+
+		_, ok = progress.ProjectedCompletion()
+		assert.False(t, ok)
+	})
+
+	t.Run("projects completion from burn rate", func(t *testing.T) {
+		hours := 40.0
+		progress := NewMilestoneProgress(&Milestone{StartTime: start, BudgetHours: &hours}, entries, now)
+
+		used, ok := progress.HoursUsed()
+		require.True(t, ok)
+		assert.InDelta(t, 0.5, used, 0.001)
+
+		// 20 hours left at 5 hours a day
+		projected, ok := progress.ProjectedCompletion()
+		require.True(t, ok)
+		assert.True(t, projected.Equal(now.AddDate(0, 0, 4)), projected)
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		hours := 10.0
+		amount := 1500.0
+		progress := NewMilestoneProgress(&Milestone{StartTime: start, BudgetHours: &hours, BudgetAmount: &amount}, entries, now)
+		assert.True(t, progress.OverBudget())
+
+		_, ok := progress.ProjectedCompletion()
+		assert.False(t, ok)
+
+		hours = 100
+		assert.True(t, progress.OverBudget(), "money budget exceeded")
+	})
+
+	t.Run("counts the running entry", func(t *testing.T) {
+		running := &TimeEntry{StartTime: now.Add(-time.Hour)}
+		progress := NewMilestoneProgress(&Milestone{StartTime: start}, []*TimeEntry{running}, now)
+		assert.Equal(t, time.Hour, progress.Tracked)
+	})
+
+	t.Run("overdue", func(t *testing.T) {
+		due := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		milestone := &Milestone{StartTime: start, DueDate: &due}
+		assert.False(t, NewMilestoneProgress(milestone, nil, now).Overdue(), "due today")
+
+		due = due.AddDate(0, 0, -1)
+		assert.True(t, NewMilestoneProgress(milestone, nil, now).Overdue())
+
+		end := now
+		milestone.EndTime = &end
+		assert.False(t, NewMilestoneProgress(milestone, nil, now).Overdue())
+	})
+}
@@ -8,18 +8,35 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DylanDevelops/tmpo/internal/settings"
 	_ "modernc.org/sqlite"
 )
 
 type Database struct {
 	db *sql.DB
+	// path is the database file on disk, empty for in-memory databases.
+	path string
+	// snapshotRetention is how many automatic snapshots to keep; a negative
+	// value disables them.
+	snapshotRetention int
+	// trashRetentionDays is how long deleted rows stay in the trash; zero
+	// or less keeps them until the trash is emptied.
+	trashRetentionDays int
+	// rejectOverlaps makes CreateManualEntry and UpdateTimeEntry fail with an
+	// OverlapError instead of accepting overlapping intervals.
+	rejectOverlaps bool
 }
 
-func Initialize() (*Database, error) {
+// schemaVersion is stored in PRAGMA user_version. Bump it whenever migrate
+// changes the schema so existing databases are snapshotted before upgrading.
+const schemaVersion = 8
+
+// DatabasePath returns the location of the tmpo database file.
+func DatabasePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
 
 	tmpoDir := filepath.Join(homeDir, ".tmpo")
@@ -27,18 +44,72 @@ func Initialize() (*Database, error) {
 		tmpoDir = filepath.Join(homeDir, ".tmpo-dev")
 	}
 
-	if err := os.MkdirAll(tmpoDir, 0755); err != nil {
+	return filepath.Join(tmpoDir, "tmpo.db"), nil
+}
+
+func Initialize() (*Database, error) {
+	dbPath, err := DatabasePath()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create .tmpo directory: %w", err)
 	}
 
-	dbPath := filepath.Join(tmpoDir, "tmpo.db")
 	db, err := sql.Open("sqlite", dbPath)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	_, err = db.Exec(`
+	database := &Database{db: db, path: dbPath}
+
+	if globalCfg, err := settings.LoadGlobalConfig(); err == nil {
+		database.snapshotRetention = globalCfg.SnapshotsToKeep()
+		database.rejectOverlaps = globalCfg.RejectsOverlaps()
+		database.trashRetentionDays = globalCfg.TrashDaysToKeep()
+	} else {
+		database.snapshotRetention = settings.DefaultSnapshotRetention
+		database.trashRetentionDays = settings.DefaultTrashRetentionDays
+	}
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if version < schemaVersion {
+		var tables int
+		if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'time_entries'").Scan(&tables); err != nil {
+			return nil, fmt.Errorf("failed to inspect database: %w", err)
+		}
+
+		// only existing data needs protecting before a schema upgrade
+		if tables > 0 {
+			if _, err := database.Snapshot("migration"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := migrate(db); err != nil {
+		return nil, err
+	}
+
+	if database.trashRetentionDays > 0 {
+		if err := database.purgeTrash(time.Now().AddDate(0, 0, -database.trashRetentionDays)); err != nil {
+			return nil, err
+		}
+	}
+
+	return database, nil
+}
+
+// migrate creates or upgrades the schema. Every statement is idempotent so it
+// is safe to run on each start.
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`
 		CREATE TABLE IF NOT EXISTS time_entries (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			project_name TEXT NOT NULL,
@@ -50,7 +121,7 @@ func Initialize() (*Database, error) {
 	`)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to create table: %w", err)
+		return fmt.Errorf("failed to create table: %w", err)
 	}
 
 	_, err = db.Exec(`
@@ -65,210 +136,162 @@ func Initialize() (*Database, error) {
 	`)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to create milestones table: %w", err)
-	}
-
-	_, err = db.Exec(`ALTER TABLE time_entries ADD COLUMN hourly_rate REAL`)
-	if err != nil && !isColumnExistsError(err) {
-		return nil, fmt.Errorf("failed to add hourly_rate column: %w", err)
-	}
-
-	_, err = db.Exec(`ALTER TABLE time_entries ADD COLUMN milestone_name TEXT`)
-	if err != nil && !isColumnExistsError(err) {
-		return nil, fmt.Errorf("failed to add milestone_name column: %w", err)
+		return fmt.Errorf("failed to create milestones table: %w", err)
 	}
 
-	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_time_entries_milestone ON time_entries(milestone_name)`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create index: %w", err)
-	}
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS operations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL,
+			description TEXT NOT NULL,
+			changes TEXT NOT NULL,
+			status TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		)
+	`)
 
-	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_milestones_project_active ON milestones(project_name, end_time)`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create index: %w", err)
-	}
-
-	return &Database{db: db}, nil
-}
-
-func isColumnExistsError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errMsg := err.Error()
-	return strings.Contains(errMsg, "duplicate column name") ||
-		strings.Contains(errMsg, "duplicate column")
-}
-
-func (d *Database) CreateEntry(projectName, description string, hourlyRate *float64, milestoneName *string) (*TimeEntry, error) {
-	var rate sql.NullFloat64
-	if hourlyRate != nil {
-		rate = sql.NullFloat64{Float64: *hourlyRate, Valid: true}
-	}
-
-	var milestone sql.NullString
-	if milestoneName != nil {
-		milestone = sql.NullString{String: *milestoneName, Valid: true}
+		return fmt.Errorf("failed to create operations table: %w", err)
 	}
 
-	result, err := d.db.Exec(
-		"INSERT INTO time_entries (project_name, start_time, description, hourly_rate, milestone_name) VALUES (?, ?, ?, ?, ?)",
-		projectName,
-		time.Now(),
-		description,
-		rate,
-		milestone,
-	)
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_edits (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL,
+			field TEXT NOT NULL,
+			old_value TEXT,
+			new_value TEXT,
+			via TEXT NOT NULL,
+			edited_at DATETIME NOT NULL
+		)
+	`)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to create entry: %w", err)
+		return fmt.Errorf("failed to create entry_edits table: %w", err)
 	}
 
-	id, err := result.LastInsertId()
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS time_off (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			day TEXT NOT NULL UNIQUE,
+			kind TEXT NOT NULL,
+			name TEXT
+		)
+	`)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+		return fmt.Errorf("failed to create time_off table: %w", err)
 	}
 
-	return d.GetEntry(id)
-}
-
-func (d *Database) CreateManualEntry(projectName, description string, startTime, endTime time.Time, hourlyRate *float64, milestoneName *string) (*TimeEntry, error) {
-	var rate sql.NullFloat64
-	if hourlyRate != nil {
-		rate = sql.NullFloat64{Float64: *hourlyRate, Valid: true}
+	_, err = db.Exec(`ALTER TABLE time_entries ADD COLUMN hourly_rate REAL`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add hourly_rate column: %w", err)
 	}
 
-	var milestone sql.NullString
-	if milestoneName != nil {
-		milestone = sql.NullString{String: *milestoneName, Valid: true}
+	_, err = db.Exec(`ALTER TABLE time_entries ADD COLUMN milestone_name TEXT`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add milestone_name column: %w", err)
 	}
 
-	result, err := d.db.Exec(
-		"INSERT INTO time_entries (project_name, start_time, end_time, description, hourly_rate, milestone_name) VALUES (?, ?, ?, ?, ?, ?)",
-		projectName,
-		startTime,
-		endTime,
-		description,
-		rate,
-		milestone,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to create manual entry: %w", err)
+	_, err = db.Exec(`ALTER TABLE time_entries ADD COLUMN deleted_at DATETIME`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add deleted_at column: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	_, err = db.Exec(`ALTER TABLE milestones ADD COLUMN deleted_at DATETIME`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add milestones deleted_at column: %w", err)
 	}
 
-	return d.GetEntry(id)
-}
-
-func (d *Database) GetRunningEntry() (*TimeEntry, error) {
-	var entry TimeEntry
-	var endTime sql.NullTime
-	var hourlyRate sql.NullFloat64
-	var milestoneName sql.NullString
-
-	err := d.db.QueryRow(`
-		SELECT id, project_name, start_time, end_time, description, hourly_rate, milestone_name
-		FROM time_entries
-		WHERE end_time IS NULL
-		ORDER BY start_time DESC
-		LIMIT 1
-	`).Scan(&entry.ID, &entry.ProjectName, &entry.StartTime, &endTime, &entry.Description, &hourlyRate, &milestoneName)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
+	_, err = db.Exec(`ALTER TABLE milestones ADD COLUMN budget_hours REAL`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add budget_hours column: %w", err)
 	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to get running entry: %w", err)
+	_, err = db.Exec(`ALTER TABLE milestones ADD COLUMN budget_amount REAL`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add budget_amount column: %w", err)
 	}
 
-	if endTime.Valid {
-		entry.EndTime = &endTime.Time
+	_, err = db.Exec(`ALTER TABLE milestones ADD COLUMN due_date DATETIME`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add due_date column: %w", err)
 	}
 
-	if hourlyRate.Valid {
-		entry.HourlyRate = &hourlyRate.Float64
+	_, err = db.Exec(`ALTER TABLE milestones ADD COLUMN parent_id INTEGER`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add parent_id column: %w", err)
 	}
 
-	if milestoneName.Valid {
-		entry.MilestoneName = &milestoneName.String
+	_, err = db.Exec(`ALTER TABLE milestones ADD COLUMN deleted_links TEXT`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add deleted_links column: %w", err)
 	}
 
-	return &entry, nil
-}
-
-func (d *Database) GetLastStoppedEntry() (*TimeEntry, error) {
-	var entry TimeEntry
-	var endTime sql.NullTime
-	var hourlyRate sql.NullFloat64
-	var milestoneName sql.NullString
-
-	err := d.db.QueryRow(`
-		SELECT id, project_name, start_time, end_time, description, hourly_rate, milestone_name
-		FROM time_entries
-		WHERE end_time IS NOT NULL
-		ORDER BY start_time DESC
-		LIMIT 1
-	`).Scan(&entry.ID, &entry.ProjectName, &entry.StartTime, &endTime, &entry.Description, &hourlyRate, &milestoneName)
+	_, err = db.Exec(`ALTER TABLE time_entries ADD COLUMN source TEXT`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add source column: %w", err)
+	}
 
-	if err == sql.ErrNoRows {
-		return nil, nil
+	_, err = db.Exec(`ALTER TABLE time_entries ADD COLUMN created_at DATETIME`)
+	if err != nil && !isColumnExistsError(err) {
+		return fmt.Errorf("failed to add created_at column: %w", err)
 	}
 
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_time_entries_milestone ON time_entries(milestone_name)`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get last stopped entry: %w", err)
+		return fmt.Errorf("failed to create index: %w", err)
 	}
 
-	if endTime.Valid {
-		entry.EndTime = &endTime.Time
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_milestones_project_active ON milestones(project_name, end_time)`)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
 	}
 
-	if hourlyRate.Valid {
-		entry.HourlyRate = &hourlyRate.Float64
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_entry_edits_entry ON entry_edits(entry_id)`)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
 	}
 
-	if milestoneName.Valid {
-		entry.MilestoneName = &milestoneName.String
+	_, err = db.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion))
+	if err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
 	}
 
-	return &entry, nil
+	return nil
 }
 
-func (d *Database) StopEntry(id int64) error {
-	_, err := d.db.Exec(
-		"UPDATE time_entries SET end_time = ? WHERE id = ?",
-		time.Now(),
-		id,
-	)
-
-	if(err != nil) {
-		return fmt.Errorf("failed to stop entry: %w", err)
+func isColumnExistsError(err error) bool {
+	if err == nil {
+		return false
 	}
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "duplicate column name") ||
+		strings.Contains(errMsg, "duplicate column")
+}
 
-	return nil
+// entryColumns lists the time_entries columns in the order scanEntry expects,
+// followed by whether the entry has any recorded edits.
+const entryColumns = "id, project_name, start_time, end_time, description, hourly_rate, milestone_name, deleted_at, source, created_at, " +
+	"EXISTS (SELECT 1 FROM entry_edits WHERE entry_edits.entry_id = time_entries.id)"
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
 }
 
-func (d *Database) GetEntry(id int64) (*TimeEntry, error) {
+func scanEntry(row rowScanner) (*TimeEntry, error) {
 	var entry TimeEntry
 	var endTime sql.NullTime
 	var hourlyRate sql.NullFloat64
 	var milestoneName sql.NullString
+	var deletedAt sql.NullTime
+	var source sql.NullString
+	var createdAt sql.NullTime
 
-	err := d.db.QueryRow(`
-		SELECT id, project_name, start_time, end_time, description, hourly_rate, milestone_name
-		FROM time_entries
-		WHERE id = ?
-	`, id).Scan(&entry.ID, &entry.ProjectName, &entry.StartTime, &endTime, &entry.Description, &hourlyRate, &milestoneName)
-
+	err := row.Scan(&entry.ID, &entry.ProjectName, &entry.StartTime, &endTime, &entry.Description, &hourlyRate, &milestoneName, &deletedAt, &source, &createdAt, &entry.Edited)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get entry: %w", err)
+		return nil, err
 	}
 
 	if endTime.Valid {
@@ -283,152 +306,400 @@ func (d *Database) GetEntry(id int64) (*TimeEntry, error) {
 		entry.MilestoneName = &milestoneName.String
 	}
 
-	return &entry, nil
-}
+	if deletedAt.Valid {
+		entry.DeletedAt = &deletedAt.Time
+	}
 
-func (d *Database) GetEntries(limit int) ([]*TimeEntry, error) {
-	query := `
-		SELECT id, project_name, start_time, end_time, description, hourly_rate, milestone_name
-		FROM time_entries
-		ORDER BY start_time DESC
-	`
+	entry.Source = source.String
 
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", limit)
+	if createdAt.Valid {
+		entry.CreatedAt = &createdAt.Time
 	}
 
-	rows, err := d.db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query entries: %w", err)
-	}
+	return &entry, nil
+}
 
+// collectEntries scans every row and closes rows when done.
+func collectEntries(rows *sql.Rows) ([]*TimeEntry, error) {
 	defer rows.Close()
 
 	var entries []*TimeEntry
 
 	for rows.Next() {
-		var entry TimeEntry
-		var endTime sql.NullTime
-		var hourlyRate sql.NullFloat64
-		var milestoneName sql.NullString
-
-		err := rows.Scan(&entry.ID, &entry.ProjectName, &entry.StartTime, &endTime, &entry.Description, &hourlyRate, &milestoneName)
+		entry, err := scanEntry(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan entry: %w", err)
 		}
 
-		if endTime.Valid {
-			entry.EndTime = &endTime.Time
-		}
-
-		if hourlyRate.Valid {
-			entry.HourlyRate = &hourlyRate.Float64
-		}
-
-		if milestoneName.Valid {
-			entry.MilestoneName = &milestoneName.String
-		}
+		entries = append(entries, entry)
+	}
 
-		entries = append(entries, &entry)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read entries: %w", err)
 	}
 
 	return entries, nil
 }
 
-func (d *Database) GetEntriesByProject(projectName string) ([]*TimeEntry, error) {
-	rows, err := d.db.Query(`
-		SELECT id, project_name, start_time, end_time, description, hourly_rate, milestone_name
-		FROM time_entries
-		WHERE project_name = ?
-		ORDER BY start_time DESC
-	`, projectName)
+func (d *Database) CreateEntry(projectName, description string, hourlyRate *float64, milestoneName *string) (*TimeEntry, error) {
+	return d.createEntry(SourceTimer, projectName, description, hourlyRate, milestoneName)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to query entries: %w", err)
-	}
+// ResumeEntry starts a new timer continuing a previous entry's project,
+// description, rate and milestone.
+func (d *Database) ResumeEntry(previous *TimeEntry) (*TimeEntry, error) {
+	return d.createEntry(SourceResume, previous.ProjectName, previous.Description, previous.HourlyRate, previous.MilestoneName)
+}
 
-	defer rows.Close()
+func (d *Database) createEntry(source, projectName, description string, hourlyRate *float64, milestoneName *string) (*TimeEntry, error) {
+	var rate sql.NullFloat64
+	if hourlyRate != nil {
+		rate = sql.NullFloat64{Float64: *hourlyRate, Valid: true}
+	}
 
-	var entries []*TimeEntry
+	var milestone sql.NullString
+	if milestoneName != nil {
+		milestone = sql.NullString{String: *milestoneName, Valid: true}
+	}
 
-	for rows.Next() {
-		var entry TimeEntry
-		var endTime sql.NullTime
-		var hourlyRate sql.NullFloat64
-		var milestoneName sql.NullString
+	var id int64
+	err := d.record(OpStart, func(j *journal) (string, error) {
+		now := time.Now()
+		result, err := j.tx.Exec(
+			"INSERT INTO time_entries (project_name, start_time, description, hourly_rate, milestone_name, source, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			projectName,
+			now,
+			description,
+			rate,
+			milestone,
+			source,
+			now,
+		)
 
-		err := rows.Scan(&entry.ID, &entry.ProjectName, &entry.StartTime, &endTime, &entry.Description, &hourlyRate, &milestoneName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan entry: %w", err)
+			return "", fmt.Errorf("failed to create entry: %w", err)
 		}
 
-		if endTime.Valid {
-			entry.EndTime = &endTime.Time
-		}
+		id, err = result.LastInsertId()
 
-		if hourlyRate.Valid {
-			entry.HourlyRate = &hourlyRate.Float64
+		if err != nil {
+			return "", fmt.Errorf("failed to get last insert id: %w", err)
 		}
 
-		if milestoneName.Valid {
-			entry.MilestoneName = &milestoneName.String
-		}
+		j.created(tableEntries, id)
+		return fmt.Sprintf("Started tracking %s", projectName), nil
+	})
 
-		entries = append(entries, &entry)
+	if err != nil {
+		return nil, err
 	}
 
-	return entries, nil
+	return d.GetEntry(id)
 }
 
-func (d *Database) GetEntriesByDateRange(start, end time.Time) ([]*TimeEntry, error) {
-	rows, err := d.db.Query(`
-		SELECT id, project_name, start_time, end_time, description, hourly_rate, milestone_name
-		FROM time_entries
-		WHERE start_time BETWEEN ? AND ?
-		ORDER BY start_time DESC
-	`, start, end)
+func (d *Database) CreateManualEntry(projectName, description string, startTime, endTime time.Time, hourlyRate *float64, milestoneName *string) (*TimeEntry, error) {
+	return d.createManualEntry(projectName, description, startTime, endTime, hourlyRate, milestoneName, false)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to query entries: %w", err)
-	}
+// CreateManualEntryCarving creates a manual entry after trimming or splitting
+// the entries it overlaps, as CarveOut does, in a single operation.
+func (d *Database) CreateManualEntryCarving(projectName, description string, startTime, endTime time.Time, hourlyRate *float64, milestoneName *string) (*TimeEntry, error) {
+	return d.createManualEntry(projectName, description, startTime, endTime, hourlyRate, milestoneName, true)
+}
 
-	defer rows.Close()
+func (d *Database) createManualEntry(projectName, description string, startTime, endTime time.Time, hourlyRate *float64, milestoneName *string, carve bool) (*TimeEntry, error) {
+	if err := d.snapshotBeforeCarving(carve); err != nil {
+		return nil, err
+	}
 
-	var entries []*TimeEntry
+	entry := &TimeEntry{
+		ProjectName:   projectName,
+		StartTime:     startTime,
+		EndTime:       &endTime,
+		Description:   description,
+		HourlyRate:    hourlyRate,
+		MilestoneName: milestoneName,
+	}
 
-	for rows.Next() {
-		var entry TimeEntry
-		var endTime sql.NullTime
-		var hourlyRate sql.NullFloat64
-		var milestoneName sql.NullString
+	var id int64
+	err := d.record(OpManual, func(j *journal) (string, error) {
+		if carve {
+			if _, err := carveOverlaps(j, startTime, endTime, 0); err != nil {
+				return "", err
+			}
+		} else if err := d.checkOverlapsIn(j.tx, startTime, &endTime); err != nil {
+			return "", err
+		}
 
-		err := rows.Scan(&entry.ID, &entry.ProjectName, &entry.StartTime, &endTime, &entry.Description, &hourlyRate, &milestoneName)
+		var err error
+		id, err = insertCompletedEntry(j, entry, SourceManual)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan entry: %w", err)
+			return "", err
 		}
 
-		if endTime.Valid {
-			entry.EndTime = &endTime.Time
-		}
+		j.created(tableEntries, id)
+		return fmt.Sprintf("Added manual entry #%d for %s", id, projectName), nil
+	})
 
-		if hourlyRate.Valid {
-			entry.HourlyRate = &hourlyRate.Float64
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		if milestoneName.Valid {
-			entry.MilestoneName = &milestoneName.String
-		}
+	return d.GetEntry(id)
+}
 
-		entries = append(entries, &entry)
+// ImportEntries creates completed entries in a single operation, so undo
+// removes the whole batch. Each entry must have an end time.
+func (d *Database) ImportEntries(entries []*TimeEntry) ([]*TimeEntry, error) {
+	for _, entry := range entries {
+		if err := d.checkOverlaps(entry.StartTime, entry.EndTime, 0); err != nil {
+			return nil, err
+		}
 	}
 
-	return entries, nil
-}
+	ids := make([]int64, 0, len(entries))
+	err := d.record(OpImport, func(j *journal) (string, error) {
+		for _, entry := range entries {
+			id, err := insertCompletedEntry(j, entry, SourceImport)
+			if err != nil {
+				return "", err
+			}
+
+			j.created(tableEntries, id)
+			ids = append(ids, id)
+		}
+
+		return fmt.Sprintf("Imported %d entries", len(entries)), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	imported := make([]*TimeEntry, 0, len(ids))
+	for _, id := range ids {
+		entry, err := d.GetEntry(id)
+		if err != nil {
+			return nil, err
+		}
+		imported = append(imported, entry)
+	}
+
+	return imported, nil
+}
+
+// insertCompletedEntry inserts a finished entry inside a journaled operation
+// and returns its ID.
+func insertCompletedEntry(j *journal, entry *TimeEntry, source string) (int64, error) {
+	if entry.EndTime == nil {
+		return 0, fmt.Errorf("entry for %s has no end time", entry.ProjectName)
+	}
+
+	var rate sql.NullFloat64
+	if entry.HourlyRate != nil {
+		rate = sql.NullFloat64{Float64: *entry.HourlyRate, Valid: true}
+	}
+
+	var milestone sql.NullString
+	if entry.MilestoneName != nil {
+		milestone = sql.NullString{String: *entry.MilestoneName, Valid: true}
+	}
+
+	result, err := j.tx.Exec(
+		"INSERT INTO time_entries (project_name, start_time, end_time, description, hourly_rate, milestone_name, source, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		entry.ProjectName,
+		entry.StartTime,
+		*entry.EndTime,
+		entry.Description,
+		rate,
+		milestone,
+		source,
+		time.Now(),
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to create manual entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return id, nil
+}
+
+func (d *Database) GetRunningEntry() (*TimeEntry, error) {
+	entry, err := scanEntry(d.db.QueryRow(`
+		SELECT ` + entryColumns + `
+		FROM time_entries
+		WHERE deleted_at IS NULL AND end_time IS NULL
+		ORDER BY start_time DESC
+		LIMIT 1
+	`))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get running entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (d *Database) GetLastStoppedEntry() (*TimeEntry, error) {
+	entry, err := scanEntry(d.db.QueryRow(`
+		SELECT ` + entryColumns + `
+		FROM time_entries
+		WHERE deleted_at IS NULL AND end_time IS NOT NULL
+		ORDER BY start_time DESC
+		LIMIT 1
+	`))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last stopped entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (d *Database) StopEntry(id int64) error {
+	return d.record(OpStop, func(j *journal) (string, error) {
+		before, err := j.track(tableEntries, id)
+		if err != nil {
+			return "", err
+		}
+
+		_, err = j.tx.Exec(
+			"UPDATE time_entries SET end_time = ? WHERE id = ?",
+			time.Now(),
+			id,
+		)
+
+		if err != nil {
+			return "", fmt.Errorf("failed to stop entry: %w", err)
+		}
+
+		return fmt.Sprintf("Stopped tracking %s", before.text("project_name")), nil
+	})
+}
+
+func (d *Database) GetEntry(id int64) (*TimeEntry, error) {
+	entry, err := scanEntry(d.db.QueryRow(`
+		SELECT `+entryColumns+`
+		FROM time_entries
+		WHERE id = ? AND deleted_at IS NULL
+	`, id))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (d *Database) GetEntries(limit int) ([]*TimeEntry, error) {
+	return d.GetEntriesByFilter(EntryFilter{Limit: limit})
+}
+
+func (d *Database) GetEntriesByProject(projectName string) ([]*TimeEntry, error) {
+	return d.GetEntriesByFilter(EntryFilter{ProjectName: projectName})
+}
+
+func (d *Database) GetEntriesByDateRange(start, end time.Time) ([]*TimeEntry, error) {
+	rows, err := d.db.Query(`
+		SELECT `+entryColumns+`
+		FROM time_entries
+		WHERE deleted_at IS NULL AND start_time BETWEEN ? AND ?
+		ORDER BY start_time DESC
+	`, start, end)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entries: %w", err)
+	}
+
+	return collectEntries(rows)
+}
+
+// GetEntriesByFilter returns every entry matching the filter, newest first.
+func (d *Database) GetEntriesByFilter(filter EntryFilter) ([]*TimeEntry, error) {
+	var entries []*TimeEntry
+
+	err := d.StreamEntries(filter, func(entry *TimeEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// StreamEntries calls fn for each entry matching the filter while reading
+// rows from the database cursor, so callers never hold the full result set
+// in memory. Returning an error from fn stops the iteration.
+func (d *Database) StreamEntries(filter EntryFilter, fn func(*TimeEntry) error) error {
+	where, args := filter.where()
+
+	query := "SELECT " + entryColumns + " FROM time_entries" + where + " ORDER BY start_time DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query entries: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan entry: %w", err)
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read entries: %w", err)
+	}
+
+	return nil
+}
+
+// CountEntries returns the number of entries matching the filter.
+func (d *Database) CountEntries(filter EntryFilter) (int, error) {
+	where, args := filter.where()
+
+	var count int
+	if err := d.db.QueryRow("SELECT COUNT(*) FROM time_entries"+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count entries: %w", err)
+	}
+
+	if filter.Limit > 0 && count > filter.Limit {
+		count = filter.Limit
+	}
+
+	return count, nil
+}
 
 func (d *Database) GetAllProjects() ([]string, error) {
 	rows, err := d.db.Query(`
 		SELECT DISTINCT project_name
 		FROM time_entries
+		WHERE deleted_at IS NULL
 		ORDER BY project_name
 	`)
 
@@ -456,7 +727,7 @@ func (d *Database) GetProjectsWithCompletedEntries() ([]string, error) {
 	rows, err := d.db.Query(`
 		SELECT DISTINCT project_name
 		FROM time_entries
-		WHERE end_time IS NOT NULL
+		WHERE deleted_at IS NULL AND end_time IS NOT NULL
 		ORDER BY project_name
 	`)
 
@@ -482,9 +753,9 @@ func (d *Database) GetProjectsWithCompletedEntries() ([]string, error) {
 
 func (d *Database) GetCompletedEntriesByProject(projectName string) ([]*TimeEntry, error) {
 	rows, err := d.db.Query(`
-		SELECT id, project_name, start_time, end_time, description, hourly_rate, milestone_name
+		SELECT `+entryColumns+`
 		FROM time_entries
-		WHERE project_name = ? AND end_time IS NOT NULL
+		WHERE project_name = ? AND end_time IS NOT NULL AND deleted_at IS NULL
 		ORDER BY start_time DESC
 	`, projectName)
 
@@ -492,40 +763,28 @@ func (d *Database) GetCompletedEntriesByProject(projectName string) ([]*TimeEntr
 		return nil, fmt.Errorf("failed to query entries: %w", err)
 	}
 
-	defer rows.Close()
-
-	var entries []*TimeEntry
-
-	for rows.Next() {
-		var entry TimeEntry
-		var endTime sql.NullTime
-		var hourlyRate sql.NullFloat64
-		var milestoneName sql.NullString
-
-		err := rows.Scan(&entry.ID, &entry.ProjectName, &entry.StartTime, &endTime, &entry.Description, &hourlyRate, &milestoneName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan entry: %w", err)
-		}
-
-		if endTime.Valid {
-			entry.EndTime = &endTime.Time
-		}
-
-		if hourlyRate.Valid {
-			entry.HourlyRate = &hourlyRate.Float64
-		}
+	return collectEntries(rows)
+}
 
-		if milestoneName.Valid {
-			entry.MilestoneName = &milestoneName.String
-		}
+func (d *Database) UpdateTimeEntry(id int64, entry *TimeEntry) error {
+	return d.updateTimeEntry(id, entry, false)
+}
 
-		entries = append(entries, &entry)
+// UpdateTimeEntryCarving saves an edited, finished entry after trimming or
+// splitting the other entries it overlaps, as CarveOut does, in a single
+// operation.
+func (d *Database) UpdateTimeEntryCarving(id int64, entry *TimeEntry) error {
+	if entry.EndTime == nil {
+		return fmt.Errorf("entry #%d is running, only finished entries can be carved around", id)
 	}
-
-	return entries, nil
+	return d.updateTimeEntry(id, entry, true)
 }
 
-func (d *Database) UpdateTimeEntry(id int64, entry *TimeEntry) error {
+func (d *Database) updateTimeEntry(id int64, entry *TimeEntry, carve bool) error {
+	if err := d.snapshotBeforeCarving(carve); err != nil {
+		return err
+	}
+
 	var endTime sql.NullTime
 	if entry.EndTime != nil {
 		endTime = sql.NullTime{Time: *entry.EndTime, Valid: true}
@@ -541,104 +800,228 @@ func (d *Database) UpdateTimeEntry(id int64, entry *TimeEntry) error {
 		milestoneName = sql.NullString{String: *entry.MilestoneName, Valid: true}
 	}
 
-	_, err := d.db.Exec(`
-		UPDATE time_entries
-		SET project_name = ?, start_time = ?, end_time = ?, description = ?, hourly_rate = ?, milestone_name = ?
-		WHERE id = ?
-	`, entry.ProjectName, entry.StartTime, endTime, entry.Description, hourlyRate, milestoneName, id)
+	return d.record(OpEdit, func(j *journal) (string, error) {
+		if _, err := j.track(tableEntries, id); err != nil {
+			return "", err
+		}
 
-	if err != nil {
-		return fmt.Errorf("failed to update entry: %w", err)
-	}
+		if carve {
+			if _, err := carveOverlaps(j, entry.StartTime, *entry.EndTime, id); err != nil {
+				return "", err
+			}
+		} else if err := d.checkOverlapsIn(j.tx, entry.StartTime, entry.EndTime, id); err != nil {
+			return "", err
+		}
 
-	return nil
+		_, err := j.tx.Exec(`
+			UPDATE time_entries
+			SET project_name = ?, start_time = ?, end_time = ?, description = ?, hourly_rate = ?, milestone_name = ?
+			WHERE id = ?
+		`, entry.ProjectName, entry.StartTime, endTime, entry.Description, hourlyRate, milestoneName, id)
+
+		if err != nil {
+			return "", fmt.Errorf("failed to update entry: %w", err)
+		}
+
+		return fmt.Sprintf("Edited entry #%d in %s", id, entry.ProjectName), nil
+	})
 }
 
+// DeleteTimeEntry moves an entry to the trash, after taking a snapshot.
 func (d *Database) DeleteTimeEntry(id int64) error {
-	_, err := d.db.Exec("DELETE FROM time_entries WHERE id = ?", id)
-	if err != nil {
-		return fmt.Errorf("failed to delete entry: %w", err)
+	if _, err := d.Snapshot("delete"); err != nil {
+		return err
 	}
-	return nil
+
+	return d.record(OpDelete, func(j *journal) (string, error) {
+		before, err := j.track(tableEntries, id)
+		if err != nil {
+			return "", err
+		}
+
+		if err := trashEntry(j, id); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Deleted entry #%d from %s", id, before.text("project_name")), nil
+	})
+}
+
+// DeleteTimeEntries moves several entries to the trash as one operation, so
+// a single undo brings them all back. A snapshot is taken first.
+func (d *Database) DeleteTimeEntries(ids []int64) error {
+	if _, err := d.Snapshot("delete"); err != nil {
+		return err
+	}
+
+	return d.record(OpDelete, func(j *journal) (string, error) {
+		var before rowImage
+		for _, id := range ids {
+			image, err := j.track(tableEntries, id)
+			if err != nil {
+				return "", err
+			}
+			before = image
+
+			if err := trashEntry(j, id); err != nil {
+				return "", err
+			}
+		}
+
+		if len(ids) == 1 {
+			return fmt.Sprintf("Deleted entry #%d from %s", ids[0], before.text("project_name")), nil
+		}
+		return fmt.Sprintf("Deleted %d entries", len(ids)), nil
+	})
 }
 
 func (d *Database) CreateMilestone(projectName, name string) (*Milestone, error) {
-	result, err := d.db.Exec(
-		"INSERT INTO milestones (project_name, name, start_time) VALUES (?, ?, ?)",
-		projectName,
-		name,
-		time.Now(),
-	)
+	return d.CreateMilestoneWithPlan(projectName, name, nil, MilestonePlan{})
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to create milestone: %w", err)
+// CreateMilestoneWithPlan starts a milestone with a budget and due date,
+// nested inside the active milestone parentID when it is set. Each milestone
+// can have one active child.
+func (d *Database) CreateMilestoneWithPlan(projectName, name string, parentID *int64, plan MilestonePlan) (*Milestone, error) {
+	if err := plan.validate(); err != nil {
+		return nil, err
 	}
 
-	id, err := result.LastInsertId()
+	var id int64
+	err := d.record(OpMilestoneStart, func(j *journal) (string, error) {
+		existing, err := milestoneRow(j.tx, projectName, name)
+		if err != nil {
+			return "", err
+		}
+
+		if existing != nil && existing.DeletedAt != nil {
+			return "", fmt.Errorf("a milestone named '%s' is in the trash, restore it or empty the trash first", name)
+		}
+
+		if err := checkCanActivate(j.tx, projectName, parentID, 0); err != nil {
+			return "", err
+		}
+
+		result, err := j.tx.Exec(
+			"INSERT INTO milestones (project_name, name, start_time, budget_hours, budget_amount, due_date, parent_id) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			projectName,
+			name,
+			time.Now(),
+			plan.BudgetHours,
+			plan.BudgetAmount,
+			plan.dueDate(),
+			parentID,
+		)
+
+		if err != nil {
+			return "", fmt.Errorf("failed to create milestone: %w", err)
+		}
+
+		id, err = result.LastInsertId()
+		if err != nil {
+			return "", fmt.Errorf("failed to get last insert id: %w", err)
+		}
+
+		j.created(tableMilestones, id)
+		return fmt.Sprintf("Started milestone %s in %s", name, projectName), nil
+	})
+
 	if err != nil {
-		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+		return nil, err
 	}
 
 	return d.GetMilestone(id)
 }
 
-func (d *Database) GetMilestone(id int64) (*Milestone, error) {
-	var milestone Milestone
-	var endTime sql.NullTime
-
-	err := d.db.QueryRow(
-		"SELECT id, project_name, name, start_time, end_time FROM milestones WHERE id = ?",
-		id,
-	).Scan(&milestone.ID, &milestone.ProjectName, &milestone.Name, &milestone.StartTime, &endTime)
+const milestoneColumns = "id, project_name, name, start_time, end_time, deleted_at, budget_hours, budget_amount, due_date, parent_id"
 
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+func scanMilestone(row rowScanner) (*Milestone, error) {
+	var milestone Milestone
+	var endTime, deletedAt, dueDate sql.NullTime
+	var budgetHours, budgetAmount sql.NullFloat64
+	var parentID sql.NullInt64
 
+	err := row.Scan(&milestone.ID, &milestone.ProjectName, &milestone.Name, &milestone.StartTime, &endTime, &deletedAt, &budgetHours, &budgetAmount, &dueDate, &parentID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get milestone: %w", err)
+		return nil, err
 	}
 
 	if endTime.Valid {
 		milestone.EndTime = &endTime.Time
 	}
 
+	if deletedAt.Valid {
+		milestone.DeletedAt = &deletedAt.Time
+	}
+
+	if budgetHours.Valid {
+		milestone.BudgetHours = &budgetHours.Float64
+	}
+
+	if budgetAmount.Valid {
+		milestone.BudgetAmount = &budgetAmount.Float64
+	}
+
+	if dueDate.Valid {
+		milestone.DueDate = &dueDate.Time
+	}
+
+	if parentID.Valid {
+		milestone.ParentID = &parentID.Int64
+	}
+
 	return &milestone, nil
 }
 
-func (d *Database) GetActiveMilestoneForProject(projectName string) (*Milestone, error) {
-	var milestone Milestone
-	var endTime sql.NullTime
+func collectMilestones(rows *sql.Rows) ([]*Milestone, error) {
+	defer rows.Close()
 
-	err := d.db.QueryRow(
-		"SELECT id, project_name, name, start_time, end_time FROM milestones WHERE project_name = ? AND end_time IS NULL ORDER BY start_time DESC LIMIT 1",
-		projectName,
-	).Scan(&milestone.ID, &milestone.ProjectName, &milestone.Name, &milestone.StartTime, &endTime)
+	var milestones []*Milestone
+	for rows.Next() {
+		milestone, err := scanMilestone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan milestone: %w", err)
+		}
+
+		milestones = append(milestones, milestone)
+	}
+
+	return milestones, rows.Err()
+}
+
+func (d *Database) GetMilestone(id int64) (*Milestone, error) {
+	milestone, err := scanMilestone(d.db.QueryRow(
+		"SELECT "+milestoneColumns+" FROM milestones WHERE id = ? AND deleted_at IS NULL",
+		id,
+	))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active milestone: %w", err)
+		return nil, fmt.Errorf("failed to get milestone: %w", err)
 	}
 
-	if endTime.Valid {
-		milestone.EndTime = &endTime.Time
+	return milestone, nil
+}
+
+func (d *Database) GetActiveMilestoneForProject(projectName string) (*Milestone, error) {
+	chain, err := activeMilestones(d.db, projectName)
+	if err != nil || len(chain) == 0 {
+		return nil, err
 	}
 
-	return &milestone, nil
+	// new entries are tagged with the deepest active level
+	return chain[len(chain)-1], nil
 }
 
 func (d *Database) GetMilestoneByName(projectName, milestoneName string) (*Milestone, error) {
-	var milestone Milestone
-	var endTime sql.NullTime
-
-	err := d.db.QueryRow(
-		"SELECT id, project_name, name, start_time, end_time FROM milestones WHERE project_name = ? AND name = ?",
+	milestone, err := scanMilestone(d.db.QueryRow(
+		"SELECT "+milestoneColumns+" FROM milestones WHERE project_name = ? AND name = ? AND deleted_at IS NULL",
 		projectName,
 		milestoneName,
-	).Scan(&milestone.ID, &milestone.ProjectName, &milestone.Name, &milestone.StartTime, &endTime)
+	))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -648,130 +1031,367 @@ func (d *Database) GetMilestoneByName(projectName, milestoneName string) (*Miles
 		return nil, fmt.Errorf("failed to get milestone by name: %w", err)
 	}
 
-	if endTime.Valid {
-		milestone.EndTime = &endTime.Time
-	}
-
-	return &milestone, nil
+	return milestone, nil
 }
 
 func (d *Database) GetMilestonesByProject(projectName string) ([]*Milestone, error) {
 	rows, err := d.db.Query(
-		"SELECT id, project_name, name, start_time, end_time FROM milestones WHERE project_name = ? ORDER BY start_time DESC",
+		"SELECT "+milestoneColumns+" FROM milestones WHERE project_name = ? AND deleted_at IS NULL ORDER BY start_time DESC",
 		projectName,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get milestones: %w", err)
 	}
-	defer rows.Close()
-
-	var milestones []*Milestone
-	for rows.Next() {
-		var milestone Milestone
-		var endTime sql.NullTime
 
-		err := rows.Scan(&milestone.ID, &milestone.ProjectName, &milestone.Name, &milestone.StartTime, &endTime)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan milestone: %w", err)
-		}
-
-		if endTime.Valid {
-			milestone.EndTime = &endTime.Time
-		}
-
-		milestones = append(milestones, &milestone)
-	}
-
-	return milestones, nil
+	return collectMilestones(rows)
 }
 
 func (d *Database) GetAllMilestones() ([]*Milestone, error) {
 	rows, err := d.db.Query(
-		"SELECT id, project_name, name, start_time, end_time FROM milestones ORDER BY start_time DESC",
+		"SELECT "+milestoneColumns+" FROM milestones WHERE deleted_at IS NULL ORDER BY start_time DESC",
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all milestones: %w", err)
 	}
-	defer rows.Close()
 
-	var milestones []*Milestone
-	for rows.Next() {
-		var milestone Milestone
-		var endTime sql.NullTime
+	return collectMilestones(rows)
+}
 
-		err := rows.Scan(&milestone.ID, &milestone.ProjectName, &milestone.Name, &milestone.StartTime, &endTime)
+// FinishMilestone finishes a milestone together with the active milestones
+// nested inside it.
+func (d *Database) FinishMilestone(id int64) error {
+	return d.record(OpMilestoneFinish, func(j *journal) (string, error) {
+		before, err := j.track(tableMilestones, id)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan milestone: %w", err)
+			return "", err
 		}
 
-		if endTime.Valid {
-			milestone.EndTime = &endTime.Time
+		if before == nil || before["deleted_at"] != nil {
+			return "", fmt.Errorf("milestone #%d not found", id)
 		}
 
-		milestones = append(milestones, &milestone)
-	}
+		descendants, err := activeDescendants(j.tx, before.text("project_name"), id)
+		if err != nil {
+			return "", err
+		}
 
-	return milestones, nil
-}
+		ids := []int64{id}
+		for _, m := range descendants {
+			if _, err := j.track(tableMilestones, m.ID); err != nil {
+				return "", err
+			}
+			ids = append(ids, m.ID)
+		}
 
-func (d *Database) FinishMilestone(id int64) error {
-	_, err := d.db.Exec(
-		"UPDATE milestones SET end_time = ? WHERE id = ?",
-		time.Now(),
-		id,
-	)
+		now := time.Now()
+		for _, finishID := range ids {
+			if _, err := j.tx.Exec("UPDATE milestones SET end_time = ? WHERE id = ?", now, finishID); err != nil {
+				return "", fmt.Errorf("failed to finish milestone: %w", err)
+			}
+		}
 
+		return fmt.Sprintf("Finished milestone %s in %s", before.text("name"), before.text("project_name")), nil
+	})
+}
+
+// finishMilestoneAt finishes a milestone and the active milestones nested in
+// it at end, or now for those started after that.
+func finishMilestoneAt(j *journal, m *Milestone, end, now time.Time) error {
+	descendants, err := activeDescendants(j.tx, m.ProjectName, m.ID)
 	if err != nil {
-		return fmt.Errorf("failed to finish milestone: %w", err)
+		return err
+	}
+
+	for _, finish := range append([]*Milestone{m}, descendants...) {
+		if _, err := j.track(tableMilestones, finish.ID); err != nil {
+			return err
+		}
+
+		endTime := end
+		if !endTime.After(finish.StartTime) {
+			endTime = now
+		}
+
+		if _, err := j.tx.Exec("UPDATE milestones SET end_time = ? WHERE id = ?", endTime, finish.ID); err != nil {
+			return fmt.Errorf("failed to finish milestone: %w", err)
+		}
 	}
 
 	return nil
 }
 
-func (d *Database) GetEntriesByMilestone(projectName, milestoneName string) ([]*TimeEntry, error) {
-	rows, err := d.db.Query(
-		"SELECT id, project_name, start_time, end_time, description, hourly_rate, milestone_name FROM time_entries WHERE project_name = ? AND milestone_name = ? ORDER BY start_time DESC",
-		projectName,
-		milestoneName,
-	)
+// RenameMilestone renames a milestone and retags its entries, including
+// those in the trash. It returns how many entries were retagged.
+func (d *Database) RenameMilestone(id int64, newName string) (int, error) {
+	if _, err := d.Snapshot("rename-milestone"); err != nil {
+		return 0, err
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to get entries by milestone: %w", err)
+	retagged := 0
+	err := d.record(OpMilestoneRename, func(j *journal) (string, error) {
+		before, err := j.track(tableMilestones, id)
+		if err != nil {
+			return "", err
+		}
+
+		if before == nil || before["deleted_at"] != nil {
+			return "", fmt.Errorf("milestone #%d not found", id)
+		}
+
+		projectName, oldName := before.text("project_name"), before.text("name")
+
+		existing, err := milestoneRow(j.tx, projectName, newName)
+		if err != nil {
+			return "", err
+		}
+
+		if existing != nil {
+			if existing.DeletedAt != nil {
+				return "", fmt.Errorf("a milestone named '%s' is in the trash, empty the trash first", newName)
+			}
+			return "", fmt.Errorf("milestone '%s' already exists in %s", newName, projectName)
+		}
+
+		if _, err := j.tx.Exec("UPDATE milestones SET name = ? WHERE id = ?", newName, id); err != nil {
+			return "", fmt.Errorf("failed to rename milestone: %w", err)
+		}
+
+		ids, err := milestoneEntryIDs(j.tx, projectName, oldName, true)
+		if err != nil {
+			return "", err
+		}
+
+		for _, entryID := range ids {
+			if _, err := j.track(tableEntries, entryID); err != nil {
+				return "", err
+			}
+
+			if _, err := j.tx.Exec("UPDATE time_entries SET milestone_name = ? WHERE id = ?", newName, entryID); err != nil {
+				return "", fmt.Errorf("failed to retag entry: %w", err)
+			}
+		}
+
+		retagged = len(ids)
+		return fmt.Sprintf("Renamed milestone %s to %s in %s", oldName, newName, projectName), nil
+	})
+
+	return retagged, err
+}
+
+// ReopenMilestone makes a finished milestone active again. Its parent must
+// be active and have no other active child.
+func (d *Database) ReopenMilestone(id int64) error {
+	return d.record(OpMilestoneReopen, func(j *journal) (string, error) {
+		before, err := j.track(tableMilestones, id)
+		if err != nil {
+			return "", err
+		}
+
+		if before == nil || before["deleted_at"] != nil {
+			return "", fmt.Errorf("milestone #%d not found", id)
+		}
+
+		projectName, name := before.text("project_name"), before.text("name")
+		if before["end_time"] == nil {
+			return "", fmt.Errorf("milestone '%s' is already active", name)
+		}
+
+		milestone, err := liveMilestone(j.tx, id)
+		if err != nil {
+			return "", err
+		}
+
+		if err := checkCanActivate(j.tx, projectName, milestone.ParentID, id); err != nil {
+			return "", err
+		}
+
+		if _, err := j.tx.Exec("UPDATE milestones SET end_time = NULL WHERE id = ?", id); err != nil {
+			return "", fmt.Errorf("failed to reopen milestone: %w", err)
+		}
+
+		return fmt.Sprintf("Reopened milestone %s in %s", name, projectName), nil
+	})
+}
+
+// UpdateMilestoneDates sets when a milestone started and finished. A nil end
+// makes the milestone active. Ending an active milestone finishes the active
+// milestones nested in it too, as FinishMilestone does.
+func (d *Database) UpdateMilestoneDates(id int64, start time.Time, end *time.Time) error {
+	if end != nil && !end.After(start) {
+		return fmt.Errorf("milestone must end after it starts")
 	}
-	defer rows.Close()
 
-	var entries []*TimeEntry
-	for rows.Next() {
-		var entry TimeEntry
-		var endTime sql.NullTime
-		var hourlyRate sql.NullFloat64
-		var milestoneName sql.NullString
+	var endTime sql.NullTime
+	if end != nil {
+		endTime = sql.NullTime{Time: *end, Valid: true}
+	}
 
-		err := rows.Scan(&entry.ID, &entry.ProjectName, &entry.StartTime, &endTime, &entry.Description, &hourlyRate, &milestoneName)
+	return d.record(OpMilestoneEdit, func(j *journal) (string, error) {
+		before, err := j.track(tableMilestones, id)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan entry: %w", err)
+			return "", err
+		}
+
+		if before == nil || before["deleted_at"] != nil {
+			return "", fmt.Errorf("milestone #%d not found", id)
+		}
+
+		projectName := before.text("project_name")
+		wasActive := before["end_time"] == nil
+
+		if end == nil && !wasActive {
+			milestone, err := liveMilestone(j.tx, id)
+			if err != nil {
+				return "", err
+			}
+
+			if err := checkCanActivate(j.tx, projectName, milestone.ParentID, id); err != nil {
+				return "", err
+			}
+		}
+
+		if end != nil && wasActive {
+			if err := setMilestoneStart(j, id, start); err != nil {
+				return "", err
+			}
+
+			milestone, err := liveMilestone(j.tx, id)
+			if err != nil {
+				return "", err
+			}
+
+			if err := finishMilestoneAt(j, milestone, *end, time.Now()); err != nil {
+				return "", err
+			}
+		} else if _, err := j.tx.Exec("UPDATE milestones SET start_time = ?, end_time = ? WHERE id = ?", start, endTime, id); err != nil {
+			return "", fmt.Errorf("failed to update milestone: %w", err)
+		}
+
+		return fmt.Sprintf("Edited dates of milestone %s in %s", before.text("name"), projectName), nil
+	})
+}
+
+// DeleteMilestone moves a milestone to the trash. Its entries are untagged,
+// or moved to the trash as well when withEntries is set, and its nested
+// milestones move up a level. What was changed is kept with the milestone so
+// RestoreMilestone can put it back. It returns how many entries were changed.
+func (d *Database) DeleteMilestone(id int64, withEntries bool) (int, error) {
+	if _, err := d.Snapshot("delete-milestone"); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	err := d.record(OpMilestoneDelete, func(j *journal) (string, error) {
+		before, err := j.track(tableMilestones, id)
+		if err != nil {
+			return "", err
+		}
+
+		if before == nil || before["deleted_at"] != nil {
+			return "", fmt.Errorf("milestone #%d not found", id)
+		}
+
+		projectName, name := before.text("project_name"), before.text("name")
+
+		if _, err := j.tx.Exec("UPDATE milestones SET deleted_at = ? WHERE id = ?", time.Now(), id); err != nil {
+			return "", fmt.Errorf("failed to delete milestone: %w", err)
+		}
+
+		// nested milestones move up a level
+		children, err := childMilestoneIDs(j.tx, id)
+		if err != nil {
+			return "", err
+		}
+
+		links := deletedLinks{Children: children}
+
+		for _, childID := range children {
+			if _, err := j.track(tableMilestones, childID); err != nil {
+				return "", err
+			}
+
+			if _, err := j.tx.Exec("UPDATE milestones SET parent_id = ? WHERE id = ?", before["parent_id"], childID); err != nil {
+				return "", fmt.Errorf("failed to update nested milestone: %w", err)
+			}
+		}
+
+		// Entries already in the trash keep their tag, like they keep their project
+		ids, err := milestoneEntryIDs(j.tx, projectName, name, false)
+		if err != nil {
+			return "", err
 		}
 
-		if endTime.Valid {
-			entry.EndTime = &endTime.Time
+		for _, entryID := range ids {
+			if _, err := j.track(tableEntries, entryID); err != nil {
+				return "", err
+			}
+
+			if withEntries {
+				err = trashEntry(j, entryID)
+				links.Trashed = append(links.Trashed, entryID)
+			} else {
+				_, err = j.tx.Exec("UPDATE time_entries SET milestone_name = NULL WHERE id = ?", entryID)
+				links.Untagged = append(links.Untagged, entryID)
+			}
+
+			if err != nil {
+				return "", fmt.Errorf("failed to update milestone entry: %w", err)
+			}
 		}
 
-		if hourlyRate.Valid {
-			entry.HourlyRate = &hourlyRate.Float64
+		if err := saveDeletedLinks(j, id, links); err != nil {
+			return "", err
 		}
 
-		if milestoneName.Valid {
-			entry.MilestoneName = &milestoneName.String
+		changed = len(ids)
+		return fmt.Sprintf("Deleted milestone %s from %s", name, projectName), nil
+	})
+
+	return changed, err
+}
+
+// milestoneEntryIDs returns the IDs of a milestone's entries, including
+// those in the trash when withTrashed is set.
+func milestoneEntryIDs(q querier, projectName, milestoneName string, withTrashed bool) ([]int64, error) {
+	query := "SELECT id FROM time_entries WHERE project_name = ? AND milestone_name = ?"
+	if !withTrashed {
+		query += " AND deleted_at IS NULL"
+	}
+
+	rows, err := q.Query(query, projectName, milestoneName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query milestone entries: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan entry: %w", err)
 		}
+		ids = append(ids, id)
+	}
 
-		entries = append(entries, &entry)
+	return ids, rows.Err()
+}
+
+func (d *Database) GetEntriesByMilestone(projectName, milestoneName string) ([]*TimeEntry, error) {
+	rows, err := d.db.Query(
+		"SELECT "+entryColumns+" FROM time_entries WHERE project_name = ? AND milestone_name = ? AND deleted_at IS NULL ORDER BY start_time DESC",
+		projectName,
+		milestoneName,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries by milestone: %w", err)
 	}
 
-	return entries, nil
+	return collectEntries(rows)
 }
 
 func (d *Database) Close() error {
 	return d.db.Close()
-}
\ No newline at end of file
+}
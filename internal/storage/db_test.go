@@ -14,30 +14,9 @@ func setupTestDB(t *testing.T) *Database {
 	db, err := sql.Open("sqlite", ":memory:")
 	assert.NoError(t, err)
 
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS time_entries (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			project_name TEXT NOT NULL,
-			start_time DATETIME NOT NULL,
-			end_time DATETIME,
-			description TEXT,
-			hourly_rate REAL,
-			milestone_name TEXT
-		)
-	`)
-	assert.NoError(t, err)
-
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS milestones (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			project_name TEXT NOT NULL,
-			name TEXT NOT NULL,
-			start_time DATETIME NOT NULL,
-			end_time DATETIME,
-			UNIQUE(project_name, name)
-		)
-	`)
-	assert.NoError(t, err)
+	// each connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	assert.NoError(t, migrate(db))
 
 	return &Database{db: db}
 }
@@ -113,6 +92,57 @@ func TestCreateManualEntry(t *testing.T) {
 	assert.Equal(t, rate, *entry.HourlyRate)
 }
 
+func TestImportEntries(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	firstEnd := base.Add(time.Hour)
+	secondEnd := base.Add(3 * time.Hour)
+	milestone := "Sprint 1"
+
+	imported, err := db.ImportEntries([]*TimeEntry{
+		{ProjectName: "p", StartTime: base, EndTime: &firstEnd, Description: "first"},
+		{ProjectName: "p", StartTime: base.Add(2 * time.Hour), EndTime: &secondEnd, MilestoneName: &milestone, HourlyRate: floatPtr(80)},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, imported, 2)
+	assert.Equal(t, SourceImport, imported[0].Source)
+	assert.Equal(t, "first", imported[0].Description)
+	assert.Equal(t, "Sprint 1", *imported[1].MilestoneName)
+	assert.Equal(t, 80.0, *imported[1].HourlyRate)
+
+	t.Run("undo removes the whole batch", func(t *testing.T) {
+		_, err := db.Undo()
+		assert.NoError(t, err)
+
+		entries, err := db.GetEntries(0)
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("rejects overlaps before creating anything", func(t *testing.T) {
+		db.rejectOverlaps = true
+		defer func() { db.rejectOverlaps = false }()
+
+		_, err := db.CreateManualEntry("p", "", base, firstEnd, nil, nil)
+		assert.NoError(t, err)
+
+		_, err = db.ImportEntries([]*TimeEntry{
+			{ProjectName: "p", StartTime: base.Add(2 * time.Hour), EndTime: &secondEnd},
+			{ProjectName: "p", StartTime: base.Add(30 * time.Minute), EndTime: &secondEnd},
+		})
+
+		var overlapErr *OverlapError
+		assert.ErrorAs(t, err, &overlapErr)
+
+		entries, err := db.GetEntries(0)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
+
 func TestGetRunningEntry(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -314,6 +344,79 @@ func TestGetEntriesByDateRange(t *testing.T) {
 	assert.Len(t, entries, 2) // Should get "recent" and "today"
 }
 
+func TestStreamEntries(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	sprint := "Sprint 1"
+
+	_, err := db.CreateManualEntry("project-a", "monday", base, base.Add(time.Hour), nil, &sprint)
+	assert.NoError(t, err)
+	_, err = db.CreateManualEntry("project-a", "tuesday", base.AddDate(0, 0, 1), base.AddDate(0, 0, 1).Add(time.Hour), nil, nil)
+	assert.NoError(t, err)
+	_, err = db.CreateManualEntry("project-b", "wednesday", base.AddDate(0, 0, 2), base.AddDate(0, 0, 2).Add(time.Hour), nil, nil)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		filter   EntryFilter
+		expected []string
+	}{
+		{
+			name:     "empty filter matches everything newest first",
+			filter:   EntryFilter{},
+			expected: []string{"wednesday", "tuesday", "monday"},
+		},
+		{
+			name:     "filters by project",
+			filter:   EntryFilter{ProjectName: "project-a"},
+			expected: []string{"tuesday", "monday"},
+		},
+		{
+			name:     "filters by milestone",
+			filter:   EntryFilter{ProjectName: "project-a", MilestoneName: sprint},
+			expected: []string{"monday"},
+		},
+		{
+			name:     "range end is exclusive",
+			filter:   EntryFilter{Start: base, End: base.AddDate(0, 0, 2)},
+			expected: []string{"tuesday", "monday"},
+		},
+		{
+			name:     "respects limit",
+			filter:   EntryFilter{Limit: 1},
+			expected: []string{"wednesday"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var descriptions []string
+			err := db.StreamEntries(tt.filter, func(entry *TimeEntry) error {
+				descriptions = append(descriptions, entry.Description)
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, descriptions)
+
+			count, err := db.CountEntries(tt.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.expected), count)
+		})
+	}
+
+	t.Run("stops when the callback fails", func(t *testing.T) {
+		calls := 0
+		err := db.StreamEntries(EntryFilter{}, func(entry *TimeEntry) error {
+			calls++
+			return assert.AnError
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 1, calls)
+	})
+}
+
 func TestGetAllProjects(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"strings"
+	"time"
+)
+
+// EntryFilter narrows down which time entries a query returns. Zero values
+// mean "no restriction", so an empty filter matches every live entry.
+type EntryFilter struct {
+	ProjectName   string
+	MilestoneName string
+	// Start and End bound the entry start time to the half-open range [Start, End).
+	Start time.Time
+	End   time.Time
+	Limit int
+	// Deleted selects entries in the trash instead of live ones.
+	Deleted bool
+}
+
+func (f EntryFilter) where() (string, []any) {
+	conditions := []string{"deleted_at IS NULL"}
+	if f.Deleted {
+		conditions[0] = "deleted_at IS NOT NULL"
+	}
+	var args []any
+
+	if f.ProjectName != "" {
+		conditions = append(conditions, "project_name = ?")
+		args = append(args, f.ProjectName)
+	}
+
+	if f.MilestoneName != "" {
+		conditions = append(conditions, "milestone_name = ?")
+		args = append(args, f.MilestoneName)
+	}
+
+	if !f.Start.IsZero() {
+		conditions = append(conditions, "start_time >= ?")
+		args = append(args, f.Start)
+	}
+
+	if !f.End.IsZero() {
+		conditions = append(conditions, "start_time < ?")
+		args = append(args, f.End)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
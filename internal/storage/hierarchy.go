@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// MilestoneNode is a milestone with the milestones nested inside it.
+type MilestoneNode struct {
+	Milestone *Milestone
+	Parent    *MilestoneNode
+	Children  []*MilestoneNode
+	// Depth is 0 for a top-level milestone.
+	Depth int
+}
+
+// BuildMilestoneTree nests milestones under their parents, ordering siblings
+// by start time. A milestone whose parent isn't among milestones, e.g.
+// because it is in the trash, becomes a top-level milestone.
+func BuildMilestoneTree(milestones []*Milestone) []*MilestoneNode {
+	nodes := make(map[int64]*MilestoneNode, len(milestones))
+	for _, m := range milestones {
+		nodes[m.ID] = &MilestoneNode{Milestone: m}
+	}
+
+	var roots []*MilestoneNode
+	for _, m := range milestones {
+		node := nodes[m.ID]
+		if m.ParentID != nil && nodes[*m.ParentID] != nil && *m.ParentID != m.ID {
+			parent := nodes[*m.ParentID]
+			node.Parent = parent
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	var order func(nodes []*MilestoneNode, depth int)
+	order = func(nodes []*MilestoneNode, depth int) {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].Milestone.StartTime.Before(nodes[j].Milestone.StartTime)
+		})
+		for _, node := range nodes {
+			node.Depth = depth
+			order(node.Children, depth+1)
+		}
+	}
+	order(roots, 0)
+
+	return roots
+}
+
+// Walk calls fn for the node and then for every milestone nested inside it.
+func (n *MilestoneNode) Walk(fn func(*MilestoneNode)) {
+	fn(n)
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// Path returns the milestones from the top level down to this one.
+func (n *MilestoneNode) Path() []*Milestone {
+	var path []*Milestone
+	for node := n; node != nil; node = node.Parent {
+		path = append([]*Milestone{node.Milestone}, path...)
+	}
+	return path
+}
+
+// FindMilestoneNode returns the node of the milestone with the given ID.
+func FindMilestoneNode(roots []*MilestoneNode, id int64) *MilestoneNode {
+	var found *MilestoneNode
+	for _, root := range roots {
+		root.Walk(func(node *MilestoneNode) {
+			if node.Milestone.ID == id {
+				found = node
+			}
+		})
+	}
+	return found
+}
+
+// GetMilestoneTree returns the milestones of a project nested under their
+// parents.
+func (d *Database) GetMilestoneTree(projectName string) ([]*MilestoneNode, error) {
+	milestones, err := d.GetMilestonesByProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return BuildMilestoneTree(milestones), nil
+}
+
+// GetActiveMilestonesForProject returns the active milestones of a project
+// from the top level down to the deepest active level.
+func (d *Database) GetActiveMilestonesForProject(projectName string) ([]*Milestone, error) {
+	return activeMilestones(d.db, projectName)
+}
+
+// GetEntriesInMilestoneTree returns the entries of a milestone and of every
+// milestone nested inside it.
+func (d *Database) GetEntriesInMilestoneTree(m *Milestone) ([]*TimeEntry, error) {
+	roots, err := d.GetMilestoneTree(m.ProjectName)
+	if err != nil {
+		return nil, err
+	}
+
+	node := FindMilestoneNode(roots, m.ID)
+	if node == nil {
+		return d.GetEntriesByMilestone(m.ProjectName, m.Name)
+	}
+
+	var entries []*TimeEntry
+	var walkErr error
+	node.Walk(func(n *MilestoneNode) {
+		if walkErr != nil {
+			return
+		}
+
+		own, err := d.GetEntriesByMilestone(m.ProjectName, n.Milestone.Name)
+		if err != nil {
+			walkErr = err
+			return
+		}
+		entries = append(entries, own...)
+	})
+
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].StartTime.After(entries[j].StartTime)
+	})
+
+	return entries, nil
+}
+
+// activeMilestones returns the chain of active milestones of a project, top
+// level first. Each active milestone has at most one active child.
+func activeMilestones(q querier, projectName string) ([]*Milestone, error) {
+	rows, err := q.Query(
+		"SELECT "+milestoneColumns+" FROM milestones WHERE project_name = ? AND end_time IS NULL AND deleted_at IS NULL ORDER BY start_time",
+		projectName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active milestones: %w", err)
+	}
+
+	active, err := collectMilestones(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := BuildMilestoneTree(active)
+	if len(roots) == 0 {
+		return nil, nil
+	}
+
+	// Only one chain is expected, but if there are several the most recently
+	// started one wins, as it did before milestones could be nested
+	var chain []*Milestone
+	for node := roots[len(roots)-1]; node != nil; {
+		chain = append(chain, node.Milestone)
+
+		var next *MilestoneNode
+		if len(node.Children) > 0 {
+			next = node.Children[len(node.Children)-1]
+		}
+		node = next
+	}
+
+	return chain, nil
+}
+
+// activeSibling returns the active milestone with the given parent, other
+// than excludeID, or nil if there is none.
+func activeSibling(q querier, projectName string, parentID *int64, excludeID int64) (*Milestone, error) {
+	milestone, err := scanMilestone(q.QueryRow(
+		"SELECT "+milestoneColumns+" FROM milestones WHERE project_name = ? AND parent_id IS ? AND id != ? AND end_time IS NULL AND deleted_at IS NULL LIMIT 1",
+		projectName, parentID, excludeID,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active milestone: %w", err)
+	}
+
+	return milestone, nil
+}
+
+// liveMilestone returns a milestone that isn't in the trash, or nil.
+func liveMilestone(q querier, id int64) (*Milestone, error) {
+	milestone, err := scanMilestone(q.QueryRow(
+		"SELECT "+milestoneColumns+" FROM milestones WHERE id = ? AND deleted_at IS NULL",
+		id,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milestone: %w", err)
+	}
+
+	return milestone, nil
+}
+
+// checkCanActivate returns an error when a milestone with the given parent
+// can't become active: the parent must be active, and must not have another
+// active child. Top-level milestones count as children of no parent.
+func checkCanActivate(q querier, projectName string, parentID *int64, excludeID int64) error {
+	if parentID != nil {
+		parent, err := liveMilestone(q, *parentID)
+		if err != nil {
+			return err
+		}
+
+		if parent == nil || parent.ProjectName != projectName {
+			return fmt.Errorf("parent milestone #%d not found in %s", *parentID, projectName)
+		}
+
+		if !parent.IsActive() {
+			return fmt.Errorf("parent milestone '%s' is finished, reopen it first", parent.Name)
+		}
+	}
+
+	sibling, err := activeSibling(q, projectName, parentID, excludeID)
+	if err != nil {
+		return err
+	}
+
+	if sibling != nil {
+		return fmt.Errorf("milestone '%s' is already active at this level for %s, finish it first", sibling.Name, projectName)
+	}
+
+	return nil
+}
+
+// activeDescendants returns the active milestones nested, at any depth,
+// inside the milestone id.
+func activeDescendants(q querier, projectName string, id int64) ([]*Milestone, error) {
+	chain, err := activeMilestones(q, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range chain {
+		if m.ID == id {
+			return chain[i+1:], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// childMilestoneIDs returns the IDs of the milestones directly nested inside
+// id, including those in the trash.
+func childMilestoneIDs(q querier, id int64) ([]int64, error) {
+	rows, err := q.Query("SELECT id FROM milestones WHERE parent_id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nested milestones: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var childID int64
+		if err := rows.Scan(&childID); err != nil {
+			return nil, fmt.Errorf("failed to scan milestone: %w", err)
+		}
+		ids = append(ids, childID)
+	}
+
+	return ids, rows.Err()
+}
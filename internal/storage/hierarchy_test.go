@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMilestoneHierarchy(t *testing.T) {
+	// release > sprint > epic, all active
+	setup := func(t *testing.T) (*Database, *Milestone, *Milestone, *Milestone) {
+		db := setupTestDB(t)
+
+		release, err := db.CreateMilestone("p", "Release 2")
+		require.NoError(t, err)
+		sprint, err := db.CreateMilestoneWithPlan("p", "Sprint 3", &release.ID, MilestonePlan{})
+		require.NoError(t, err)
+		epic, err := db.CreateMilestoneWithPlan("p", "Login", &sprint.ID, MilestonePlan{})
+		require.NoError(t, err)
+
+		return db, release, sprint, epic
+	}
+
+	t.Run("deepest active level is used for tagging", func(t *testing.T) {
+		db, release, sprint, epic := setup(t)
+		defer db.Close()
+
+		chain, err := db.GetActiveMilestonesForProject("p")
+		require.NoError(t, err)
+		require.Len(t, chain, 3)
+		assert.Equal(t, []int64{release.ID, sprint.ID, epic.ID}, []int64{chain[0].ID, chain[1].ID, chain[2].ID})
+
+		active, err := db.GetActiveMilestoneForProject("p")
+		require.NoError(t, err)
+		assert.Equal(t, epic.ID, active.ID)
+	})
+
+	t.Run("one active milestone per level", func(t *testing.T) {
+		db, release, _, _ := setup(t)
+		defer db.Close()
+
+		_, err := db.CreateMilestone("p", "Release 3")
+		assert.ErrorContains(t, err, "Release 2")
+
+		_, err = db.CreateMilestoneWithPlan("p", "Sprint 4", &release.ID, MilestonePlan{})
+		assert.ErrorContains(t, err, "Sprint 3")
+	})
+
+	t.Run("finishing cascades to nested milestones", func(t *testing.T) {
+		db, release, sprint, epic := setup(t)
+		defer db.Close()
+
+		require.NoError(t, db.FinishMilestone(sprint.ID))
+
+		for _, id := range []int64{sprint.ID, epic.ID} {
+			m, err := db.GetMilestone(id)
+			require.NoError(t, err)
+			assert.False(t, m.IsActive(), m.Name)
+		}
+
+		active, err := db.GetActiveMilestoneForProject("p")
+		require.NoError(t, err)
+		assert.Equal(t, release.ID, active.ID)
+
+		// a finished parent can't get active children
+		_, err = db.CreateMilestoneWithPlan("p", "Sprint 4", &sprint.ID, MilestonePlan{})
+		assert.ErrorContains(t, err, "finished")
+		assert.ErrorContains(t, db.ReopenMilestone(epic.ID), "finished")
+
+		require.NoError(t, db.ReopenMilestone(sprint.ID))
+		require.NoError(t, db.ReopenMilestone(epic.ID))
+
+		op, err := db.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, OpMilestoneReopen, op.Kind)
+	})
+
+	t.Run("editing the end cascades to nested milestones", func(t *testing.T) {
+		db, release, sprint, epic := setup(t)
+		defer db.Close()
+
+		end := time.Now()
+		require.NoError(t, db.UpdateMilestoneDates(release.ID, release.StartTime.Add(-time.Hour), &end))
+
+		for _, id := range []int64{release.ID, sprint.ID, epic.ID} {
+			m, err := db.GetMilestone(id)
+			require.NoError(t, err)
+			assert.False(t, m.IsActive(), m.Name)
+		}
+
+		chain, err := db.GetActiveMilestonesForProject("p")
+		require.NoError(t, err)
+		assert.Empty(t, chain)
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+
+		chain, err = db.GetActiveMilestonesForProject("p")
+		require.NoError(t, err)
+		assert.Len(t, chain, 3)
+	})
+
+	t.Run("tree and rolled up entries", func(t *testing.T) {
+		db, release, sprint, epic := setup(t)
+		defer db.Close()
+
+		base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+		for i, m := range []*Milestone{release, sprint, epic, epic} {
+			start := base.Add(time.Duration(i) * time.Hour)
+			_, err := db.CreateManualEntry("p", "", start, start.Add(time.Hour), nil, &m.Name)
+			require.NoError(t, err)
+		}
+
+		roots, err := db.GetMilestoneTree("p")
+		require.NoError(t, err)
+		require.Len(t, roots, 1)
+		require.Len(t, roots[0].Children, 1)
+		leaf := roots[0].Children[0].Children[0]
+		assert.Equal(t, 2, leaf.Depth)
+		assert.Equal(t, epic.ID, leaf.Path()[2].ID)
+
+		entries, err := db.GetEntriesInMilestoneTree(sprint)
+		require.NoError(t, err)
+		assert.Len(t, entries, 3)
+
+		progress, err := db.GetMilestoneProgress(release)
+		require.NoError(t, err)
+		assert.Equal(t, 4, progress.Entries)
+		assert.Equal(t, 4*time.Hour, progress.Tracked)
+	})
+
+	t.Run("deleting moves nested milestones up", func(t *testing.T) {
+		db, release, sprint, epic := setup(t)
+		defer db.Close()
+
+		_, err := db.DeleteMilestone(sprint.ID, false)
+		require.NoError(t, err)
+
+		got, err := db.GetMilestone(epic.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.ParentID)
+		assert.Equal(t, release.ID, *got.ParentID)
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+
+		got, err = db.GetMilestone(epic.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sprint.ID, *got.ParentID)
+	})
+
+	t.Run("moving a parent alone detaches its children", func(t *testing.T) {
+		db, release, sprint, _ := setup(t)
+		defer db.Close()
+
+		_, err := db.MoveEntries(MoveRequest{FromProject: "p", ToProject: "q", MilestoneName: release.Name})
+		require.NoError(t, err)
+
+		got, err := db.GetMilestone(sprint.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "p", got.ProjectName)
+		assert.Nil(t, got.ParentID)
+	})
+}
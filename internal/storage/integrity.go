@@ -0,0 +1,406 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type IssueKind string
+
+const (
+	IssueMultipleRunning  IssueKind = "multiple-running"
+	IssueEndBeforeStart   IssueKind = "end-before-start"
+	IssueMissingMilestone IssueKind = "missing-milestone"
+	IssueOverlap          IssueKind = "overlap"
+)
+
+type RepairAction string
+
+const (
+	// RepairStopRunning stops every running entry but the newest at the
+	// moment the next entry started.
+	RepairStopRunning RepairAction = "stop-running"
+	// RepairSwapTimes swaps the start and end of an inverted entry.
+	RepairSwapTimes RepairAction = "swap-times"
+	// RepairDeleteEntry deletes the inverted entry.
+	RepairDeleteEntry RepairAction = "delete-entry"
+	// RepairCreateMilestone creates the missing milestone, spanning its entries.
+	RepairCreateMilestone RepairAction = "create-milestone"
+	// RepairClearMilestone removes the missing milestone from its entries.
+	RepairClearMilestone RepairAction = "clear-milestone"
+	// RepairTrimEarlier cuts the later entry's interval out of the earlier
+	// one, splitting it when the later entry sits in its middle.
+	RepairTrimEarlier RepairAction = "trim-earlier"
+	// RepairDeleteLater deletes the later of two overlapping entries.
+	RepairDeleteLater RepairAction = "delete-later"
+)
+
+// IntegrityIssue is a single anomaly found by CheckIntegrity. Entries are
+// ordered by start time.
+type IntegrityIssue struct {
+	Kind          IssueKind
+	Entries       []*TimeEntry
+	ProjectName   string
+	MilestoneName string
+}
+
+// RepairSkippedError is returned by Repair when the chosen repair can't be
+// applied to the issue as things stand. Nothing is changed, so the caller can
+// report it and carry on with the other issues.
+type RepairSkippedError struct {
+	Reason string
+}
+
+func (e *RepairSkippedError) Error() string {
+	return e.Reason
+}
+
+// Key identifies the issue across repeated scans.
+func (i IntegrityIssue) Key() string {
+	ids := make([]string, len(i.Entries))
+	for n, entry := range i.Entries {
+		ids[n] = fmt.Sprintf("%d", entry.ID)
+	}
+
+	return fmt.Sprintf("%s:%s:%s:%s", i.Kind, i.ProjectName, i.MilestoneName, strings.Join(ids, ","))
+}
+
+// Actions lists the repairs available for the issue. The first one is the
+// default applied by 'tmpo doctor --fix'.
+func (i IntegrityIssue) Actions() []RepairAction {
+	switch i.Kind {
+	case IssueMultipleRunning:
+		return []RepairAction{RepairStopRunning}
+	case IssueEndBeforeStart:
+		return []RepairAction{RepairSwapTimes, RepairDeleteEntry}
+	case IssueMissingMilestone:
+		return []RepairAction{RepairCreateMilestone, RepairClearMilestone}
+	case IssueOverlap:
+		return []RepairAction{RepairTrimEarlier, RepairDeleteLater}
+	default:
+		return nil
+	}
+}
+
+// CheckIntegrity scans the database for running entries that were never
+// stopped, entries ending before they start, entries pointing at milestones
+// that don't exist, and overlapping entries.
+func (d *Database) CheckIntegrity() ([]IntegrityIssue, error) {
+	entries, err := d.GetEntriesByFilter(EntryFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartTime.Before(entries[j].StartTime)
+	})
+
+	var issues []IntegrityIssue
+
+	var running []*TimeEntry
+	for _, entry := range entries {
+		if entry.IsRunning() {
+			running = append(running, entry)
+		}
+	}
+
+	if len(running) > 1 {
+		issues = append(issues, IntegrityIssue{Kind: IssueMultipleRunning, Entries: running})
+	}
+
+	for _, entry := range entries {
+		if entry.EndTime != nil && entry.EndTime.Before(entry.StartTime) {
+			issues = append(issues, IntegrityIssue{Kind: IssueEndBeforeStart, Entries: []*TimeEntry{entry}})
+		}
+	}
+
+	missing, err := d.findMissingMilestones(entries)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, missing...)
+
+	// stale running entries overlap everything after them; they are reported
+	// above, and their overlaps show up once they have been stopped
+	checked := entries
+	if len(running) > 1 {
+		stale := make(map[int64]bool)
+		for _, entry := range running[:len(running)-1] {
+			stale[entry.ID] = true
+		}
+
+		checked = nil
+		for _, entry := range entries {
+			if !stale[entry.ID] {
+				checked = append(checked, entry)
+			}
+		}
+	}
+
+	issues = append(issues, findOverlaps(checked)...)
+
+	return issues, nil
+}
+
+func (d *Database) findMissingMilestones(entries []*TimeEntry) ([]IntegrityIssue, error) {
+	milestones, err := d.GetAllMilestones()
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, m := range milestones {
+		known[m.ProjectName+"\x00"+m.Name] = true
+	}
+
+	var issues []IntegrityIssue
+	index := make(map[string]int)
+
+	for _, entry := range entries {
+		if entry.MilestoneName == nil {
+			continue
+		}
+
+		key := entry.ProjectName + "\x00" + *entry.MilestoneName
+		if known[key] {
+			continue
+		}
+
+		if i, ok := index[key]; ok {
+			issues[i].Entries = append(issues[i].Entries, entry)
+			continue
+		}
+
+		index[key] = len(issues)
+		issues = append(issues, IntegrityIssue{
+			Kind:          IssueMissingMilestone,
+			Entries:       []*TimeEntry{entry},
+			ProjectName:   entry.ProjectName,
+			MilestoneName: *entry.MilestoneName,
+		})
+	}
+
+	return issues, nil
+}
+
+// findOverlaps reports each pair of entries whose intervals intersect.
+// entries must be sorted by start time. Running entries extend to now, and
+// inverted entries are skipped since they are reported separately.
+func findOverlaps(entries []*TimeEntry) []IntegrityIssue {
+	var issues []IntegrityIssue
+	var latest *TimeEntry
+
+	for _, entry := range entries {
+		if entry.EndTime != nil && entry.EndTime.Before(entry.StartTime) {
+			continue
+		}
+
+		if latest != nil && entry.StartTime.Before(effectiveEnd(latest)) {
+			issues = append(issues, IntegrityIssue{Kind: IssueOverlap, Entries: []*TimeEntry{latest, entry}})
+		}
+
+		if latest == nil || effectiveEnd(entry).After(effectiveEnd(latest)) {
+			latest = entry
+		}
+	}
+
+	return issues
+}
+
+// effectiveEnd treats running entries as ending now.
+func effectiveEnd(entry *TimeEntry) time.Time {
+	if entry.EndTime == nil {
+		return time.Now()
+	}
+	return *entry.EndTime
+}
+
+// Repair applies action to the issue.
+func (d *Database) Repair(issue IntegrityIssue, action RepairAction) error {
+	valid := false
+	for _, a := range issue.Actions() {
+		if a == action {
+			valid = true
+		}
+	}
+
+	if !valid {
+		return fmt.Errorf("repair '%s' does not apply to %s issues", action, issue.Kind)
+	}
+
+	return d.record(OpRepair, func(j *journal) (string, error) {
+		switch action {
+		case RepairStopRunning:
+			return "Stopped stale running entries", stopOlderRunning(j, issue.Entries)
+		case RepairSwapTimes:
+			entry := issue.Entries[0]
+			return fmt.Sprintf("Swapped start and end of entry #%d", entry.ID), setEntryTimes(j, entry.ID, *entry.EndTime, entry.StartTime)
+		case RepairDeleteEntry:
+			id := issue.Entries[0].ID
+			return fmt.Sprintf("Deleted inverted entry #%d", id), deleteEntryRow(j, id)
+		case RepairCreateMilestone:
+			return fmt.Sprintf("Created missing milestone %s in %s", issue.MilestoneName, issue.ProjectName),
+				createMilestoneSpanning(j, issue.ProjectName, issue.MilestoneName, issue.Entries)
+		case RepairClearMilestone:
+			return fmt.Sprintf("Removed missing milestone %s from %s entries", issue.MilestoneName, issue.ProjectName),
+				clearMilestone(j, issue.ProjectName, issue.MilestoneName)
+		case RepairTrimEarlier:
+			earlier, later := issue.Entries[0], issue.Entries[1]
+			return fmt.Sprintf("Trimmed entry #%d around entry #%d", earlier.ID, later.ID),
+				carveEntry(j, earlier, later.StartTime, effectiveEnd(later))
+		case RepairDeleteLater:
+			id := issue.Entries[1].ID
+			return fmt.Sprintf("Deleted overlapping entry #%d", id), deleteEntryRow(j, id)
+		}
+
+		return "", nil
+	})
+}
+
+func stopOlderRunning(j *journal, running []*TimeEntry) error {
+	newest := running[len(running)-1]
+
+	for _, entry := range running[:len(running)-1] {
+		// stop it when the next entry (of any kind) started, or when the
+		// newest running entry did if nothing starts later
+		var next time.Time
+		err := j.tx.QueryRow(
+			"SELECT start_time FROM time_entries WHERE start_time > ? AND id != ? AND deleted_at IS NULL ORDER BY start_time ASC LIMIT 1",
+			entry.StartTime, entry.ID,
+		).Scan(&next)
+		if err == sql.ErrNoRows {
+			next = newest.StartTime
+		} else if err != nil {
+			return fmt.Errorf("failed to find next entry: %w", err)
+		}
+
+		if _, err := j.track(tableEntries, entry.ID); err != nil {
+			return err
+		}
+
+		if _, err := j.tx.Exec("UPDATE time_entries SET end_time = ? WHERE id = ?", next, entry.ID); err != nil {
+			return fmt.Errorf("failed to stop entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func setEntryTimes(j *journal, id int64, start, end time.Time) error {
+	if _, err := j.track(tableEntries, id); err != nil {
+		return err
+	}
+
+	_, err := j.tx.Exec("UPDATE time_entries SET start_time = ?, end_time = ? WHERE id = ?", start, end, id)
+	if err != nil {
+		return fmt.Errorf("failed to update entry: %w", err)
+	}
+	return nil
+}
+
+func deleteEntryRow(j *journal, id int64) error {
+	if _, err := j.track(tableEntries, id); err != nil {
+		return err
+	}
+
+	return trashEntry(j, id)
+}
+
+// createMilestoneSpanning creates a milestone covering entries. It is
+// skipped when a milestone with that name is in the trash, or when the
+// milestone would be active next to another active one.
+func createMilestoneSpanning(j *journal, projectName, name string, entries []*TimeEntry) error {
+	existing, err := milestoneRow(j.tx, projectName, name)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.DeletedAt != nil {
+		return &RepairSkippedError{Reason: fmt.Sprintf("milestone '%s' in %s is in the trash, restore it with 'tmpo trash restore --milestone %d'", name, projectName, existing.ID)}
+	}
+
+	start := entries[0].StartTime
+	var end *time.Time
+
+	for _, entry := range entries {
+		if entry.StartTime.Before(start) {
+			start = entry.StartTime
+		}
+
+		if entry.IsRunning() {
+			// a running entry keeps the milestone active
+			end = nil
+			break
+		}
+
+		if end == nil || entry.EndTime.After(*end) {
+			end = entry.EndTime
+		}
+	}
+
+	if end == nil {
+		sibling, err := activeSibling(j.tx, projectName, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		if sibling != nil {
+			return &RepairSkippedError{Reason: fmt.Sprintf("milestone '%s' would be active next to '%s' in %s, finish it first", name, sibling.Name, projectName)}
+		}
+	}
+
+	result, err := j.tx.Exec(
+		"INSERT INTO milestones (project_name, name, start_time, end_time) VALUES (?, ?, ?, ?)",
+		projectName, name, start, end,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create milestone: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	j.created(tableMilestones, id)
+
+	return nil
+}
+
+func clearMilestone(j *journal, projectName, name string) error {
+	rows, err := j.tx.Query(
+		"SELECT id FROM time_entries WHERE project_name = ? AND milestone_name = ? AND deleted_at IS NULL",
+		projectName, name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query entries: %w", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan entry: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if _, err := j.track(tableEntries, id); err != nil {
+			return err
+		}
+	}
+
+	_, err = j.tx.Exec(
+		"UPDATE time_entries SET milestone_name = NULL WHERE project_name = ? AND milestone_name = ? AND deleted_at IS NULL",
+		projectName, name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear milestone: %w", err)
+	}
+	return nil
+}
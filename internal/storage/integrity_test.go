@@ -0,0 +1,351 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// insertRawEntry writes an entry without any of the validation the normal
+// constructors apply, so tests can reproduce corrupted data.
+func insertRawEntry(t *testing.T, db *Database, project string, start time.Time, end *time.Time, milestone *string) int64 {
+	result, err := db.db.Exec(
+		"INSERT INTO time_entries (project_name, start_time, end_time, description, milestone_name) VALUES (?, ?, ?, '', ?)",
+		project, start, end, milestone,
+	)
+	require.NoError(t, err)
+
+	id, err := result.LastInsertId()
+	require.NoError(t, err)
+
+	return id
+}
+
+func issuesOfKind(issues []IntegrityIssue, kind IssueKind) []IntegrityIssue {
+	var matching []IntegrityIssue
+	for _, issue := range issues {
+		if issue.Kind == kind {
+			matching = append(matching, issue)
+		}
+	}
+	return matching
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	at := func(hours float64) *time.Time {
+		ts := base.Add(time.Duration(hours * float64(time.Hour)))
+		return &ts
+	}
+
+	t.Run("clean database has no issues", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		insertRawEntry(t, db, "p", *at(0), at(1), nil)
+		insertRawEntry(t, db, "p", *at(1), at(2), nil)
+		insertRawEntry(t, db, "p", *at(3), nil, nil)
+
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("detects multiple running entries", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		first := insertRawEntry(t, db, "p", *at(0), nil, nil)
+		second := insertRawEntry(t, db, "p", *at(2), nil, nil)
+
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+
+		running := issuesOfKind(issues, IssueMultipleRunning)
+		require.Len(t, running, 1)
+		assert.Equal(t, first, running[0].Entries[0].ID)
+		assert.Equal(t, second, running[0].Entries[1].ID)
+
+		// the stale entry's overlaps are left until it has been stopped
+		assert.Empty(t, issuesOfKind(issues, IssueOverlap))
+	})
+
+	t.Run("detects end before start", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		id := insertRawEntry(t, db, "p", *at(2), at(1), nil)
+
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, IssueEndBeforeStart, issues[0].Kind)
+		assert.Equal(t, id, issues[0].Entries[0].ID)
+	})
+
+	t.Run("detects missing milestones per project", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		_, err := db.CreateMilestone("p", "Known")
+		require.NoError(t, err)
+
+		known, ghost := "Known", "Ghost"
+		insertRawEntry(t, db, "p", *at(0), at(1), &known)
+		insertRawEntry(t, db, "p", *at(1), at(2), &ghost)
+		insertRawEntry(t, db, "p", *at(2), at(3), &ghost)
+		insertRawEntry(t, db, "other", *at(3), at(4), &known)
+
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+
+		missing := issuesOfKind(issues, IssueMissingMilestone)
+		require.Len(t, missing, 2)
+		assert.Equal(t, "Ghost", missing[0].MilestoneName)
+		assert.Len(t, missing[0].Entries, 2)
+		assert.Equal(t, "other", missing[1].ProjectName)
+		assert.Equal(t, "Known", missing[1].MilestoneName)
+	})
+
+	t.Run("detects overlaps across projects", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		long := insertRawEntry(t, db, "a", *at(0), at(4), nil)
+		inside := insertRawEntry(t, db, "b", *at(1), at(2), nil)
+		after := insertRawEntry(t, db, "a", *at(3), at(5), nil)
+		insertRawEntry(t, db, "a", *at(5), at(6), nil)
+
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+
+		overlaps := issuesOfKind(issues, IssueOverlap)
+		require.Len(t, overlaps, 2)
+		assert.Equal(t, []int64{long, inside}, []int64{overlaps[0].Entries[0].ID, overlaps[0].Entries[1].ID})
+		assert.Equal(t, []int64{long, after}, []int64{overlaps[1].Entries[0].ID, overlaps[1].Entries[1].ID})
+	})
+}
+
+func TestRepair(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	at := func(hours float64) *time.Time {
+		ts := base.Add(time.Duration(hours * float64(time.Hour)))
+		return &ts
+	}
+
+	repairAll := func(t *testing.T, db *Database, pick func(IntegrityIssue) RepairAction) {
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+		require.NotEmpty(t, issues)
+
+		require.NoError(t, db.Repair(issues[0], pick(issues[0])))
+	}
+	defaultAction := func(issue IntegrityIssue) RepairAction { return issue.Actions()[0] }
+
+	t.Run("stops older running entries when the next entry started", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		first := insertRawEntry(t, db, "p", *at(0), nil, nil)
+		insertRawEntry(t, db, "p", *at(2), at(3), nil)
+		latest := insertRawEntry(t, db, "p", *at(4), nil, nil)
+
+		repairAll(t, db, defaultAction)
+
+		entry, err := db.GetEntry(first)
+		require.NoError(t, err)
+		require.NotNil(t, entry.EndTime)
+		assert.True(t, entry.EndTime.Equal(*at(2)))
+
+		running, err := db.GetRunningEntry()
+		require.NoError(t, err)
+		assert.Equal(t, latest, running.ID)
+
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("stops running entries that started at the same time", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		first := insertRawEntry(t, db, "p", *at(0), nil, nil)
+		latest := insertRawEntry(t, db, "p", *at(0), nil, nil)
+
+		repairAll(t, db, defaultAction)
+
+		entry, err := db.GetEntry(first)
+		require.NoError(t, err)
+		require.NotNil(t, entry.EndTime)
+		assert.True(t, entry.EndTime.Equal(*at(0)))
+
+		running, err := db.GetRunningEntry()
+		require.NoError(t, err)
+		assert.Equal(t, latest, running.ID)
+	})
+
+	t.Run("swaps inverted times", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		id := insertRawEntry(t, db, "p", *at(2), at(1), nil)
+		repairAll(t, db, defaultAction)
+
+		entry, err := db.GetEntry(id)
+		require.NoError(t, err)
+		assert.True(t, entry.StartTime.Equal(*at(1)))
+		assert.True(t, entry.EndTime.Equal(*at(2)))
+	})
+
+	t.Run("creates missing milestone spanning its entries", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		ghost := "Ghost"
+		insertRawEntry(t, db, "p", *at(1), at(2), &ghost)
+		insertRawEntry(t, db, "p", *at(0), at(3), &ghost)
+
+		repairAll(t, db, defaultAction)
+
+		milestone, err := db.GetMilestoneByName("p", "Ghost")
+		require.NoError(t, err)
+		require.NotNil(t, milestone)
+		assert.True(t, milestone.StartTime.Equal(*at(0)))
+		require.NotNil(t, milestone.EndTime)
+		assert.True(t, milestone.EndTime.Equal(*at(3)))
+	})
+
+	t.Run("skips creating a milestone that is in the trash", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		milestone, err := db.CreateMilestone("p", "Ghost")
+		require.NoError(t, err)
+		_, err = db.DeleteMilestone(milestone.ID, false)
+		require.NoError(t, err)
+
+		ghost := "Ghost"
+		insertRawEntry(t, db, "p", *at(0), at(1), &ghost)
+
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+
+		var skip *RepairSkippedError
+		require.ErrorAs(t, db.Repair(issues[0], RepairCreateMilestone), &skip)
+
+		trashed, err := db.GetTrashedMilestones()
+		require.NoError(t, err)
+		assert.Len(t, trashed, 1)
+	})
+
+	t.Run("skips creating an active milestone next to another active one", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		_, err := db.CreateMilestone("p", "Current")
+		require.NoError(t, err)
+
+		ghost := "Ghost"
+		insertRawEntry(t, db, "p", *at(0), nil, &ghost)
+
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+		missing := issuesOfKind(issues, IssueMissingMilestone)
+		require.Len(t, missing, 1)
+
+		var skip *RepairSkippedError
+		require.ErrorAs(t, db.Repair(missing[0], RepairCreateMilestone), &skip)
+
+		milestone, err := db.GetMilestoneByName("p", "Ghost")
+		require.NoError(t, err)
+		assert.Nil(t, milestone)
+	})
+
+	t.Run("clears missing milestone", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		ghost := "Ghost"
+		id := insertRawEntry(t, db, "p", *at(0), at(1), &ghost)
+
+		repairAll(t, db, func(IntegrityIssue) RepairAction { return RepairClearMilestone })
+
+		entry, err := db.GetEntry(id)
+		require.NoError(t, err)
+		assert.Nil(t, entry.MilestoneName)
+	})
+
+	t.Run("trims the earlier entry", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		earlier := insertRawEntry(t, db, "p", *at(0), at(2), nil)
+		insertRawEntry(t, db, "p", *at(1), at(3), nil)
+
+		repairAll(t, db, defaultAction)
+
+		entry, err := db.GetEntry(earlier)
+		require.NoError(t, err)
+		assert.True(t, entry.EndTime.Equal(*at(1)))
+
+		count, err := db.CountEntries(EntryFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("splits the earlier entry around a contained one", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		ms := "M"
+		earlier := insertRawEntry(t, db, "p", *at(0), at(4), &ms)
+		insertRawEntry(t, db, "q", *at(1), at(2), nil)
+		_, err := db.CreateMilestone("p", "M")
+		require.NoError(t, err)
+
+		repairAll(t, db, defaultAction)
+
+		entries, err := db.GetEntriesByProject("p")
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+
+		// newest first
+		assert.True(t, entries[0].StartTime.Equal(*at(2)))
+		assert.True(t, entries[0].EndTime.Equal(*at(4)))
+		assert.Equal(t, "M", *entries[0].MilestoneName)
+		assert.Equal(t, earlier, entries[1].ID)
+		assert.True(t, entries[1].EndTime.Equal(*at(1)))
+
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("deletes the later entry", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		insertRawEntry(t, db, "p", *at(0), at(2), nil)
+		later := insertRawEntry(t, db, "p", *at(0), at(2), nil)
+
+		repairAll(t, db, func(IntegrityIssue) RepairAction { return RepairDeleteLater })
+
+		_, err := db.GetEntry(later)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects actions for other issue kinds", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		insertRawEntry(t, db, "p", *at(2), at(1), nil)
+		issues, err := db.CheckIntegrity()
+		require.NoError(t, err)
+
+		assert.Error(t, db.Repair(issues[0], RepairCreateMilestone))
+	})
+}
@@ -0,0 +1,542 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Operation kinds recorded in the journal.
+const (
+	OpStart           = "start"
+	OpStop            = "stop"
+	OpManual          = "manual"
+	OpImport          = "import"
+	OpSplit           = "split"
+	OpMerge           = "merge"
+	OpDuplicate       = "duplicate"
+	OpMove            = "move"
+	OpEdit            = "edit"
+	OpDelete          = "delete"
+	OpRestore         = "restore"
+	OpEmptyTrash      = "empty-trash"
+	OpResolveOverlap  = "resolve-overlap"
+	OpRepair          = "repair"
+	OpMilestoneStart  = "milestone-start"
+	OpMilestoneFinish = "milestone-finish"
+	OpMilestoneRename = "milestone-rename"
+	OpMilestoneReopen = "milestone-reopen"
+	OpMilestoneEdit   = "milestone-edit"
+	OpMilestoneDelete = "milestone-delete"
+	OpMilestoneSync   = "milestone-sync"
+	OpTimeOffAdd      = "time-off-add"
+	OpTimeOffRemove   = "time-off-remove"
+)
+
+// Operation statuses. Undone operations can be redone until a new operation
+// is recorded, which discards them.
+const (
+	OpStatusDone      = "done"
+	OpStatusUndone    = "undone"
+	OpStatusDiscarded = "discarded"
+)
+
+const (
+	tableEntries    = "time_entries"
+	tableMilestones = "milestones"
+	tableTimeOff    = "time_off"
+)
+
+// journalLimit is how many operations are kept; older ones are pruned.
+const journalLimit = 1000
+
+// rowImage is a row as stored, keyed by column. Values are kept as text so a
+// row can be written back exactly as it was; nil means NULL.
+type rowImage map[string]*string
+
+// text returns a column's value, or "" for NULL.
+func (r rowImage) text(column string) string {
+	if value := r[column]; value != nil {
+		return *value
+	}
+	return ""
+}
+
+// RowChange is the state of one row before and after an operation. A nil
+// Before means the row was created, a nil After that it was deleted.
+type RowChange struct {
+	Table  string   `json:"table"`
+	RowID  int64    `json:"id"`
+	Before rowImage `json:"before"`
+	After  rowImage `json:"after"`
+}
+
+// Subject names the kind of row changed, "entry", "milestone" or "time
+// off".
+func (c RowChange) Subject() string {
+	return tableSubject(c.Table)
+}
+
+func tableSubject(table string) string {
+	switch table {
+	case tableMilestones:
+		return "milestone"
+	case tableTimeOff:
+		return "time off"
+	}
+	return "entry"
+}
+
+// ColumnChange is a single column that differs between a row's before and
+// after images.
+type ColumnChange struct {
+	Column string
+	Before *string
+	After  *string
+}
+
+// Diff lists the columns the change modified, in column name order.
+func (c RowChange) Diff() []ColumnChange {
+	columns := make(map[string]bool)
+	for column := range c.Before {
+		columns[column] = true
+	}
+	for column := range c.After {
+		columns[column] = true
+	}
+
+	var names []string
+	for column := range columns {
+		if column != "id" {
+			names = append(names, column)
+		}
+	}
+	sort.Strings(names)
+
+	var diff []ColumnChange
+	for _, column := range names {
+		var before, after *string
+		if c.Before != nil {
+			before = c.Before[column]
+		}
+		if c.After != nil {
+			after = c.After[column]
+		}
+
+		if !textEqual(before, after) {
+			diff = append(diff, ColumnChange{Column: column, Before: before, After: after})
+		}
+	}
+
+	return diff
+}
+
+type Operation struct {
+	ID          int64
+	Kind        string
+	Description string
+	Status      string
+	CreatedAt   time.Time
+	Changes     []RowChange
+}
+
+// JournalConflictError is returned by Undo and Redo when a row was changed
+// outside the journal since the operation was recorded.
+type JournalConflictError struct {
+	Table string
+	RowID int64
+}
+
+func (e *JournalConflictError) Error() string {
+	return fmt.Sprintf("%s #%d has changed since this operation", tableSubject(e.Table), e.RowID)
+}
+
+// journal tracks the rows touched inside a recorded transaction.
+type journal struct {
+	tx      *sql.Tx
+	changes []RowChange
+	seen    map[string]bool
+	// entries holds tracked entries as they were before the operation, so
+	// field changes can be appended to the audit trail.
+	entries map[int64]*TimeEntry
+}
+
+// track captures a row's state before it is modified or deleted. It returns
+// the captured image, or nil if the row doesn't exist.
+func (j *journal) track(table string, id int64) (rowImage, error) {
+	before, err := captureRow(j.tx, table, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s:%d", table, id)
+	if !j.seen[key] {
+		j.seen[key] = true
+		j.changes = append(j.changes, RowChange{Table: table, RowID: id, Before: before})
+
+		if table == tableEntries && before != nil {
+			entry, err := auditedEntry(j.tx, id)
+			if err != nil {
+				return nil, err
+			}
+			j.entries[id] = entry
+		}
+	}
+
+	return before, nil
+}
+
+// created records a row inserted by the operation.
+func (j *journal) created(table string, id int64) {
+	j.seen[fmt.Sprintf("%s:%d", table, id)] = true
+	j.changes = append(j.changes, RowChange{Table: table, RowID: id})
+}
+
+// record runs fn in a transaction and journals the rows it tracks as one
+// operation. fn returns the operation's description. Recording a new
+// operation discards anything that could still be redone; if fn changed
+// nothing, no operation is recorded. Field changes to existing entries are
+// also appended to the audit trail, except for stopping a timer.
+func (d *Database) record(kind string, fn func(j *journal) (string, error)) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	j := &journal{tx: tx, seen: make(map[string]bool), entries: make(map[int64]*TimeEntry)}
+
+	description, err := fn(j)
+	if err != nil {
+		return err
+	}
+
+	var changed []RowChange
+	for _, change := range j.changes {
+		after, err := captureRow(tx, change.Table, change.RowID)
+		if err != nil {
+			return err
+		}
+
+		change.After = after
+		if imagesEqual(change.Before, change.After) {
+			continue
+		}
+		changed = append(changed, change)
+
+		if before := j.entries[change.RowID]; change.Table == tableEntries && before != nil && after != nil && kind != OpStop {
+			if err := auditStep(tx, before, change.RowID, kind); err != nil {
+				return err
+			}
+		}
+	}
+
+	// nothing to undo, so leave the journal (and the redo stack) alone
+	if len(changed) == 0 {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	}
+
+	changes, err := json.Marshal(changed)
+	if err != nil {
+		return fmt.Errorf("failed to encode operation: %w", err)
+	}
+
+	if _, err := tx.Exec("UPDATE operations SET status = ? WHERE status = ?", OpStatusDiscarded, OpStatusUndone); err != nil {
+		return fmt.Errorf("failed to update journal: %w", err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO operations (kind, description, changes, status, created_at) VALUES (?, ?, ?, ?, ?)",
+		kind, description, string(changes), OpStatusDone, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record operation: %w", err)
+	}
+
+	_, err = tx.Exec(
+		"DELETE FROM operations WHERE id <= (SELECT MAX(id) FROM operations) - ?",
+		journalLimit,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to prune journal: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetOperations returns the most recent journal entries, newest first.
+func (d *Database) GetOperations(limit int) ([]*Operation, error) {
+	query := "SELECT id, kind, description, changes, status, created_at FROM operations ORDER BY id DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query operations: %w", err)
+	}
+	defer rows.Close()
+
+	var operations []*Operation
+	for rows.Next() {
+		op, err := scanOperation(rows)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, op)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read operations: %w", err)
+	}
+
+	return operations, nil
+}
+
+func scanOperation(row rowScanner) (*Operation, error) {
+	var op Operation
+	var changes string
+
+	if err := row.Scan(&op.ID, &op.Kind, &op.Description, &changes, &op.Status, &op.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(changes), &op.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode operation #%d: %w", op.ID, err)
+	}
+
+	return &op, nil
+}
+
+// Undo reverts the most recent operation that hasn't been undone. It returns
+// nil when there is nothing to undo.
+func (d *Database) Undo() (*Operation, error) {
+	return d.step(
+		"SELECT id, kind, description, changes, status, created_at FROM operations WHERE status = ? ORDER BY id DESC LIMIT 1",
+		OpStatusDone, OpStatusUndone, true,
+	)
+}
+
+// Redo reapplies the most recently undone operation. It returns nil when
+// there is nothing to redo.
+func (d *Database) Redo() (*Operation, error) {
+	return d.step(
+		"SELECT id, kind, description, changes, status, created_at FROM operations WHERE status = ? ORDER BY id ASC LIMIT 1",
+		OpStatusUndone, OpStatusDone, false,
+	)
+}
+
+func (d *Database) step(query, from, to string, undo bool) (*Operation, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	op, err := scanOperation(tx.QueryRow(query, from))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	changes := op.Changes
+	if undo {
+		// revert in the opposite order the rows were changed
+		changes = make([]RowChange, len(op.Changes))
+		for i, change := range op.Changes {
+			changes[len(op.Changes)-1-i] = change
+		}
+	}
+
+	for _, change := range changes {
+		expected, target := change.After, change.Before
+		if !undo {
+			expected, target = change.Before, change.After
+		}
+
+		current, err := captureRow(tx, change.Table, change.RowID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !imagesEqual(current, expected) {
+			return nil, &JournalConflictError{Table: change.Table, RowID: change.RowID}
+		}
+
+		audited := change.Table == tableEntries && current != nil && target != nil && op.Kind != OpStop
+
+		var before *TimeEntry
+		if audited {
+			if before, err = auditedEntry(tx, change.RowID); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := restoreRow(tx, change.Table, change.RowID, target); err != nil {
+			return nil, err
+		}
+
+		if audited {
+			via := ViaRedo
+			if undo {
+				via = ViaUndo
+			}
+
+			if err := auditStep(tx, before, change.RowID, via); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE operations SET status = ? WHERE id = ?", to, op.ID); err != nil {
+		return nil, fmt.Errorf("failed to update journal: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	op.Status = to
+	return op, nil
+}
+
+func tableColumns(q querier, table string) ([]string, error) {
+	rows, err := q.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, fmt.Errorf("failed to read %s columns: %w", table, err)
+		}
+		columns = append(columns, name)
+	}
+
+	return columns, rows.Err()
+}
+
+// captureRow reads a row as stored text, or returns nil if it doesn't exist.
+func captureRow(q querier, table string, id int64) (rowImage, error) {
+	columns, err := tableColumns(q, table)
+	if err != nil {
+		return nil, err
+	}
+
+	selects := make([]string, len(columns))
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i, column := range columns {
+		selects[i] = fmt.Sprintf("CAST(%s AS TEXT)", column)
+		dest[i] = &values[i]
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = ?", strings.Join(selects, ", "), table)
+	err = q.QueryRow(query, id).Scan(dest...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s row: %w", table, err)
+	}
+
+	image := make(rowImage, len(columns))
+	for i, column := range columns {
+		if values[i].Valid {
+			value := values[i].String
+			image[column] = &value
+		} else {
+			image[column] = nil
+		}
+	}
+
+	return image, nil
+}
+
+// restoreRow writes image back as the row with the given id, deleting the
+// row when image is nil.
+func restoreRow(q querier, table string, id int64, image rowImage) error {
+	if image == nil {
+		if _, err := q.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", table), id); err != nil {
+			return fmt.Errorf("failed to delete %s row: %w", table, err)
+		}
+		return nil
+	}
+
+	columns, err := tableColumns(q, table)
+	if err != nil {
+		return err
+	}
+
+	var names, placeholders []string
+	var args []any
+	for _, column := range columns {
+		value, ok := image[column]
+		if !ok {
+			// added after the operation was recorded; leave the default
+			continue
+		}
+
+		names = append(names, column)
+		placeholders = append(placeholders, "?")
+		if value == nil {
+			args = append(args, nil)
+		} else {
+			args = append(args, *value)
+		}
+	}
+
+	query := fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES (%s)", table, strings.Join(names, ", "), strings.Join(placeholders, ", "))
+	if _, err := q.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to restore %s row: %w", table, err)
+	}
+
+	return nil
+}
+
+// imagesEqual compares two row images, treating missing columns as NULL so
+// columns added by later migrations don't count as changes.
+func imagesEqual(a, b rowImage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	for column, value := range a {
+		if !textEqual(value, b[column]) {
+			return false
+		}
+	}
+
+	for column, value := range b {
+		if !textEqual(value, a[column]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func textEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
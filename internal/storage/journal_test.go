@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUndoRedo(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+	t.Run("nothing to undo or redo", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		op, err := db.Undo()
+		assert.NoError(t, err)
+		assert.Nil(t, op)
+
+		op, err = db.Redo()
+		assert.NoError(t, err)
+		assert.Nil(t, op)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "work", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		op, err := db.Undo()
+		require.NoError(t, err)
+		require.NotNil(t, op)
+		assert.Equal(t, OpManual, op.Kind)
+		assert.Equal(t, OpStatusUndone, op.Status)
+
+		_, err = db.GetEntry(entry.ID)
+		assert.Error(t, err)
+
+		op, err = db.Redo()
+		require.NoError(t, err)
+		require.NotNil(t, op)
+
+		restored, err := db.GetEntry(entry.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "work", restored.Description)
+		assert.True(t, restored.StartTime.Equal(entry.StartTime))
+	})
+
+	t.Run("edit restores every column", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		rate := 75.5
+		milestone := "Sprint"
+		entry, err := db.CreateManualEntry("p", "original", base, base.Add(time.Hour), &rate, &milestone)
+		require.NoError(t, err)
+
+		end := base.Add(3 * time.Hour)
+		edited := *entry
+		edited.ProjectName = "q"
+		edited.Description = "changed"
+		edited.EndTime = &end
+		edited.HourlyRate = nil
+		edited.MilestoneName = nil
+		require.NoError(t, db.UpdateTimeEntry(entry.ID, &edited))
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+
+		got, err := db.GetEntry(entry.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "p", got.ProjectName)
+		assert.Equal(t, "original", got.Description)
+		assert.True(t, got.EndTime.Equal(*entry.EndTime))
+		assert.Equal(t, rate, *got.HourlyRate)
+		assert.Equal(t, milestone, *got.MilestoneName)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, db.DeleteTimeEntry(entry.ID))
+
+		op, err := db.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, OpDelete, op.Kind)
+
+		_, err = db.GetEntry(entry.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("stop and milestone finish", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateEntry("p", "", nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, db.StopEntry(entry.ID))
+
+		milestone, err := db.CreateMilestone("p", "M")
+		require.NoError(t, err)
+		require.NoError(t, db.FinishMilestone(milestone.ID))
+
+		op, err := db.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, OpMilestoneFinish, op.Kind)
+
+		active, err := db.GetActiveMilestoneForProject("p")
+		require.NoError(t, err)
+		require.NotNil(t, active)
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+		op, err = db.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, OpStop, op.Kind)
+
+		running, err := db.GetRunningEntry()
+		require.NoError(t, err)
+		require.NotNil(t, running)
+		assert.Equal(t, entry.ID, running.ID)
+	})
+
+	t.Run("undo and redo walk the stack in order", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		first, err := db.CreateManualEntry("p", "first", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		second, err := db.CreateManualEntry("p", "second", base.Add(time.Hour), base.Add(2*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+		_, err = db.Undo()
+		require.NoError(t, err)
+
+		count, err := db.CountEntries(EntryFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		op, err := db.Redo()
+		require.NoError(t, err)
+		assert.Contains(t, op.Description, "#1")
+
+		_, err = db.GetEntry(first.ID)
+		assert.NoError(t, err)
+		_, err = db.GetEntry(second.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("new operations discard the redo stack", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		_, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		_, err = db.Undo()
+		require.NoError(t, err)
+
+		_, err = db.CreateManualEntry("p", "", base.Add(2*time.Hour), base.Add(3*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		op, err := db.Redo()
+		assert.NoError(t, err)
+		assert.Nil(t, op)
+
+		ops, err := db.GetOperations(0)
+		require.NoError(t, err)
+		require.Len(t, ops, 2)
+		assert.Equal(t, OpStatusDone, ops[0].Status)
+		assert.Equal(t, OpStatusDiscarded, ops[1].Status)
+	})
+
+	t.Run("refuses to undo over outside changes", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		_, err = db.db.Exec("UPDATE time_entries SET description = 'sneaky' WHERE id = ?", entry.ID)
+		require.NoError(t, err)
+
+		_, err = db.Undo()
+		var conflict *JournalConflictError
+		require.True(t, errors.As(err, &conflict))
+		assert.Equal(t, entry.ID, conflict.RowID)
+
+		// nothing was changed
+		_, err = db.GetEntry(entry.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("multi-row operations undo as one", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		long, err := db.CreateManualEntry("p", "", base, base.Add(4*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		changed, err := db.CarveOut(base.Add(time.Hour), base.Add(2*time.Hour), 0)
+		require.NoError(t, err)
+		assert.Equal(t, 1, changed)
+
+		count, err := db.CountEntries(EntryFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		op, err := db.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, OpResolveOverlap, op.Kind)
+		assert.Len(t, op.Changes, 2)
+
+		count, err = db.CountEntries(EntryFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		got, err := db.GetEntry(long.ID)
+		require.NoError(t, err)
+		assert.True(t, got.EndTime.Equal(*long.EndTime))
+	})
+}
+
+func TestGetOperations(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	entry, err := db.CreateEntry("p", "", nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.StopEntry(entry.ID))
+
+	ops, err := db.GetOperations(1)
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+	assert.Equal(t, OpStop, ops[0].Kind)
+	assert.Equal(t, "Stopped tracking p", ops[0].Description)
+	require.Len(t, ops[0].Changes, 1)
+	assert.NotNil(t, ops[0].Changes[0].Before)
+	assert.NotNil(t, ops[0].Changes[0].After)
+
+	ops, err = db.GetOperations(0)
+	require.NoError(t, err)
+	assert.Len(t, ops, 2)
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMilestoneManagement(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+	setup := func(t *testing.T) (*Database, *Milestone, []*TimeEntry) {
+		db := setupTestDB(t)
+
+		milestone, err := db.CreateMilestone("p", "Sprnt 1")
+		require.NoError(t, err)
+
+		name := milestone.Name
+		var entries []*TimeEntry
+		for i := 0; i < 3; i++ {
+			start := base.Add(time.Duration(i) * time.Hour)
+			entry, err := db.CreateManualEntry("p", "", start, start.Add(time.Hour), nil, &name)
+			require.NoError(t, err)
+			entries = append(entries, entry)
+		}
+
+		return db, milestone, entries
+	}
+
+	t.Run("rename retags entries including trashed ones", func(t *testing.T) {
+		db, milestone, entries := setup(t)
+		defer db.Close()
+
+		require.NoError(t, db.DeleteTimeEntry(entries[2].ID))
+
+		retagged, err := db.RenameMilestone(milestone.ID, "Sprint 1")
+		require.NoError(t, err)
+		assert.Equal(t, 3, retagged)
+
+		tagged, err := db.GetEntriesByMilestone("p", "Sprint 1")
+		require.NoError(t, err)
+		assert.Len(t, tagged, 2)
+
+		trashed, err := db.GetTrashedEntries()
+		require.NoError(t, err)
+		require.Len(t, trashed, 1)
+		assert.Equal(t, "Sprint 1", *trashed[0].MilestoneName)
+
+		old, err := db.GetMilestoneByName("p", "Sprnt 1")
+		require.NoError(t, err)
+		assert.Nil(t, old)
+
+		op, err := db.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, OpMilestoneRename, op.Kind)
+
+		tagged, err = db.GetEntriesByMilestone("p", "Sprnt 1")
+		require.NoError(t, err)
+		assert.Len(t, tagged, 2)
+	})
+
+	t.Run("rename rejects names in use", func(t *testing.T) {
+		db, milestone, _ := setup(t)
+		defer db.Close()
+
+		require.NoError(t, db.FinishMilestone(milestone.ID))
+		other, err := db.CreateMilestone("p", "Sprint 2")
+		require.NoError(t, err)
+
+		_, err = db.RenameMilestone(milestone.ID, "Sprint 2")
+		assert.Error(t, err)
+
+		_, err = db.DeleteMilestone(other.ID, false)
+		require.NoError(t, err)
+
+		_, err = db.RenameMilestone(milestone.ID, "Sprint 2")
+		assert.ErrorContains(t, err, "trash")
+	})
+
+	t.Run("reopen", func(t *testing.T) {
+		db, milestone, _ := setup(t)
+		defer db.Close()
+
+		assert.Error(t, db.ReopenMilestone(milestone.ID), "already active")
+
+		require.NoError(t, db.FinishMilestone(milestone.ID))
+		other, err := db.CreateMilestone("p", "Sprint 2")
+		require.NoError(t, err)
+
+		assert.ErrorContains(t, db.ReopenMilestone(milestone.ID), "Sprint 2")
+
+		require.NoError(t, db.FinishMilestone(other.ID))
+		require.NoError(t, db.ReopenMilestone(milestone.ID))
+
+		active, err := db.GetActiveMilestoneForProject("p")
+		require.NoError(t, err)
+		require.NotNil(t, active)
+		assert.Equal(t, milestone.ID, active.ID)
+	})
+
+	t.Run("update dates", func(t *testing.T) {
+		db, milestone, _ := setup(t)
+		defer db.Close()
+
+		end := base.Add(8 * time.Hour)
+		require.NoError(t, db.UpdateMilestoneDates(milestone.ID, base, &end))
+
+		got, err := db.GetMilestone(milestone.ID)
+		require.NoError(t, err)
+		assert.True(t, got.StartTime.Equal(base))
+		require.NotNil(t, got.EndTime)
+		assert.True(t, got.EndTime.Equal(end))
+
+		before := base.Add(-time.Hour)
+		assert.Error(t, db.UpdateMilestoneDates(milestone.ID, base, &before))
+	})
+
+	t.Run("delete keeps entries untagged", func(t *testing.T) {
+		db, milestone, _ := setup(t)
+		defer db.Close()
+
+		changed, err := db.DeleteMilestone(milestone.ID, false)
+		require.NoError(t, err)
+		assert.Equal(t, 3, changed)
+
+		count, err := db.CountEntries(EntryFilter{ProjectName: "p"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		tagged, err := db.GetEntriesByMilestone("p", milestone.Name)
+		require.NoError(t, err)
+		assert.Empty(t, tagged)
+
+		trashed, err := db.GetTrashedMilestones()
+		require.NoError(t, err)
+		assert.Len(t, trashed, 1)
+
+		// the name stays taken until the trash is emptied
+		_, err = db.CreateMilestone("p", milestone.Name)
+		assert.ErrorContains(t, err, "trash")
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+
+		tagged, err = db.GetEntriesByMilestone("p", milestone.Name)
+		require.NoError(t, err)
+		assert.Len(t, tagged, 3)
+	})
+
+	t.Run("delete with entries", func(t *testing.T) {
+		db, milestone, _ := setup(t)
+		defer db.Close()
+
+		changed, err := db.DeleteMilestone(milestone.ID, true)
+		require.NoError(t, err)
+		assert.Equal(t, 3, changed)
+
+		count, err := db.CountEntries(EntryFilter{ProjectName: "p"})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		trashed, err := db.GetTrashedEntries()
+		require.NoError(t, err)
+		assert.Len(t, trashed, 3)
+	})
+}
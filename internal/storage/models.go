@@ -13,6 +13,15 @@ type TimeEntry struct {
 	Description string
 	HourlyRate *float64
 	MilestoneName *string
+	// DeletedAt is set while the entry is in the trash.
+	DeletedAt *time.Time
+	// Source is how the entry was created, one of the Source constants, or
+	// empty for entries recorded before sources were tracked.
+	Source string
+	// CreatedAt is when the entry was recorded, nil for older entries.
+	CreatedAt *time.Time
+	// Edited reports whether the entry has any recorded edits.
+	Edited bool
 }
 
 func (t *TimeEntry) Duration() time.Duration {
@@ -39,6 +48,15 @@ type Milestone struct {
 	Name        string
 	StartTime   time.Time
 	EndTime     *time.Time
+	// DeletedAt is set while the milestone is in the trash.
+	DeletedAt   *time.Time
+	// BudgetHours, BudgetAmount and DueDate are the optional plan for the
+	// milestone; nil means no budget or deadline was set.
+	BudgetHours  *float64
+	BudgetAmount *float64
+	DueDate      *time.Time
+	// ParentID is the milestone this one is nested in, nil at the top level.
+	ParentID *int64
 }
 
 func (m *Milestone) IsActive() bool {
@@ -0,0 +1,481 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Milestone name collision policies for MoveEntries.
+const (
+	ConflictFail   = "fail"
+	ConflictMerge  = "merge"
+	ConflictRename = "rename"
+)
+
+// MoveRequest selects entries to move to another project.
+type MoveRequest struct {
+	FromProject string
+	ToProject   string
+	// MilestoneName limits the move to the entries of one milestone.
+	MilestoneName string
+	// Start and End limit the move to entries starting in [Start, End).
+	Start time.Time
+	End   time.Time
+	// OnConflict is how a milestone that already exists in the target
+	// project is handled: ConflictFail, ConflictMerge or ConflictRename.
+	OnConflict string
+}
+
+// MilestoneMove describes what a move does to one milestone of the source
+// project.
+type MilestoneMove struct {
+	Milestone *Milestone
+	// NewName is the milestone's name in the target project.
+	NewName string
+	// Collides is set when the target project already has a milestone with
+	// the same name, which the UNIQUE(project_name, name) constraint forbids.
+	Collides bool
+	// Whole is set when none of the milestone's entries stay behind, so the
+	// milestone itself moves. Otherwise it is copied to the target project.
+	Whole bool
+	// Finishes is set when the milestone would arrive active while the
+	// target project already has an active milestone. It is finished on
+	// arrival, so the target project keeps one active milestone per level.
+	Finishes bool
+
+	target *Milestone
+}
+
+// MovePlan lists what MoveEntries changes.
+type MovePlan struct {
+	Entries    []*TimeEntry
+	Milestones []*MilestoneMove
+}
+
+// Collisions returns the names of the milestones that already exist in the
+// target project.
+func (p *MovePlan) Collisions() []string {
+	var names []string
+	for _, m := range p.Milestones {
+		if m.Collides {
+			names = append(names, m.Milestone.Name)
+		}
+	}
+	return names
+}
+
+// MilestoneConflictError is returned when moved milestones collide with
+// milestones of the target project and no conflict policy was chosen.
+type MilestoneConflictError struct {
+	Project string
+	Names   []string
+}
+
+func (e *MilestoneConflictError) Error() string {
+	if len(e.Names) == 1 {
+		return fmt.Sprintf("milestone '%s' already exists in %s", e.Names[0], e.Project)
+	}
+	return fmt.Sprintf("milestones '%s' already exist in %s", strings.Join(e.Names, "', '"), e.Project)
+}
+
+// PlanMove returns what MoveEntries would change without changing anything.
+func (d *Database) PlanMove(req MoveRequest) (*MovePlan, error) {
+	return planMove(d.db, req)
+}
+
+// MoveEntries reassigns the matching entries to another project in one
+// operation. Milestones whose entries all move go with them, and the others
+// are copied so the moved entries keep their milestone. Active milestones
+// are finished when the target project already has an active milestone. A
+// snapshot is taken first.
+func (d *Database) MoveEntries(req MoveRequest) (*MovePlan, error) {
+	if _, err := d.Snapshot("move"); err != nil {
+		return nil, err
+	}
+
+	var plan *MovePlan
+	err := d.record(OpMove, func(j *journal) (string, error) {
+		var err error
+		plan, err = planMove(j.tx, req)
+		if err != nil {
+			return "", err
+		}
+
+		if collisions := plan.Collisions(); len(collisions) > 0 && req.OnConflict != ConflictMerge && req.OnConflict != ConflictRename {
+			return "", &MilestoneConflictError{Project: req.ToProject, Names: collisions}
+		}
+
+		newNames := make(map[string]string)
+		copies := make(map[int64]int64)
+		for _, m := range plan.Milestones {
+			copyID, err := applyMilestoneMove(j, req, m)
+			if err != nil {
+				return "", err
+			}
+			if copyID != 0 {
+				copies[m.Milestone.ID] = copyID
+			}
+			newNames[m.Milestone.Name] = m.NewName
+		}
+
+		// copies nested in a milestone that was copied too nest in its copy
+		for _, m := range plan.Milestones {
+			copyID, copied := copies[m.Milestone.ID]
+			if !copied || m.Milestone.ParentID == nil {
+				continue
+			}
+
+			if parentCopy, ok := copies[*m.Milestone.ParentID]; ok {
+				if _, err := j.tx.Exec("UPDATE milestones SET parent_id = ? WHERE id = ?", parentCopy, copyID); err != nil {
+					return "", fmt.Errorf("failed to update nested milestone: %w", err)
+				}
+			}
+		}
+
+		for _, entry := range plan.Entries {
+			if _, err := j.track(tableEntries, entry.ID); err != nil {
+				return "", err
+			}
+
+			milestone := entry.MilestoneName
+			if milestone != nil {
+				if name, ok := newNames[*milestone]; ok {
+					milestone = &name
+				}
+			}
+
+			if _, err := j.tx.Exec(
+				"UPDATE time_entries SET project_name = ?, milestone_name = ? WHERE id = ?",
+				req.ToProject, milestone, entry.ID,
+			); err != nil {
+				return "", fmt.Errorf("failed to move entry: %w", err)
+			}
+		}
+
+		if err := detachCrossProjectMilestones(j, req.FromProject, req.ToProject); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Moved %d entries from %s to %s", len(plan.Entries), req.FromProject, req.ToProject), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func planMove(q querier, req MoveRequest) (*MovePlan, error) {
+	if req.FromProject == "" || req.ToProject == "" {
+		return nil, fmt.Errorf("both a source and a target project are needed")
+	}
+
+	if req.FromProject == req.ToProject {
+		return nil, fmt.Errorf("source and target project are the same")
+	}
+
+	if req.MilestoneName != "" {
+		milestone, err := milestoneRow(q, req.FromProject, req.MilestoneName)
+		if err != nil {
+			return nil, err
+		}
+
+		if milestone == nil || milestone.DeletedAt != nil {
+			return nil, fmt.Errorf("milestone '%s' not found in project '%s'", req.MilestoneName, req.FromProject)
+		}
+	}
+
+	plan := &MovePlan{}
+	filter := EntryFilter{ProjectName: req.FromProject, MilestoneName: req.MilestoneName, Start: req.Start, End: req.End}
+	where, args := filter.where()
+
+	rows, err := q.Query("SELECT "+entryColumns+" FROM time_entries"+where+" ORDER BY start_time", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entries: %w", err)
+	}
+
+	moving := make(map[string]int)
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan entry: %w", err)
+		}
+
+		plan.Entries = append(plan.Entries, entry)
+		if entry.MilestoneName != nil {
+			moving[*entry.MilestoneName]++
+		}
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read entries: %w", err)
+	}
+
+	// Without a range, empty milestones of the project move as well
+	if req.Start.IsZero() && req.End.IsZero() {
+		names, err := milestoneNames(q, req.FromProject)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			if req.MilestoneName == "" || name == req.MilestoneName {
+				moving[name] += 0
+			}
+		}
+	}
+
+	// arriving active milestones can't sit next to an active one
+	targetActive, err := activeSibling(q, req.ToProject, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make(map[string]bool)
+	for name, count := range moving {
+		milestone, err := milestoneRow(q, req.FromProject, name)
+		if err != nil {
+			return nil, err
+		}
+
+		// Entries can name a milestone that no longer exists; they keep the name
+		if milestone == nil || milestone.DeletedAt != nil {
+			continue
+		}
+
+		var total int
+		if err := q.QueryRow(
+			"SELECT COUNT(*) FROM time_entries WHERE project_name = ? AND milestone_name = ? AND deleted_at IS NULL",
+			req.FromProject, name,
+		).Scan(&total); err != nil {
+			return nil, fmt.Errorf("failed to count milestone entries: %w", err)
+		}
+
+		target, err := milestoneRow(q, req.ToProject, name)
+		if err != nil {
+			return nil, err
+		}
+
+		move := &MilestoneMove{
+			Milestone: milestone,
+			NewName:   name,
+			Collides:  target != nil,
+			Whole:     total == count,
+			target:    target,
+		}
+
+		if move.Collides && req.OnConflict == ConflictRename {
+			if move.NewName, err = unusedMilestoneName(q, req.ToProject, fmt.Sprintf("%s (%s)", name, req.FromProject), taken); err != nil {
+				return nil, err
+			}
+		}
+		taken[move.NewName] = true
+
+		if move.Collides && move.NewName == name {
+			// merging only brings a milestone back when the target's is in the trash
+			if target.DeletedAt != nil && target.IsActive() {
+				move.Finishes = checkCanActivate(q, req.ToProject, target.ParentID, target.ID) != nil
+			}
+		} else {
+			move.Finishes = milestone.IsActive() && targetActive != nil
+		}
+
+		plan.Milestones = append(plan.Milestones, move)
+	}
+
+	sort.Slice(plan.Milestones, func(a, b int) bool {
+		return plan.Milestones[a].Milestone.StartTime.Before(plan.Milestones[b].Milestone.StartTime)
+	})
+
+	return plan, nil
+}
+
+// applyMilestoneMove moves, copies, renames or merges one milestone into
+// the target project. It returns the ID of the copy when the milestone is
+// copied.
+func applyMilestoneMove(j *journal, req MoveRequest, m *MilestoneMove) (int64, error) {
+	source := m.Milestone
+
+	if m.Collides && m.NewName == source.Name {
+		// Merging: entries join the target's milestone, brought back from
+		// the trash if needed, and a wholly moved source milestone goes away
+		if m.target.DeletedAt != nil {
+			if _, err := j.track(tableMilestones, m.target.ID); err != nil {
+				return 0, err
+			}
+			if _, err := j.tx.Exec("UPDATE milestones SET deleted_at = NULL WHERE id = ?", m.target.ID); err != nil {
+				return 0, fmt.Errorf("failed to restore milestone: %w", err)
+			}
+			if err := finishOnArrival(j, m, m.target.ID); err != nil {
+				return 0, err
+			}
+		}
+
+		if m.Whole {
+			if _, err := j.track(tableMilestones, source.ID); err != nil {
+				return 0, err
+			}
+			if _, err := j.tx.Exec("UPDATE milestones SET deleted_at = ? WHERE id = ?", time.Now(), source.ID); err != nil {
+				return 0, fmt.Errorf("failed to delete milestone: %w", err)
+			}
+		}
+
+		return 0, nil
+	}
+
+	if m.Whole {
+		if _, err := j.track(tableMilestones, source.ID); err != nil {
+			return 0, err
+		}
+
+		if _, err := j.tx.Exec(
+			"UPDATE milestones SET project_name = ?, name = ? WHERE id = ?",
+			req.ToProject, m.NewName, source.ID,
+		); err != nil {
+			return 0, fmt.Errorf("failed to move milestone: %w", err)
+		}
+
+		return 0, finishOnArrival(j, m, source.ID)
+	}
+
+	var endTime sql.NullTime
+	if source.EndTime != nil {
+		endTime = sql.NullTime{Time: *source.EndTime, Valid: true}
+	}
+
+	// The parent is fixed up once every milestone has arrived
+	result, err := j.tx.Exec(
+		"INSERT INTO milestones (project_name, name, start_time, end_time, budget_hours, budget_amount, due_date, parent_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		req.ToProject, m.NewName, source.StartTime, endTime, source.BudgetHours, source.BudgetAmount, source.DueDate, source.ParentID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy milestone: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	j.created(tableMilestones, id)
+
+	return id, finishOnArrival(j, m, id)
+}
+
+// finishOnArrival finishes the milestone id, which m brought into the target
+// project, when m.Finishes is set. The caller must have tracked or created
+// it.
+func finishOnArrival(j *journal, m *MilestoneMove, id int64) error {
+	if !m.Finishes {
+		return nil
+	}
+
+	if _, err := j.tx.Exec("UPDATE milestones SET end_time = ? WHERE id = ?", time.Now(), id); err != nil {
+		return fmt.Errorf("failed to finish milestone: %w", err)
+	}
+
+	return nil
+}
+
+// detachCrossProjectMilestones makes milestones of either project top-level
+// when the milestone they were nested in now belongs to another project.
+func detachCrossProjectMilestones(j *journal, projects ...string) error {
+	for _, projectName := range projects {
+		rows, err := j.tx.Query(`
+			SELECT child.id FROM milestones child
+			JOIN milestones parent ON child.parent_id = parent.id
+			WHERE child.project_name = ? AND parent.project_name != child.project_name
+		`, projectName)
+		if err != nil {
+			return fmt.Errorf("failed to query nested milestones: %w", err)
+		}
+
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan milestone: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read nested milestones: %w", err)
+		}
+
+		for _, id := range ids {
+			if _, err := j.track(tableMilestones, id); err != nil {
+				return err
+			}
+
+			if _, err := j.tx.Exec("UPDATE milestones SET parent_id = NULL WHERE id = ?", id); err != nil {
+				return fmt.Errorf("failed to update nested milestone: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// milestoneRow returns a project's milestone by name, including one in the
+// trash, or nil if there is none.
+func milestoneRow(q querier, projectName, name string) (*Milestone, error) {
+	milestone, err := scanMilestone(q.QueryRow(
+		"SELECT "+milestoneColumns+" FROM milestones WHERE project_name = ? AND name = ?",
+		projectName, name,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milestone: %w", err)
+	}
+
+	return milestone, nil
+}
+
+func milestoneNames(q querier, projectName string) ([]string, error) {
+	rows, err := q.Query("SELECT name FROM milestones WHERE project_name = ? AND deleted_at IS NULL", projectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query milestones: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan milestone: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// unusedMilestoneName returns base, or base with a number appended, that no
+// milestone of the project uses and that isn't already taken.
+func unusedMilestoneName(q querier, projectName, base string, taken map[string]bool) (string, error) {
+	name := base
+	for n := 2; ; n++ {
+		existing, err := milestoneRow(q, projectName, name)
+		if err != nil {
+			return "", err
+		}
+
+		if existing == nil && !taken[name] {
+			return name, nil
+		}
+
+		name = fmt.Sprintf("%s %d", base, n)
+	}
+}
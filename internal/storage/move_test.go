@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveEntries(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+	// setup creates two entries of "old" in milestone Sprint, one without a
+	// milestone and one of another project.
+	setup := func(t *testing.T) *Database {
+		db := setupTestDB(t)
+
+		_, err := db.CreateMilestone("old", "Sprint")
+		require.NoError(t, err)
+
+		sprint := "Sprint"
+		_, err = db.CreateManualEntry("old", "a", base, base.Add(time.Hour), nil, &sprint)
+		require.NoError(t, err)
+		_, err = db.CreateManualEntry("old", "b", base.AddDate(0, 0, 7), base.AddDate(0, 0, 7).Add(time.Hour), nil, &sprint)
+		require.NoError(t, err)
+		_, err = db.CreateManualEntry("old", "c", base.Add(2*time.Hour), base.Add(3*time.Hour), nil, nil)
+		require.NoError(t, err)
+		_, err = db.CreateManualEntry("other", "d", base.Add(4*time.Hour), base.Add(5*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		return db
+	}
+
+	projectCounts := func(t *testing.T, db *Database) map[string]int {
+		entries, err := db.GetEntries(0)
+		require.NoError(t, err)
+
+		counts := make(map[string]int)
+		for _, entry := range entries {
+			counts[entry.ProjectName]++
+		}
+		return counts
+	}
+
+	t.Run("moves every entry and milestone", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		plan, err := db.MoveEntries(MoveRequest{FromProject: "old", ToProject: "new"})
+		require.NoError(t, err)
+		assert.Len(t, plan.Entries, 3)
+		require.Len(t, plan.Milestones, 1)
+		assert.True(t, plan.Milestones[0].Whole)
+
+		assert.Equal(t, map[string]int{"new": 3, "other": 1}, projectCounts(t, db))
+
+		milestone, err := db.GetMilestoneByName("new", "Sprint")
+		require.NoError(t, err)
+		assert.NotNil(t, milestone)
+
+		milestone, err = db.GetMilestoneByName("old", "Sprint")
+		require.NoError(t, err)
+		assert.Nil(t, milestone)
+
+		edits, err := db.GetEntryEdits(plan.Entries[0].ID)
+		require.NoError(t, err)
+		require.Len(t, edits, 1)
+		assert.Equal(t, FieldProject, edits[0].Field)
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"old": 3, "other": 1}, projectCounts(t, db))
+
+		milestone, err = db.GetMilestoneByName("old", "Sprint")
+		require.NoError(t, err)
+		assert.NotNil(t, milestone)
+	})
+
+	t.Run("a range copies milestones with entries left behind", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		plan, err := db.MoveEntries(MoveRequest{FromProject: "old", ToProject: "new", Start: base, End: base.AddDate(0, 0, 1)})
+		require.NoError(t, err)
+		assert.Len(t, plan.Entries, 2)
+		require.Len(t, plan.Milestones, 1)
+		assert.False(t, plan.Milestones[0].Whole)
+
+		for _, project := range []string{"old", "new"} {
+			milestone, err := db.GetMilestoneByName(project, "Sprint")
+			require.NoError(t, err)
+			assert.NotNil(t, milestone, project)
+		}
+
+		entries, err := db.GetEntriesByMilestone("new", "Sprint")
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("only the named milestone", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		plan, err := db.MoveEntries(MoveRequest{FromProject: "old", ToProject: "new", MilestoneName: "Sprint"})
+		require.NoError(t, err)
+		assert.Len(t, plan.Entries, 2)
+		assert.Equal(t, map[string]int{"new": 2, "old": 1, "other": 1}, projectCounts(t, db))
+
+		_, err = db.PlanMove(MoveRequest{FromProject: "old", ToProject: "new", MilestoneName: "Missing"})
+		assert.Error(t, err)
+	})
+
+	t.Run("milestone collisions", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		_, err := db.CreateMilestone("new", "Sprint")
+		require.NoError(t, err)
+
+		plan, err := db.PlanMove(MoveRequest{FromProject: "old", ToProject: "new"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Sprint"}, plan.Collisions())
+
+		_, err = db.MoveEntries(MoveRequest{FromProject: "old", ToProject: "new"})
+		var conflictErr *MilestoneConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, map[string]int{"old": 3, "other": 1}, projectCounts(t, db))
+
+		plan, err = db.MoveEntries(MoveRequest{FromProject: "old", ToProject: "new", OnConflict: ConflictRename})
+		require.NoError(t, err)
+		assert.Equal(t, "Sprint (old)", plan.Milestones[0].NewName)
+
+		entries, err := db.GetEntriesByMilestone("new", "Sprint (old)")
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+
+		_, err = db.MoveEntries(MoveRequest{FromProject: "old", ToProject: "new", OnConflict: ConflictMerge})
+		require.NoError(t, err)
+
+		entries, err = db.GetEntriesByMilestone("new", "Sprint")
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+
+		milestones, err := db.GetMilestonesByProject("old")
+		require.NoError(t, err)
+		assert.Empty(t, milestones)
+	})
+
+	t.Run("active milestones are finished when the target has one", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		sprint, err := db.GetMilestoneByName("old", "Sprint")
+		require.NoError(t, err)
+		budget := 40.0
+		due := base.AddDate(0, 1, 0)
+		require.NoError(t, db.SetMilestonePlan(sprint.ID, MilestonePlan{BudgetHours: &budget, DueDate: &due}))
+
+		release, err := db.CreateMilestone("new", "Release")
+		require.NoError(t, err)
+
+		req := MoveRequest{FromProject: "old", ToProject: "new", Start: base, End: base.AddDate(0, 0, 1)}
+		plan, err := db.PlanMove(req)
+		require.NoError(t, err)
+		require.Len(t, plan.Milestones, 1)
+		assert.True(t, plan.Milestones[0].Finishes)
+
+		_, err = db.MoveEntries(req)
+		require.NoError(t, err)
+
+		chain, err := db.GetActiveMilestonesForProject("new")
+		require.NoError(t, err)
+		require.Len(t, chain, 1)
+		assert.Equal(t, release.ID, chain[0].ID)
+
+		copied, err := db.GetMilestoneByName("new", "Sprint")
+		require.NoError(t, err)
+		require.NotNil(t, copied)
+		assert.False(t, copied.IsActive())
+		require.NotNil(t, copied.BudgetHours)
+		assert.Equal(t, budget, *copied.BudgetHours)
+		require.NotNil(t, copied.DueDate)
+		assert.True(t, due.Equal(*copied.DueDate))
+
+		// the rest of the project moves the original
+		_, err = db.MoveEntries(MoveRequest{FromProject: "old", ToProject: "new", OnConflict: ConflictRename})
+		require.NoError(t, err)
+
+		chain, err = db.GetActiveMilestonesForProject("new")
+		require.NoError(t, err)
+		require.Len(t, chain, 1)
+		assert.Equal(t, release.ID, chain[0].ID)
+	})
+
+	t.Run("rejects moving a project onto itself", func(t *testing.T) {
+		db := setup(t)
+		defer db.Close()
+
+		_, err := db.MoveEntries(MoveRequest{FromProject: "old", ToProject: "old"})
+		assert.Error(t, err)
+	})
+}
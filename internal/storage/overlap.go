@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+)
+
+// overlapMargin widens the SQL prefilter for overlapping entries. Times are
+// compared as text, so rows stored with a different UTC offset can sort up to
+// a day away from their real position; the exact check happens in Go.
+const overlapMargin = 48 * time.Hour
+
+// querier is the subset of *sql.DB and *sql.Tx used by helpers that run
+// both inside and outside transactions.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// OverlapError is returned when an entry would overlap existing entries and
+// the overlap policy forbids it.
+type OverlapError struct {
+	Conflicts []*TimeEntry
+}
+
+func (e *OverlapError) Error() string {
+	ids := make([]string, len(e.Conflicts))
+	for i, entry := range e.Conflicts {
+		ids[i] = fmt.Sprintf("#%d", entry.ID)
+	}
+
+	return fmt.Sprintf("entry overlaps existing entries %s", strings.Join(ids, ", "))
+}
+
+// FindOverlappingEntries returns the entries, other than excludeID, whose
+// interval intersects [start, end), oldest first. A nil end and running
+// entries both extend to now.
+func (d *Database) FindOverlappingEntries(start time.Time, end *time.Time, excludeID int64) ([]*TimeEntry, error) {
+	return findOverlapping(d.db, start, end, excludeID)
+}
+
+func findOverlapping(q querier, start time.Time, end *time.Time, excludeID int64) ([]*TimeEntry, error) {
+	until := time.Now()
+	if end != nil {
+		until = *end
+	}
+
+	rows, err := q.Query(`
+		SELECT `+entryColumns+`
+		FROM time_entries
+		WHERE id != ? AND deleted_at IS NULL AND start_time < ? AND (end_time IS NULL OR end_time > ?)
+		ORDER BY start_time ASC
+	`, excludeID, until.Add(overlapMargin), start.Add(-overlapMargin))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overlapping entries: %w", err)
+	}
+
+	candidates, err := collectEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var overlapping []*TimeEntry
+	for _, entry := range candidates {
+		if entry.StartTime.Before(until) && effectiveEnd(entry).After(start) {
+			overlapping = append(overlapping, entry)
+		}
+	}
+
+	return overlapping, nil
+}
+
+// checkOverlaps returns an OverlapError when overlaps are rejected and
+// [start, end) intersects an entry other than excludeID.
+func (d *Database) checkOverlaps(start time.Time, end *time.Time, excludeID int64) error {
+	return d.checkOverlapsIn(d.db, start, end, excludeID)
+}
+
+// checkOverlapsIn is checkOverlaps run on q, so it can see the changes of a
+// transaction, ignoring every entry in excludeIDs.
+func (d *Database) checkOverlapsIn(q querier, start time.Time, end *time.Time, excludeIDs ...int64) error {
+	if !d.rejectOverlaps {
+		return nil
+	}
+
+	overlapping, err := findOverlapping(q, start, end, 0)
+	if err != nil {
+		return err
+	}
+
+	var conflicts []*TimeEntry
+	for _, entry := range overlapping {
+		if !slices.Contains(excludeIDs, entry.ID) {
+			conflicts = append(conflicts, entry)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return &OverlapError{Conflicts: conflicts}
+	}
+
+	return nil
+}
+
+// snapshotBeforeCarving takes a snapshot before an entry is saved with the
+// existing entries carved around it.
+func (d *Database) snapshotBeforeCarving(carve bool) error {
+	if !carve {
+		return nil
+	}
+
+	_, err := d.Snapshot("overlap")
+	return err
+}
+
+// TrimToFit returns the longest part of [start, end) that none of the
+// conflicts cover. ok is false when no time is left.
+func TrimToFit(start, end time.Time, conflicts []*TimeEntry) (time.Time, time.Time, bool) {
+	type gap struct{ start, end time.Time }
+	gaps := []gap{{start, end}}
+
+	for _, entry := range conflicts {
+		entryEnd := effectiveEnd(entry)
+
+		var next []gap
+		for _, g := range gaps {
+			if !entry.StartTime.Before(g.end) || !entryEnd.After(g.start) {
+				next = append(next, g)
+				continue
+			}
+
+			if entry.StartTime.After(g.start) {
+				next = append(next, gap{g.start, entry.StartTime})
+			}
+			if entryEnd.Before(g.end) {
+				next = append(next, gap{entryEnd, g.end})
+			}
+		}
+		gaps = next
+	}
+
+	if len(gaps) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	longest := gaps[0]
+	for _, g := range gaps[1:] {
+		if g.end.Sub(g.start) > longest.end.Sub(longest.start) {
+			longest = g
+		}
+	}
+
+	return longest.start, longest.end, true
+}
+
+// CarveOut removes [start, end) from every entry other than excludeID.
+// Entries that straddle the interval are split in two and entries inside it
+// are moved to the trash, after taking a snapshot. It returns the number of
+// entries changed.
+func (d *Database) CarveOut(start, end time.Time, excludeID int64) (int, error) {
+	if _, err := d.Snapshot("overlap"); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	err := d.record(OpResolveOverlap, func(j *journal) (string, error) {
+		var err error
+		if changed, err = carveOverlaps(j, start, end, excludeID); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Trimmed entries overlapping %s", settings.FormatDateTimeDashed(start)), nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return changed, nil
+}
+
+// carveOverlaps removes [start, end) from every entry other than excludeID
+// within an operation. It returns the number of entries changed.
+func carveOverlaps(j *journal, start, end time.Time, excludeID int64) (int, error) {
+	conflicts, err := findOverlapping(j.tx, start, &end, excludeID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range conflicts {
+		if err := carveEntry(j, entry, start, end); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(conflicts), nil
+}
+
+// carveEntry removes [start, end) from a single entry.
+func carveEntry(j *journal, entry *TimeEntry, start, end time.Time) error {
+	if _, err := j.track(tableEntries, entry.ID); err != nil {
+		return err
+	}
+
+	keepsHead := entry.StartTime.Before(start)
+	keepsTail := entry.EndTime == nil || entry.EndTime.After(end)
+
+	switch {
+	case keepsHead && keepsTail:
+		if _, err := insertEntryPart(j, entry, end, entry.EndTime, entry.Description); err != nil {
+			return err
+		}
+
+		if _, err := j.tx.Exec("UPDATE time_entries SET end_time = ? WHERE id = ?", start, entry.ID); err != nil {
+			return fmt.Errorf("failed to trim entry: %w", err)
+		}
+	case keepsHead:
+		if _, err := j.tx.Exec("UPDATE time_entries SET end_time = ? WHERE id = ?", start, entry.ID); err != nil {
+			return fmt.Errorf("failed to trim entry: %w", err)
+		}
+	case keepsTail:
+		if _, err := j.tx.Exec("UPDATE time_entries SET start_time = ? WHERE id = ?", end, entry.ID); err != nil {
+			return fmt.Errorf("failed to trim entry: %w", err)
+		}
+	default:
+		if err := trashEntry(j, entry.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
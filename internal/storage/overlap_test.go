@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOverlappingEntries(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	at := func(hours float64) time.Time {
+		return base.Add(time.Duration(hours * float64(time.Hour)))
+	}
+
+	morning, err := db.CreateManualEntry("p", "", at(0), at(2), nil, nil)
+	require.NoError(t, err)
+	afternoon, err := db.CreateManualEntry("q", "", at(4), at(6), nil, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		start     time.Time
+		end       time.Time
+		excludeID int64
+		want      []int64
+	}{
+		{"no overlap", at(2), at(4), 0, nil},
+		{"touching edges do not overlap", at(-1), at(0), 0, nil},
+		{"overlaps one", at(1), at(3), 0, []int64{morning.ID}},
+		{"spans both, oldest first", at(-1), at(7), 0, []int64{morning.ID, afternoon.ID}},
+		{"contained in one", at(4.5), at(5), 0, []int64{afternoon.ID}},
+		{"excludes the entry being edited", at(1), at(5), morning.ID, []int64{afternoon.ID}},
+		{"other timezone", at(1).In(time.FixedZone("X", 10*3600)), at(1.5).In(time.FixedZone("X", 10*3600)), 0, []int64{morning.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := tt.end
+			conflicts, err := db.FindOverlappingEntries(tt.start, &end, tt.excludeID)
+			require.NoError(t, err)
+
+			var ids []int64
+			for _, entry := range conflicts {
+				ids = append(ids, entry.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+
+	t.Run("running entries extend to now", func(t *testing.T) {
+		running, err := db.CreateEntry("p", "", nil, nil)
+		require.NoError(t, err)
+
+		end := time.Now().Add(time.Hour)
+		conflicts, err := db.FindOverlappingEntries(time.Now().Add(-time.Minute), &end, 0)
+		require.NoError(t, err)
+		require.Len(t, conflicts, 1)
+		assert.Equal(t, running.ID, conflicts[0].ID)
+	})
+}
+
+func TestTrimToFit(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	at := func(hours float64) time.Time {
+		return base.Add(time.Duration(hours * float64(time.Hour)))
+	}
+	entry := func(start, end float64) *TimeEntry {
+		e := at(end)
+		return &TimeEntry{StartTime: at(start), EndTime: &e}
+	}
+
+	tests := []struct {
+		name      string
+		conflicts []*TimeEntry
+		wantStart time.Time
+		wantEnd   time.Time
+		wantOK    bool
+	}{
+		{"overlap at start", []*TimeEntry{entry(-1, 1)}, at(1), at(4), true},
+		{"overlap at end", []*TimeEntry{entry(3, 5)}, at(0), at(3), true},
+		{"keeps the longest gap", []*TimeEntry{entry(1, 2)}, at(2), at(4), true},
+		{"gap between two conflicts", []*TimeEntry{entry(-1, 1), entry(3, 5)}, at(1), at(3), true},
+		{"fully covered", []*TimeEntry{entry(-1, 5)}, time.Time{}, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := TrimToFit(at(0), at(4), tt.conflicts)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.True(t, start.Equal(tt.wantStart), "start %v", start)
+				assert.True(t, end.Equal(tt.wantEnd), "end %v", end)
+			}
+		})
+	}
+}
+
+func TestCarveOut(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	at := func(hours float64) time.Time {
+		return base.Add(time.Duration(hours * float64(time.Hour)))
+	}
+
+	rate := 50.0
+	milestone := "M"
+	straddles, err := db.CreateManualEntry("p", "long", at(0), at(10), &rate, &milestone)
+	require.NoError(t, err)
+	head, err := db.CreateManualEntry("q", "", at(11), at(13), nil, nil)
+	require.NoError(t, err)
+	inside, err := db.CreateManualEntry("q", "", at(14), at(15), nil, nil)
+	require.NoError(t, err)
+	tail, err := db.CreateManualEntry("q", "", at(16), at(18), nil, nil)
+	require.NoError(t, err)
+
+	changed, err := db.CarveOut(at(2), at(4), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	changed, err = db.CarveOut(at(12), at(17), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, changed)
+
+	got, err := db.GetEntry(straddles.ID)
+	require.NoError(t, err)
+	assert.True(t, got.EndTime.Equal(at(2)))
+
+	remainder, err := db.GetEntriesByFilter(EntryFilter{ProjectName: "p", Start: at(4), End: at(5)})
+	require.NoError(t, err)
+	require.Len(t, remainder, 1)
+	assert.True(t, remainder[0].EndTime.Equal(at(10)))
+	assert.Equal(t, "long", remainder[0].Description)
+	assert.Equal(t, rate, *remainder[0].HourlyRate)
+	assert.Equal(t, milestone, *remainder[0].MilestoneName)
+
+	got, err = db.GetEntry(head.ID)
+	require.NoError(t, err)
+	assert.True(t, got.EndTime.Equal(at(12)))
+
+	_, err = db.GetEntry(inside.ID)
+	assert.Error(t, err)
+
+	got, err = db.GetEntry(tail.ID)
+	require.NoError(t, err)
+	assert.True(t, got.StartTime.Equal(at(17)))
+}
+
+func TestSaveCarving(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+	t.Run("manual entry and carve are one operation", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		db.rejectOverlaps = true
+		existing, err := db.CreateManualEntry("p", "", base, base.Add(3*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		entry, err := db.CreateManualEntryCarving("q", "", base.Add(time.Hour), base.Add(2*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		entries, err := db.GetEntries(0)
+		require.NoError(t, err)
+		assert.Len(t, entries, 3)
+
+		got, err := db.GetEntry(existing.ID)
+		require.NoError(t, err)
+		assert.True(t, got.EndTime.Equal(entry.StartTime))
+
+		op, err := db.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, OpManual, op.Kind)
+
+		entries, err = db.GetEntries(0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.True(t, entries[0].EndTime.Equal(base.Add(3*time.Hour)))
+	})
+
+	t.Run("a failed save leaves the other entries alone", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		existing, err := db.CreateManualEntry("p", "", base, base.Add(3*time.Hour), nil, nil)
+		require.NoError(t, err)
+		edited, err := db.CreateManualEntry("q", "", base.Add(4*time.Hour), base.Add(5*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		_, err = db.db.Exec("CREATE TRIGGER fail_update BEFORE UPDATE OF project_name ON time_entries WHEN NEW.project_name = 'broken' BEGIN SELECT RAISE(ABORT, 'broken'); END")
+		require.NoError(t, err)
+
+		edited.ProjectName = "broken"
+		edited.StartTime = base.Add(time.Hour)
+		assert.Error(t, db.UpdateTimeEntryCarving(edited.ID, edited))
+
+		got, err := db.GetEntry(existing.ID)
+		require.NoError(t, err)
+		assert.True(t, got.EndTime.Equal(base.Add(3*time.Hour)))
+
+		entries, err := db.GetEntries(0)
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+}
+
+func TestOverlapPolicy(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	existing, err := db.CreateManualEntry("p", "", base, base.Add(2*time.Hour), nil, nil)
+	require.NoError(t, err)
+
+	// overlaps are allowed by default
+	other, err := db.CreateManualEntry("p", "", base.Add(time.Hour), base.Add(3*time.Hour), nil, nil)
+	require.NoError(t, err)
+
+	db.rejectOverlaps = true
+
+	_, err = db.CreateManualEntry("p", "", base.Add(30*time.Minute), base.Add(90*time.Minute), nil, nil)
+	var overlapErr *OverlapError
+	require.True(t, errors.As(err, &overlapErr))
+	assert.Len(t, overlapErr.Conflicts, 2)
+	assert.Contains(t, err.Error(), "#1")
+
+	// the entry itself doesn't count as a conflict when updating
+	end := base.Add(90 * time.Minute)
+	existing.EndTime = &end
+	err = db.UpdateTimeEntry(existing.ID, existing)
+	require.True(t, errors.As(err, &overlapErr))
+	assert.Equal(t, other.ID, overlapErr.Conflicts[0].ID)
+
+	end = base.Add(time.Hour)
+	other.StartTime = base.Add(2 * time.Hour)
+	assert.NoError(t, db.UpdateTimeEntry(other.ID, other))
+	assert.NoError(t, db.UpdateTimeEntry(existing.ID, existing))
+
+	_, err = db.CreateManualEntry("p", "", base.Add(-time.Hour), base, nil, nil)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+)
+
+// ScheduleSync lists the milestones SyncMilestoneSchedule changed.
+type ScheduleSync struct {
+	Started  []string
+	Finished []string
+	Reopened []string
+	// Updated are existing milestones whose dates were set to the schedule.
+	Updated []string
+	// Skipped explains why scheduled milestones were left alone.
+	Skipped []string
+}
+
+// Changed reports whether the sync changed any milestone.
+func (s *ScheduleSync) Changed() bool {
+	return len(s.Started)+len(s.Finished)+len(s.Reopened)+len(s.Updated) > 0
+}
+
+// SyncMilestoneSchedule starts the scheduled milestones of a project whose
+// start has passed and finishes those whose end has passed, at the scheduled
+// times. A scheduled milestone that was never started is created, finished
+// if its end has passed too. With align set, milestones that already exist
+// are also given their scheduled dates, and one finished before its end is
+// reopened. Milestones that aren't scheduled are left alone.
+func (d *Database) SyncMilestoneSchedule(projectName string, schedule []settings.ScheduledMilestone, now time.Time, align bool) (*ScheduleSync, error) {
+	loc := now.Location()
+	if err := settings.ValidateSchedule(schedule, loc); err != nil {
+		return nil, err
+	}
+
+	type scheduled struct {
+		name       string
+		start, end time.Time
+	}
+
+	var planned []scheduled
+	for _, m := range schedule {
+		start, end, err := m.Span(loc)
+		if err != nil {
+			return nil, err
+		}
+		planned = append(planned, scheduled{m.Name, start, end})
+	}
+
+	// earlier milestones finish before later ones start
+	sort.Slice(planned, func(a, b int) bool {
+		return planned[a].start.Before(planned[b].start)
+	})
+
+	// aligning can rewrite the dates of every scheduled milestone
+	if align {
+		if _, err := d.Snapshot("milestone-sync"); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &ScheduleSync{}
+	err := d.record(OpMilestoneSync, func(j *journal) (string, error) {
+		for _, s := range planned {
+			if now.Before(s.start) {
+				continue
+			}
+
+			existing, err := milestoneRow(j.tx, projectName, s.name)
+			if err != nil {
+				return "", err
+			}
+
+			over := !now.Before(s.end)
+
+			switch {
+			case existing != nil && existing.DeletedAt != nil:
+				result.Skipped = append(result.Skipped, fmt.Sprintf("'%s' is in the trash", s.name))
+
+			case existing == nil:
+				var endTime sql.NullTime
+				if over {
+					endTime = sql.NullTime{Time: s.end, Valid: true}
+				} else {
+					active, err := activeSibling(j.tx, projectName, nil, 0)
+					if err != nil {
+						return "", err
+					}
+
+					if active != nil {
+						result.Skipped = append(result.Skipped, fmt.Sprintf("'%s' wasn't started because '%s' is active", s.name, active.Name))
+						continue
+					}
+				}
+
+				res, err := j.tx.Exec(
+					"INSERT INTO milestones (project_name, name, start_time, end_time) VALUES (?, ?, ?, ?)",
+					projectName, s.name, s.start, endTime,
+				)
+				if err != nil {
+					return "", fmt.Errorf("failed to create milestone: %w", err)
+				}
+
+				id, err := res.LastInsertId()
+				if err != nil {
+					return "", fmt.Errorf("failed to get last insert id: %w", err)
+				}
+				j.created(tableMilestones, id)
+
+				result.Started = append(result.Started, s.name)
+				if over {
+					result.Finished = append(result.Finished, s.name)
+				}
+
+			case existing.IsActive() && over:
+				if align && !existing.StartTime.Equal(s.start) {
+					if err := setMilestoneStart(j, existing.ID, s.start); err != nil {
+						return "", err
+					}
+					existing.StartTime = s.start
+				}
+
+				if err := finishMilestoneAt(j, existing, s.end, now); err != nil {
+					return "", err
+				}
+				result.Finished = append(result.Finished, s.name)
+
+			case existing.IsActive():
+				if align && !existing.StartTime.Equal(s.start) {
+					if err := setMilestoneStart(j, existing.ID, s.start); err != nil {
+						return "", err
+					}
+					result.Updated = append(result.Updated, s.name)
+				}
+
+			case over:
+				if align && (!existing.StartTime.Equal(s.start) || !existing.EndTime.Equal(s.end)) {
+					if _, err := j.track(tableMilestones, existing.ID); err != nil {
+						return "", err
+					}
+
+					if _, err := j.tx.Exec("UPDATE milestones SET start_time = ?, end_time = ? WHERE id = ?", s.start, s.end, existing.ID); err != nil {
+						return "", fmt.Errorf("failed to update milestone: %w", err)
+					}
+					result.Updated = append(result.Updated, s.name)
+				}
+
+			case align:
+				// finished before its scheduled end
+				if err := checkCanActivate(j.tx, projectName, existing.ParentID, existing.ID); err != nil {
+					result.Skipped = append(result.Skipped, fmt.Sprintf("'%s' wasn't reopened: %v", s.name, err))
+					continue
+				}
+
+				if _, err := j.track(tableMilestones, existing.ID); err != nil {
+					return "", err
+				}
+
+				if _, err := j.tx.Exec("UPDATE milestones SET start_time = ?, end_time = NULL WHERE id = ?", s.start, existing.ID); err != nil {
+					return "", fmt.Errorf("failed to reopen milestone: %w", err)
+				}
+				result.Reopened = append(result.Reopened, s.name)
+			}
+		}
+
+		return fmt.Sprintf("Synced scheduled milestones of %s", projectName), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func setMilestoneStart(j *journal, id int64, start time.Time) error {
+	if _, err := j.track(tableMilestones, id); err != nil {
+		return err
+	}
+
+	if _, err := j.tx.Exec("UPDATE milestones SET start_time = ? WHERE id = ?", start, id); err != nil {
+		return fmt.Errorf("failed to update milestone: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncMilestoneSchedule(t *testing.T) {
+	schedule := []settings.ScheduledMilestone{
+		{Name: "Sprint 1", Start: "2024-03-04", End: "2024-03-15"},
+		{Name: "Sprint 2", Start: "2024-03-18", End: "2024-03-29"},
+		{Name: "Sprint 3", Start: "2024-04-01", End: "2024-04-12"},
+	}
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2024, month, d, 0, 0, 0, 0, time.Local)
+	}
+
+	t.Run("starts and finishes milestones at their boundaries", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		result, err := db.SyncMilestoneSchedule("p", schedule, day(3, 5).Add(10*time.Hour), false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Sprint 1"}, result.Started)
+		assert.Empty(t, result.Finished)
+
+		active, err := db.GetActiveMilestoneForProject("p")
+		require.NoError(t, err)
+		require.NotNil(t, active)
+		assert.Equal(t, "Sprint 1", active.Name)
+		assert.True(t, active.StartTime.Equal(day(3, 4)))
+
+		// the first command after the weekend moves on to the next sprint
+		result, err = db.SyncMilestoneSchedule("p", schedule, day(3, 18).Add(9*time.Hour), false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Sprint 2"}, result.Started)
+		assert.Equal(t, []string{"Sprint 1"}, result.Finished)
+
+		first, err := db.GetMilestoneByName("p", "Sprint 1")
+		require.NoError(t, err)
+		require.NotNil(t, first.EndTime)
+		assert.True(t, first.EndTime.Equal(day(3, 16)), "finished at the end of its last day")
+
+		// nothing left to do
+		result, err = db.SyncMilestoneSchedule("p", schedule, day(3, 18).Add(10*time.Hour), false)
+		require.NoError(t, err)
+		assert.False(t, result.Changed())
+	})
+
+	t.Run("creates milestones that were missed entirely", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		result, err := db.SyncMilestoneSchedule("p", schedule, day(4, 2), false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Sprint 1", "Sprint 2", "Sprint 3"}, result.Started)
+		assert.Equal(t, []string{"Sprint 1", "Sprint 2"}, result.Finished)
+
+		milestones, err := db.GetMilestonesByProject("p")
+		require.NoError(t, err)
+		assert.Len(t, milestones, 3)
+
+		// a single operation to undo
+		_, err = db.Undo()
+		require.NoError(t, err)
+		milestones, err = db.GetMilestonesByProject("p")
+		require.NoError(t, err)
+		assert.Empty(t, milestones)
+	})
+
+	t.Run("leaves a manually started milestone alone", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		_, err := db.CreateMilestone("p", "Hotfix")
+		require.NoError(t, err)
+
+		result, err := db.SyncMilestoneSchedule("p", schedule, day(3, 5), false)
+		require.NoError(t, err)
+		assert.Empty(t, result.Started)
+		require.Len(t, result.Skipped, 1)
+		assert.Contains(t, result.Skipped[0], "Hotfix")
+	})
+
+	t.Run("align reconciles existing milestones with the schedule", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		_, err := db.SyncMilestoneSchedule("p", schedule, day(3, 19), false)
+		require.NoError(t, err)
+
+		// finished early by hand
+		second, err := db.GetMilestoneByName("p", "Sprint 2")
+		require.NoError(t, err)
+		require.NoError(t, db.FinishMilestone(second.ID))
+
+		// the calendar was changed after Sprint 1 finished
+		changed := append([]settings.ScheduledMilestone{{Name: "Sprint 1", Start: "2024-03-04", End: "2024-03-14"}}, schedule[1:]...)
+
+		result, err := db.SyncMilestoneSchedule("p", changed, day(3, 19), false)
+		require.NoError(t, err)
+		assert.False(t, result.Changed(), "only boundaries are acted on without align")
+
+		result, err = db.SyncMilestoneSchedule("p", changed, day(3, 19), true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Sprint 1"}, result.Updated)
+		assert.Equal(t, []string{"Sprint 2"}, result.Reopened)
+
+		first, err := db.GetMilestoneByName("p", "Sprint 1")
+		require.NoError(t, err)
+		assert.True(t, first.EndTime.Equal(day(3, 15)))
+
+		active, err := db.GetActiveMilestoneForProject("p")
+		require.NoError(t, err)
+		assert.Equal(t, "Sprint 2", active.Name)
+	})
+
+	t.Run("rejects an invalid schedule", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		_, err := db.SyncMilestoneSchedule("p", []settings.ScheduledMilestone{{Name: "Sprint 1", Start: "2024-03-04", End: "soon"}}, day(3, 5), false)
+		assert.ErrorContains(t, err, "invalid end")
+	})
+}
@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DylanDevelops/tmpo/internal/settings"
+)
+
+// SplitEntry splits an entry in two at the given time. The first part keeps
+// the entry's ID and the second part is a new entry with the same project,
+// rate and milestone. The second part keeps the description unless one is
+// given. Running entries can be split; the second part keeps running.
+func (d *Database) SplitEntry(id int64, at time.Time, description *string) (*TimeEntry, *TimeEntry, error) {
+	var partID int64
+	err := d.record(OpSplit, func(j *journal) (string, error) {
+		entry, err := trackLiveEntry(j, id)
+		if err != nil {
+			return "", err
+		}
+
+		end := time.Now()
+		if entry.EndTime != nil {
+			end = *entry.EndTime
+		}
+
+		if !at.After(entry.StartTime) || !at.Before(end) {
+			return "", fmt.Errorf("split time must be between the entry's start (%s) and end (%s)",
+				settings.FormatDateTimeDashed(entry.StartTime), settings.FormatDateTimeDashed(end))
+		}
+
+		partDescription := entry.Description
+		if description != nil {
+			partDescription = *description
+		}
+
+		partID, err = insertEntryPart(j, entry, at, entry.EndTime, partDescription)
+		if err != nil {
+			return "", err
+		}
+
+		if _, err := j.tx.Exec("UPDATE time_entries SET end_time = ? WHERE id = ?", at, id); err != nil {
+			return "", fmt.Errorf("failed to split entry: %w", err)
+		}
+
+		return fmt.Sprintf("Split entry #%d at %s", id, settings.FormatDateTimeDashed(at)), nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	first, err := d.GetEntry(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	second, err := d.GetEntry(partID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return first, second, nil
+}
+
+// ValidateMerge checks that entries can be merged: there are at least two,
+// none are running and they share a project, hourly rate and milestone.
+func ValidateMerge(entries []*TimeEntry) error {
+	if len(entries) < 2 {
+		return fmt.Errorf("at least two entries are needed to merge")
+	}
+
+	first := entries[0]
+	for _, entry := range entries {
+		if entry.IsRunning() {
+			return fmt.Errorf("entry #%d is still running, stop it first", entry.ID)
+		}
+
+		if entry.ProjectName != first.ProjectName {
+			return fmt.Errorf("entries belong to different projects (%s and %s)", first.ProjectName, entry.ProjectName)
+		}
+
+		if !floatEqual(entry.HourlyRate, first.HourlyRate) {
+			return fmt.Errorf("entries #%d and #%d have different hourly rates", first.ID, entry.ID)
+		}
+
+		if !textEqual(entry.MilestoneName, first.MilestoneName) {
+			return fmt.Errorf("entries #%d and #%d belong to different milestones", first.ID, entry.ID)
+		}
+	}
+
+	return nil
+}
+
+// MergeEntries joins entries of one project into the earliest of them, which
+// then runs from the first start to the last end with their descriptions
+// combined. The other entries are moved to the trash, after taking a
+// snapshot. The entries must be
+// adjacent: no other entry, of any project, may lie in the gaps between them.
+func (d *Database) MergeEntries(ids []int64) (*TimeEntry, error) {
+	if _, err := d.Snapshot("merge"); err != nil {
+		return nil, err
+	}
+
+	var firstID int64
+	err := d.record(OpMerge, func(j *journal) (string, error) {
+		seen := make(map[int64]bool)
+		var entries []*TimeEntry
+
+		for _, id := range ids {
+			if seen[id] {
+				return "", fmt.Errorf("entry #%d is listed more than once", id)
+			}
+			seen[id] = true
+
+			entry, err := trackLiveEntry(j, id)
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, entry)
+		}
+
+		if err := ValidateMerge(entries); err != nil {
+			return "", err
+		}
+
+		sort.SliceStable(entries, func(a, b int) bool {
+			return entries[a].StartTime.Before(entries[b].StartTime)
+		})
+
+		first := entries[0]
+		end := *first.EndTime
+		var descriptions []string
+		for _, entry := range entries {
+			if entry.EndTime.After(end) {
+				end = *entry.EndTime
+			}
+
+			if entry.Description != "" && !slices.Contains(descriptions, entry.Description) {
+				descriptions = append(descriptions, entry.Description)
+			}
+		}
+
+		// the gaps between the entries become part of the merged one
+		reach := *first.EndTime
+		for _, entry := range entries[1:] {
+			if entry.StartTime.After(reach) {
+				gapEnd := entry.StartTime
+				between, err := findOverlapping(j.tx, reach, &gapEnd, 0)
+				if err != nil {
+					return "", err
+				}
+
+				for _, other := range between {
+					if !seen[other.ID] {
+						return "", fmt.Errorf("entry #%d of %s lies between the entries, include it or merge fewer entries", other.ID, other.ProjectName)
+					}
+				}
+			}
+
+			if entry.EndTime.After(reach) {
+				reach = *entry.EndTime
+			}
+		}
+
+		if err := d.checkOverlapsIn(j.tx, first.StartTime, &end, ids...); err != nil {
+			return "", err
+		}
+
+		if _, err := j.tx.Exec(
+			"UPDATE time_entries SET end_time = ?, description = ? WHERE id = ?",
+			end, strings.Join(descriptions, "; "), first.ID,
+		); err != nil {
+			return "", fmt.Errorf("failed to merge entries: %w", err)
+		}
+
+		for _, entry := range entries[1:] {
+			if err := trashEntry(j, entry.ID); err != nil {
+				return "", err
+			}
+		}
+
+		firstID = first.ID
+		return fmt.Sprintf("Merged %d entries into #%d in %s", len(entries), first.ID, first.ProjectName), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d.GetEntry(firstID)
+}
+
+// DuplicateEntry creates a completed entry from start to end with the
+// project, description, rate and milestone of an existing entry.
+func (d *Database) DuplicateEntry(id int64, start, end time.Time) (*TimeEntry, error) {
+	return d.duplicateEntry(id, start, end, false)
+}
+
+// DuplicateEntryCarving duplicates an entry after trimming or splitting the
+// entries the copy overlaps, as CarveOut does, in a single operation.
+func (d *Database) DuplicateEntryCarving(id int64, start, end time.Time) (*TimeEntry, error) {
+	return d.duplicateEntry(id, start, end, true)
+}
+
+func (d *Database) duplicateEntry(id int64, start, end time.Time, carve bool) (*TimeEntry, error) {
+	if err := d.snapshotBeforeCarving(carve); err != nil {
+		return nil, err
+	}
+
+	var copyID int64
+	err := d.record(OpDuplicate, func(j *journal) (string, error) {
+		source, err := auditedEntry(j.tx, id)
+		if err != nil {
+			return "", err
+		}
+
+		if source == nil || source.DeletedAt != nil {
+			return "", fmt.Errorf("entry #%d not found", id)
+		}
+
+		if carve {
+			if _, err := carveOverlaps(j, start, end, 0); err != nil {
+				return "", err
+			}
+		} else if err := d.checkOverlapsIn(j.tx, start, &end); err != nil {
+			return "", err
+		}
+
+		duplicate := &TimeEntry{
+			ProjectName:   source.ProjectName,
+			StartTime:     start,
+			EndTime:       &end,
+			Description:   source.Description,
+			HourlyRate:    source.HourlyRate,
+			MilestoneName: source.MilestoneName,
+		}
+
+		copyID, err = insertCompletedEntry(j, duplicate, SourceDuplicate)
+		if err != nil {
+			return "", err
+		}
+
+		j.created(tableEntries, copyID)
+		return fmt.Sprintf("Duplicated entry #%d as #%d", id, copyID), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d.GetEntry(copyID)
+}
+
+// trackLiveEntry tracks an entry for a change and returns it, failing if it
+// doesn't exist or is in the trash.
+func trackLiveEntry(j *journal, id int64) (*TimeEntry, error) {
+	if _, err := j.track(tableEntries, id); err != nil {
+		return nil, err
+	}
+
+	entry := j.entries[id]
+	if entry == nil || entry.DeletedAt != nil {
+		return nil, fmt.Errorf("entry #%d not found", id)
+	}
+
+	return entry, nil
+}
+
+// insertEntryPart inserts a new entry covering start to end that keeps the
+// project, rate, milestone, source and creation time of entry.
+func insertEntryPart(j *journal, entry *TimeEntry, start time.Time, end *time.Time, description string) (int64, error) {
+	result, err := j.tx.Exec(
+		"INSERT INTO time_entries (project_name, start_time, end_time, description, hourly_rate, milestone_name, source, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		entry.ProjectName, start, end, description, entry.HourlyRate, entry.MilestoneName,
+		sql.NullString{String: entry.Source, Valid: entry.Source != ""}, entry.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to split entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	j.created(tableEntries, id)
+
+	return id, nil
+}
+
+func floatEqual(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
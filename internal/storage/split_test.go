@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitEntry(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	milestone := "Sprint"
+
+	t.Run("splits into two parts", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "all day", base, base.Add(8*time.Hour), floatPtr(90), &milestone)
+		require.NoError(t, err)
+
+		description := "afternoon"
+		first, second, err := db.SplitEntry(entry.ID, base.Add(4*time.Hour), &description)
+		require.NoError(t, err)
+
+		assert.Equal(t, entry.ID, first.ID)
+		assert.Equal(t, 4*time.Hour, first.Duration())
+		assert.Equal(t, "all day", first.Description)
+
+		assert.True(t, base.Add(4*time.Hour).Equal(second.StartTime))
+		assert.True(t, base.Add(8*time.Hour).Equal(*second.EndTime))
+		assert.Equal(t, "afternoon", second.Description)
+		assert.Equal(t, 90.0, *second.HourlyRate)
+		assert.Equal(t, "Sprint", *second.MilestoneName)
+		assert.Equal(t, SourceManual, second.Source)
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+
+		entries, err := db.GetEntries(0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, 8*time.Hour, entries[0].Duration())
+	})
+
+	t.Run("running entries keep running", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateEntry("p", "", nil, nil)
+		require.NoError(t, err)
+
+		started := time.Now().Add(-time.Hour)
+		_, err = db.db.Exec("UPDATE time_entries SET start_time = ? WHERE id = ?", started, entry.ID)
+		require.NoError(t, err)
+
+		_, second, err := db.SplitEntry(entry.ID, started.Add(30*time.Minute), nil)
+		require.NoError(t, err)
+		assert.True(t, second.IsRunning())
+	})
+
+	t.Run("split time must be inside the entry", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		_, _, err = db.SplitEntry(entry.ID, base, nil)
+		assert.Error(t, err)
+		_, _, err = db.SplitEntry(entry.ID, base.Add(2*time.Hour), nil)
+		assert.Error(t, err)
+		_, _, err = db.SplitEntry(999, base.Add(30*time.Minute), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestMergeEntries(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+	t.Run("joins fragments into the earliest", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		first, err := db.CreateManualEntry("p", "coding", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		second, err := db.CreateManualEntry("p", "coding", base.Add(75*time.Minute), base.Add(2*time.Hour), nil, nil)
+		require.NoError(t, err)
+		third, err := db.CreateManualEntry("p", "review", base.Add(2*time.Hour), base.Add(3*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		merged, err := db.MergeEntries([]int64{third.ID, first.ID, second.ID})
+		require.NoError(t, err)
+
+		assert.Equal(t, first.ID, merged.ID)
+		assert.True(t, base.Equal(merged.StartTime))
+		assert.True(t, base.Add(3*time.Hour).Equal(*merged.EndTime))
+		assert.Equal(t, "coding; review", merged.Description)
+
+		entries, err := db.GetEntries(0)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		trashed, err := db.GetTrashedEntries()
+		require.NoError(t, err)
+		assert.Len(t, trashed, 2)
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+
+		entries, err = db.GetEntries(0)
+		require.NoError(t, err)
+		assert.Len(t, entries, 3)
+	})
+
+	t.Run("rejects entries that can't be merged", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		a, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		between, err := db.CreateManualEntry("p", "", base.Add(time.Hour), base.Add(2*time.Hour), nil, nil)
+		require.NoError(t, err)
+		b, err := db.CreateManualEntry("p", "", base.Add(2*time.Hour), base.Add(3*time.Hour), nil, nil)
+		require.NoError(t, err)
+		other, err := db.CreateManualEntry("q", "", base.Add(3*time.Hour), base.Add(4*time.Hour), nil, nil)
+		require.NoError(t, err)
+		paid, err := db.CreateManualEntry("p", "", base.Add(4*time.Hour), base.Add(5*time.Hour), floatPtr(50), nil)
+		require.NoError(t, err)
+
+		_, err = db.MergeEntries([]int64{a.ID, b.ID})
+		assert.ErrorContains(t, err, "lies between")
+
+		_, err = db.MergeEntries([]int64{b.ID, other.ID})
+		assert.ErrorContains(t, err, "different projects")
+
+		_, err = db.MergeEntries([]int64{b.ID, paid.ID})
+		assert.ErrorContains(t, err, "hourly rates")
+
+		_, err = db.MergeEntries([]int64{a.ID})
+		assert.Error(t, err)
+
+		_, err = db.MergeEntries([]int64{a.ID, a.ID})
+		assert.Error(t, err)
+
+		entries, err := db.GetEntries(0)
+		require.NoError(t, err)
+		assert.Len(t, entries, 5)
+
+		_, err = db.MergeEntries([]int64{a.ID, between.ID, b.ID})
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects entries of other projects in between", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		a, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		other, err := db.CreateManualEntry("q", "", base.Add(90*time.Minute), base.Add(2*time.Hour), nil, nil)
+		require.NoError(t, err)
+		b, err := db.CreateManualEntry("p", "", base.Add(3*time.Hour), base.Add(4*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		_, err = db.MergeEntries([]int64{a.ID, b.ID})
+		assert.ErrorContains(t, err, fmt.Sprintf("entry #%d of q lies between", other.ID))
+
+		entries, err := db.GetEntries(0)
+		require.NoError(t, err)
+		assert.Len(t, entries, 3)
+	})
+
+	t.Run("follows the overlap policy", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		a, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		b, err := db.CreateManualEntry("p", "", base.Add(time.Hour), base.Add(2*time.Hour), nil, nil)
+		require.NoError(t, err)
+		overlapping, err := db.CreateManualEntry("q", "", base.Add(90*time.Minute), base.Add(3*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		db.rejectOverlaps = true
+		_, err = db.MergeEntries([]int64{a.ID, b.ID})
+		var overlapErr *OverlapError
+		require.ErrorAs(t, err, &overlapErr)
+		require.Len(t, overlapErr.Conflicts, 1)
+		assert.Equal(t, overlapping.ID, overlapErr.Conflicts[0].ID)
+
+		db.rejectOverlaps = false
+		_, err = db.MergeEntries([]int64{a.ID, b.ID})
+		assert.NoError(t, err)
+	})
+}
+
+func TestDuplicateEntry(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	milestone := "Sprint"
+
+	entry, err := db.CreateManualEntry("p", "standup", base, base.Add(15*time.Minute), floatPtr(60), &milestone)
+	require.NoError(t, err)
+
+	next := base.AddDate(0, 0, 1)
+	duplicate, err := db.DuplicateEntry(entry.ID, next, next.Add(15*time.Minute))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, entry.ID, duplicate.ID)
+	assert.True(t, next.Equal(duplicate.StartTime))
+	assert.Equal(t, "standup", duplicate.Description)
+	assert.Equal(t, 60.0, *duplicate.HourlyRate)
+	assert.Equal(t, "Sprint", *duplicate.MilestoneName)
+	assert.Equal(t, SourceDuplicate, duplicate.Source)
+
+	_, err = db.DuplicateEntry(999, next, next.Add(time.Hour))
+	assert.Error(t, err)
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kinds of time off.
+const (
+	TimeOffHoliday  = "holiday"
+	TimeOffVacation = "vacation"
+	TimeOffSick     = "sick"
+)
+
+// TimeOffKinds lists the kinds of time off in the order they are shown.
+var TimeOffKinds = []string{TimeOffHoliday, TimeOffVacation, TimeOffSick}
+
+// dayLayout is how the days of time off are stored. Days are calendar dates,
+// so they stay the same day whatever the timezone.
+const dayLayout = "2006-01-02"
+
+// TimeOff is a day off in the time-off calendar.
+type TimeOff struct {
+	// ID is zero for days off that aren't stored, such as those read from
+	// the holidays file.
+	ID int64
+	// Day is the start of the day off, in the local timezone.
+	Day  time.Time
+	Kind string
+	Name string
+}
+
+// Label returns the name of the day off, or its kind when it has none.
+func (t *TimeOff) Label() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return strings.ToUpper(t.Kind[:1]) + t.Kind[1:]
+}
+
+// ValidateTimeOffKind checks that kind is one of TimeOffKinds.
+func ValidateTimeOffKind(kind string) error {
+	for _, known := range TimeOffKinds {
+		if kind == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown type of time off '%s', use %s", kind, strings.Join(TimeOffKinds, ", "))
+}
+
+// AddTimeOff adds days to the time-off calendar as one operation. A day that
+// is already off is replaced. It returns how many days were new.
+func (d *Database) AddTimeOff(days []*TimeOff) (int, error) {
+	for _, day := range days {
+		if err := ValidateTimeOffKind(day.Kind); err != nil {
+			return 0, err
+		}
+	}
+
+	added := 0
+	err := d.record(OpTimeOffAdd, func(j *journal) (string, error) {
+		for _, day := range days {
+			key := day.Day.Format(dayLayout)
+
+			var id int64
+			err := j.tx.QueryRow("SELECT id FROM time_off WHERE day = ?", key).Scan(&id)
+			switch {
+			case err == sql.ErrNoRows:
+				result, err := j.tx.Exec("INSERT INTO time_off (day, kind, name) VALUES (?, ?, ?)", key, day.Kind, day.Name)
+				if err != nil {
+					return "", fmt.Errorf("failed to add time off: %w", err)
+				}
+
+				id, err = result.LastInsertId()
+				if err != nil {
+					return "", fmt.Errorf("failed to get last insert id: %w", err)
+				}
+
+				j.created(tableTimeOff, id)
+				added++
+
+			case err != nil:
+				return "", fmt.Errorf("failed to read time off: %w", err)
+
+			default:
+				if _, err := j.track(tableTimeOff, id); err != nil {
+					return "", err
+				}
+
+				if _, err := j.tx.Exec("UPDATE time_off SET kind = ?, name = ? WHERE id = ?", day.Kind, day.Name, id); err != nil {
+					return "", fmt.Errorf("failed to update time off: %w", err)
+				}
+			}
+		}
+
+		if len(days) == 1 {
+			return fmt.Sprintf("Added %s on %s", days[0].Kind, days[0].Day.Format(dayLayout)), nil
+		}
+		return fmt.Sprintf("Added %d days off", len(days)), nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return added, nil
+}
+
+// RemoveTimeOff removes the days off between start and end from the
+// time-off calendar as one operation. It returns how many were removed.
+func (d *Database) RemoveTimeOff(start, end time.Time) (int, error) {
+	days, err := d.GetTimeOff(start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(days) > 0 {
+		if _, err := d.Snapshot("time-off"); err != nil {
+			return 0, err
+		}
+	}
+
+	err = d.record(OpTimeOffRemove, func(j *journal) (string, error) {
+		for _, day := range days {
+			if _, err := j.track(tableTimeOff, day.ID); err != nil {
+				return "", err
+			}
+
+			if _, err := j.tx.Exec("DELETE FROM time_off WHERE id = ?", day.ID); err != nil {
+				return "", fmt.Errorf("failed to remove time off: %w", err)
+			}
+		}
+
+		if len(days) == 1 {
+			return fmt.Sprintf("Removed %s on %s", days[0].Kind, days[0].Day.Format(dayLayout)), nil
+		}
+		return fmt.Sprintf("Removed %d days off", len(days)), nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(days), nil
+}
+
+// GetTimeOff returns the days off from start up to end, in order. A zero
+// start or end leaves that side open.
+func (d *Database) GetTimeOff(start, end time.Time) ([]*TimeOff, error) {
+	query := "SELECT id, day, kind, name FROM time_off WHERE 1 = 1"
+	var args []any
+
+	if !start.IsZero() {
+		query += " AND day >= ?"
+		args = append(args, start.Format(dayLayout))
+	}
+
+	if !end.IsZero() {
+		query += " AND day < ?"
+		args = append(args, end.Format(dayLayout))
+	}
+
+	rows, err := d.db.Query(query+" ORDER BY day", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time off: %w", err)
+	}
+	defer rows.Close()
+
+	var days []*TimeOff
+	for rows.Next() {
+		var day TimeOff
+		var date string
+		var name sql.NullString
+
+		if err := rows.Scan(&day.ID, &date, &day.Kind, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan time off: %w", err)
+		}
+
+		day.Day, err = time.ParseInLocation(dayLayout, date, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid day of time off #%d: %w", day.ID, err)
+		}
+		day.Name = name.String
+
+		days = append(days, &day)
+	}
+
+	return days, rows.Err()
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeOff(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 12, d, 0, 0, 0, 0, time.Local)
+	}
+
+	t.Run("add, replace and list", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		added, err := db.AddTimeOff([]*TimeOff{
+			{Day: day(25), Kind: TimeOffHoliday, Name: "Christmas Day"},
+			{Day: day(24), Kind: TimeOffVacation},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, added)
+
+		added, err = db.AddTimeOff([]*TimeOff{{Day: day(24), Kind: TimeOffSick}})
+		require.NoError(t, err)
+		assert.Equal(t, 0, added, "an existing day is replaced")
+
+		days, err := db.GetTimeOff(time.Time{}, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, days, 2)
+		assert.True(t, days[0].Day.Equal(day(24)))
+		assert.Equal(t, TimeOffSick, days[0].Kind)
+		assert.Equal(t, "Sick", days[0].Label())
+		assert.Equal(t, "Christmas Day", days[1].Label())
+
+		days, err = db.GetTimeOff(day(25), day(26))
+		require.NoError(t, err)
+		require.Len(t, days, 1)
+		assert.Equal(t, TimeOffHoliday, days[0].Kind)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		_, err := db.AddTimeOff([]*TimeOff{{Day: day(24), Kind: "party"}})
+		assert.ErrorContains(t, err, "unknown type of time off")
+	})
+
+	t.Run("remove and undo", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		_, err := db.AddTimeOff([]*TimeOff{
+			{Day: day(24), Kind: TimeOffVacation},
+			{Day: day(28), Kind: TimeOffVacation},
+			{Day: day(31), Kind: TimeOffHoliday},
+		})
+		require.NoError(t, err)
+
+		removed, err := db.RemoveTimeOff(day(24), day(31))
+		require.NoError(t, err)
+		assert.Equal(t, 2, removed)
+
+		days, err := db.GetTimeOff(time.Time{}, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, days, 1)
+
+		op, err := db.Undo()
+		require.NoError(t, err)
+		require.NotNil(t, op)
+		assert.Equal(t, OpTimeOffRemove, op.Kind)
+		assert.Equal(t, "time off", op.Changes[0].Subject())
+
+		days, err = db.GetTimeOff(time.Time{}, time.Time{})
+		require.NoError(t, err)
+		assert.Len(t, days, 3)
+	})
+}
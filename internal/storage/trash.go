@@ -0,0 +1,328 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// deletedLinks records what deleting a milestone changed besides the
+// milestone itself, so restoring it can change it back.
+type deletedLinks struct {
+	// Untagged are the entries whose milestone was cleared.
+	Untagged []int64 `json:"untagged,omitempty"`
+	// Trashed are the entries moved to the trash with the milestone.
+	Trashed []int64 `json:"trashed,omitempty"`
+	// Children are the nested milestones moved up a level.
+	Children []int64 `json:"children,omitempty"`
+}
+
+// saveDeletedLinks stores links with a trashed milestone. The caller must
+// have tracked it.
+func saveDeletedLinks(j *journal, id int64, links deletedLinks) error {
+	data, err := json.Marshal(links)
+	if err != nil {
+		return fmt.Errorf("failed to encode deleted milestone: %w", err)
+	}
+
+	if _, err := j.tx.Exec("UPDATE milestones SET deleted_links = ? WHERE id = ?", string(data), id); err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	}
+
+	return nil
+}
+
+// trashEntry marks an entry as deleted. The caller must have tracked it.
+func trashEntry(j *journal, id int64) error {
+	_, err := j.tx.Exec("UPDATE time_entries SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrashedEntries returns the entries in the trash, most recently deleted
+// first.
+func (d *Database) GetTrashedEntries() ([]*TimeEntry, error) {
+	rows, err := d.db.Query(`
+		SELECT ` + entryColumns + `
+		FROM time_entries
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %w", err)
+	}
+
+	return collectEntries(rows)
+}
+
+// GetTrashedMilestones returns the milestones in the trash, most recently
+// deleted first.
+func (d *Database) GetTrashedMilestones() ([]*Milestone, error) {
+	rows, err := d.db.Query(`
+		SELECT ` + milestoneColumns + `
+		FROM milestones
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %w", err)
+	}
+
+	return collectMilestones(rows)
+}
+
+// RestoreEntry takes an entry back out of the trash.
+func (d *Database) RestoreEntry(id int64) error {
+	return d.record(OpRestore, func(j *journal) (string, error) {
+		before, err := j.track(tableEntries, id)
+		if err != nil {
+			return "", err
+		}
+
+		if before == nil || before["deleted_at"] == nil {
+			return "", fmt.Errorf("entry #%d is not in the trash", id)
+		}
+
+		if before["end_time"] == nil {
+			if err := checkNoneRunning(j, id); err != nil {
+				return "", err
+			}
+		}
+
+		if _, err := j.tx.Exec("UPDATE time_entries SET deleted_at = NULL WHERE id = ?", id); err != nil {
+			return "", fmt.Errorf("failed to restore entry: %w", err)
+		}
+
+		return fmt.Sprintf("Restored entry #%d in %s", id, before.text("project_name")), nil
+	})
+}
+
+// checkNoneRunning fails when another entry is running, since the running
+// entry id can't be restored next to it.
+func checkNoneRunning(j *journal, id int64) error {
+	var running int
+	if err := j.tx.QueryRow("SELECT COUNT(*) FROM time_entries WHERE end_time IS NULL AND deleted_at IS NULL").Scan(&running); err != nil {
+		return fmt.Errorf("failed to check running entry: %w", err)
+	}
+
+	if running > 0 {
+		return fmt.Errorf("entry #%d was running and another entry is running now; stop it first", id)
+	}
+
+	return nil
+}
+
+// RestoreMilestone takes a milestone back out of the trash, along with what
+// deleting it changed: its entries are tagged again, or taken out of the
+// trash, and its nested milestones move back under it. Anything changed since
+// the deletion is left alone. A milestone that was active when it was deleted
+// can only come back if it could be started again: its parent is active and
+// has no other active child.
+func (d *Database) RestoreMilestone(id int64) error {
+	return d.record(OpRestore, func(j *journal) (string, error) {
+		before, err := j.track(tableMilestones, id)
+		if err != nil {
+			return "", err
+		}
+
+		if before == nil || before["deleted_at"] == nil {
+			return "", fmt.Errorf("milestone #%d is not in the trash", id)
+		}
+
+		milestone, err := scanMilestone(j.tx.QueryRow("SELECT "+milestoneColumns+" FROM milestones WHERE id = ?", id))
+		if err != nil {
+			return "", fmt.Errorf("failed to get milestone: %w", err)
+		}
+
+		if _, err := j.tx.Exec("UPDATE milestones SET deleted_at = NULL, deleted_links = NULL WHERE id = ?", id); err != nil {
+			return "", fmt.Errorf("failed to restore milestone: %w", err)
+		}
+
+		var links deletedLinks
+		if data := before["deleted_links"]; data != nil {
+			if err := json.Unmarshal([]byte(*data), &links); err != nil {
+				return "", fmt.Errorf("failed to decode deleted milestone: %w", err)
+			}
+		}
+
+		// an active child that moved up a level is no longer a sibling once
+		// it is back under the milestone
+		if err := restoreChildren(j, milestone, links.Children); err != nil {
+			return "", err
+		}
+
+		if milestone.IsActive() {
+			if err := checkCanActivate(j.tx, milestone.ProjectName, milestone.ParentID, id); err != nil {
+				return "", fmt.Errorf("milestone '%s' was active when it was deleted: %w", milestone.Name, err)
+			}
+		}
+
+		restored, err := restoreMilestoneEntries(j, milestone, links)
+		if err != nil {
+			return "", err
+		}
+
+		description := fmt.Sprintf("Restored milestone %s in %s", milestone.Name, milestone.ProjectName)
+		if restored > 0 {
+			description += fmt.Sprintf(" with %d entries", restored)
+		}
+
+		return description, nil
+	})
+}
+
+// restoreChildren moves nested milestones back under a restored milestone,
+// unless they have been moved elsewhere since, or are active under a
+// finished milestone.
+func restoreChildren(j *journal, milestone *Milestone, children []int64) error {
+	parent := ""
+	if milestone.ParentID != nil {
+		parent = fmt.Sprintf("%d", *milestone.ParentID)
+	}
+
+	for _, childID := range children {
+		child, err := j.track(tableMilestones, childID)
+		if err != nil {
+			return err
+		}
+
+		if child == nil || child.text("parent_id") != parent || child.text("project_name") != milestone.ProjectName {
+			continue
+		}
+
+		if child["end_time"] == nil && child["deleted_at"] == nil && !milestone.IsActive() {
+			continue
+		}
+
+		if _, err := j.tx.Exec("UPDATE milestones SET parent_id = ? WHERE id = ?", milestone.ID, childID); err != nil {
+			return fmt.Errorf("failed to restore nested milestone: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// restoreMilestoneEntries tags the untagged entries of a restored milestone
+// again and takes those deleted with it out of the trash, skipping entries
+// that changed project, milestone or trash state since. It returns how many
+// entries were restored.
+func restoreMilestoneEntries(j *journal, milestone *Milestone, links deletedLinks) (int, error) {
+	restored := 0
+
+	for _, entryID := range slices.Concat(links.Untagged, links.Trashed) {
+		trashed := slices.Contains(links.Trashed, entryID)
+
+		entry, err := j.track(tableEntries, entryID)
+		if err != nil {
+			return 0, err
+		}
+
+		if entry == nil || entry.text("project_name") != milestone.ProjectName {
+			continue
+		}
+
+		if trashed {
+			if entry["deleted_at"] == nil || entry.text("milestone_name") != milestone.Name {
+				continue
+			}
+
+			if entry["end_time"] == nil {
+				if err := checkNoneRunning(j, entryID); err != nil {
+					return 0, err
+				}
+			}
+
+			_, err = j.tx.Exec("UPDATE time_entries SET deleted_at = NULL WHERE id = ?", entryID)
+		} else {
+			if entry["milestone_name"] != nil {
+				continue
+			}
+
+			_, err = j.tx.Exec("UPDATE time_entries SET milestone_name = ? WHERE id = ?", milestone.Name, entryID)
+		}
+
+		if err != nil {
+			return 0, fmt.Errorf("failed to restore milestone entry: %w", err)
+		}
+		restored++
+	}
+
+	return restored, nil
+}
+
+// EmptyTrash permanently removes everything in the trash, after taking a
+// snapshot. It returns how many entries and milestones were removed.
+func (d *Database) EmptyTrash() (int, int, error) {
+	if _, err := d.Snapshot("empty-trash"); err != nil {
+		return 0, 0, err
+	}
+
+	var entries, milestones int
+	err := d.record(OpEmptyTrash, func(j *journal) (string, error) {
+		var err error
+
+		if entries, err = purgeTable(j, tableEntries); err != nil {
+			return "", err
+		}
+
+		if milestones, err = purgeTable(j, tableMilestones); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Emptied trash (%d entries, %d milestones)", entries, milestones), nil
+	})
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return entries, milestones, nil
+}
+
+func purgeTable(j *journal, table string) (int, error) {
+	rows, err := j.tx.Query(fmt.Sprintf("SELECT id FROM %s WHERE deleted_at IS NOT NULL", table))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query trash: %w", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan trash: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if _, err := j.track(table, id); err != nil {
+			return 0, err
+		}
+
+		if _, err := j.tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", table), id); err != nil {
+			return 0, fmt.Errorf("failed to empty trash: %w", err)
+		}
+	}
+
+	return len(ids), nil
+}
+
+// purgeTrash permanently removes rows deleted before cutoff. It runs on
+// every start, so it isn't journaled.
+func (d *Database) purgeTrash(cutoff time.Time) error {
+	for _, table := range []string{tableEntries, tableMilestones} {
+		if _, err := d.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE deleted_at IS NOT NULL AND deleted_at < ?", table), cutoff); err != nil {
+			return fmt.Errorf("failed to purge trash: %w", err)
+		}
+	}
+
+	return nil
+}
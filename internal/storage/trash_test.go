@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+
+	t.Run("deleted entries are hidden but kept", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		kept, err := db.CreateManualEntry("kept", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		deleted, err := db.CreateManualEntry("gone", "", base.Add(time.Hour), base.Add(2*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, db.DeleteTimeEntry(deleted.ID))
+
+		entries, err := db.GetEntries(0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, kept.ID, entries[0].ID)
+
+		projects, err := db.GetAllProjects()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kept"}, projects)
+
+		count, err := db.CountEntries(EntryFilter{Deleted: true})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		trashed, err := db.GetTrashedEntries()
+		require.NoError(t, err)
+		require.Len(t, trashed, 1)
+		assert.Equal(t, deleted.ID, trashed[0].ID)
+		assert.NotNil(t, trashed[0].DeletedAt)
+
+		// deleted entries don't count as overlaps
+		end := base.Add(2 * time.Hour)
+		conflicts, err := db.FindOverlappingEntries(base.Add(time.Hour), &end, 0)
+		require.NoError(t, err)
+		assert.Empty(t, conflicts)
+	})
+
+	t.Run("bulk delete is undone as one operation", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		var ids []int64
+		for i := 0; i < 3; i++ {
+			start := base.Add(time.Duration(i) * time.Hour)
+			entry, err := db.CreateManualEntry("p", "", start, start.Add(time.Hour), nil, nil)
+			require.NoError(t, err)
+			ids = append(ids, entry.ID)
+		}
+
+		require.NoError(t, db.DeleteTimeEntries(ids[:2]))
+
+		count, err := db.CountEntries(EntryFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		op, err := db.Undo()
+		require.NoError(t, err)
+		require.NotNil(t, op)
+		assert.Equal(t, OpDelete, op.Kind)
+		assert.Len(t, op.Changes, 2)
+
+		count, err = db.CountEntries(EntryFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("restore entry", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		assert.Error(t, db.RestoreEntry(entry.ID), "not in the trash yet")
+
+		require.NoError(t, db.DeleteTimeEntry(entry.ID))
+		require.NoError(t, db.RestoreEntry(entry.ID))
+
+		got, err := db.GetEntry(entry.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.DeletedAt)
+
+		trashed, err := db.GetTrashedEntries()
+		require.NoError(t, err)
+		assert.Empty(t, trashed)
+	})
+
+	t.Run("restoring a running entry requires no other running entry", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		first, err := db.CreateEntry("p", "", nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, db.DeleteTimeEntry(first.ID))
+
+		second, err := db.CreateEntry("p", "", nil, nil)
+		require.NoError(t, err)
+
+		assert.Error(t, db.RestoreEntry(first.ID))
+
+		require.NoError(t, db.StopEntry(second.ID))
+		assert.NoError(t, db.RestoreEntry(first.ID))
+	})
+
+	t.Run("restore milestone", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		milestone, err := db.CreateMilestone("p", "Sprint")
+		require.NoError(t, err)
+		_, err = db.db.Exec("UPDATE milestones SET deleted_at = ? WHERE id = ?", time.Now(), milestone.ID)
+		require.NoError(t, err)
+
+		active, err := db.GetActiveMilestoneForProject("p")
+		require.NoError(t, err)
+		assert.Nil(t, active)
+
+		trashed, err := db.GetTrashedMilestones()
+		require.NoError(t, err)
+		require.Len(t, trashed, 1)
+		assert.Equal(t, "Sprint", trashed[0].Name)
+
+		require.NoError(t, db.RestoreMilestone(milestone.ID))
+
+		active, err = db.GetActiveMilestoneForProject("p")
+		require.NoError(t, err)
+		require.NotNil(t, active)
+	})
+
+	t.Run("restoring an active milestone requires no other active milestone", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		first, err := db.CreateMilestone("p", "Sprint 1")
+		require.NoError(t, err)
+		_, err = db.DeleteMilestone(first.ID, false)
+		require.NoError(t, err)
+
+		second, err := db.CreateMilestone("p", "Sprint 2")
+		require.NoError(t, err)
+
+		assert.ErrorContains(t, db.RestoreMilestone(first.ID), "Sprint 2")
+
+		require.NoError(t, db.FinishMilestone(second.ID))
+		require.NoError(t, db.RestoreMilestone(first.ID))
+
+		active, err := db.GetActiveMilestoneForProject("p")
+		require.NoError(t, err)
+		require.NotNil(t, active)
+		assert.Equal(t, first.ID, active.ID)
+	})
+
+	t.Run("restoring a milestone retags its entries and nests its children again", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		parent, err := db.CreateMilestone("p", "Release")
+		require.NoError(t, err)
+		child, err := db.CreateMilestoneWithPlan("p", "Sprint", &parent.ID, MilestonePlan{})
+		require.NoError(t, err)
+
+		entry, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, &parent.Name)
+		require.NoError(t, err)
+
+		changed, err := db.DeleteMilestone(parent.ID, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, changed)
+
+		untagged, err := db.GetEntry(entry.ID)
+		require.NoError(t, err)
+		assert.Nil(t, untagged.MilestoneName)
+
+		require.NoError(t, db.RestoreMilestone(parent.ID))
+
+		retagged, err := db.GetEntry(entry.ID)
+		require.NoError(t, err)
+		require.NotNil(t, retagged.MilestoneName)
+		assert.Equal(t, "Release", *retagged.MilestoneName)
+
+		nested, err := db.GetMilestone(child.ID)
+		require.NoError(t, err)
+		require.NotNil(t, nested.ParentID)
+		assert.Equal(t, parent.ID, *nested.ParentID)
+	})
+
+	t.Run("restoring a milestone deleted with its entries restores them", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		milestone, err := db.CreateMilestone("p", "Sprint")
+		require.NoError(t, err)
+		entry, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, &milestone.Name)
+		require.NoError(t, err)
+		// retagged after the deletion, so it stays where it is
+		moved, err := db.CreateManualEntry("p", "", base.Add(time.Hour), base.Add(2*time.Hour), nil, &milestone.Name)
+		require.NoError(t, err)
+
+		_, err = db.DeleteMilestone(milestone.ID, true)
+		require.NoError(t, err)
+		require.NoError(t, db.RestoreEntry(moved.ID))
+		_, err = db.db.Exec("UPDATE time_entries SET milestone_name = NULL WHERE id = ?", moved.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, db.RestoreMilestone(milestone.ID))
+
+		restored, err := db.GetEntry(entry.ID)
+		require.NoError(t, err)
+		require.NotNil(t, restored.MilestoneName)
+		assert.Equal(t, "Sprint", *restored.MilestoneName)
+
+		untouched, err := db.GetEntry(moved.ID)
+		require.NoError(t, err)
+		assert.Nil(t, untouched.MilestoneName)
+
+		trashed, err := db.GetTrashedEntries()
+		require.NoError(t, err)
+		assert.Empty(t, trashed)
+	})
+
+	t.Run("empty trash can be undone", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		entry, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, db.DeleteTimeEntry(entry.ID))
+
+		entries, milestones, err := db.EmptyTrash()
+		require.NoError(t, err)
+		assert.Equal(t, 1, entries)
+		assert.Equal(t, 0, milestones)
+
+		count, err := db.CountEntries(EntryFilter{Deleted: true})
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		_, err = db.Undo()
+		require.NoError(t, err)
+
+		trashed, err := db.GetTrashedEntries()
+		require.NoError(t, err)
+		assert.Len(t, trashed, 1)
+	})
+
+	t.Run("purge removes only old deletions", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		old, err := db.CreateManualEntry("p", "", base, base.Add(time.Hour), nil, nil)
+		require.NoError(t, err)
+		recent, err := db.CreateManualEntry("p", "", base.Add(time.Hour), base.Add(2*time.Hour), nil, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, db.DeleteTimeEntry(recent.ID))
+		_, err = db.db.Exec("UPDATE time_entries SET deleted_at = ? WHERE id = ?", time.Now().AddDate(0, 0, -40), old.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, db.purgeTrash(time.Now().AddDate(0, 0, -30)))
+
+		trashed, err := db.GetTrashedEntries()
+		require.NoError(t, err)
+		require.Len(t, trashed, 1)
+		assert.Equal(t, recent.ID, trashed[0].ID)
+	})
+}
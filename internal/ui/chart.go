@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// Unicode reports whether charts are drawn with Unicode block characters.
+// It is false when the locale isn't UTF-8, the terminal is dumb or
+// TMPO_ASCII is set, and charts then fall back to plain ASCII.
+var Unicode = supportsUnicode()
+
+func supportsUnicode() bool {
+	if os.Getenv("TMPO_ASCII") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	// the first locale variable that is set decides, as it does for the C library
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := strings.ToLower(os.Getenv(name)); value != "" {
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+
+	return true
+}
+
+var (
+	// eighths of a block, for bars that end part way through a cell
+	barEighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+	sparkUnicode = []string{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+	sparkASCII   = []string{"_", ".", "-", "~", "=", "+", "*", "#"}
+
+	heatUnicode = []string{"·", "░", "▒", "▓", "█"}
+	heatASCII   = []string{".", "-", "+", "*", "#"}
+)
+
+// Bar draws fraction (1.0 is full) as a bar of the given width, padded with
+// spaces so bars line up.
+func Bar(fraction float64, width int) string {
+	fraction = math.Max(0, math.Min(fraction, 1))
+
+	if !Unicode {
+		filled := int(math.Round(fraction * float64(width)))
+		return strings.Repeat("#", filled) + strings.Repeat(" ", width-filled)
+	}
+
+	eighths := int(math.Round(fraction * float64(width) * 8))
+	bar := strings.Repeat("█", eighths/8) + barEighths[eighths%8]
+
+	cells := eighths / 8
+	if eighths%8 > 0 {
+		cells++
+	}
+
+	return bar + strings.Repeat(" ", width-cells)
+}
+
+// Sparkline draws one character per value, scaled to the largest value.
+// Zero values are left blank so days without time stand out.
+func Sparkline(values []float64) string {
+	levels := sparkUnicode
+	if !Unicode {
+		levels = sparkASCII
+	}
+
+	var highest float64
+	for _, value := range values {
+		highest = math.Max(highest, value)
+	}
+
+	var b strings.Builder
+	for _, value := range values {
+		if value <= 0 || highest == 0 {
+			b.WriteString(" ")
+			continue
+		}
+
+		level := int(math.Ceil(value/highest*float64(len(levels)))) - 1
+		b.WriteString(levels[max(0, min(level, len(levels)-1))])
+	}
+
+	return b.String()
+}
+
+// Heatmap draws a calendar of the days in [start, end) with one column per
+// week and one row per weekday, starting on firstWeekday, shaded by value
+// relative to the busiest day. It returns the lines to print, starting with
+// the month labels and ending with a legend.
+func Heatmap(start, end time.Time, firstWeekday time.Weekday, value func(day time.Time) float64) []string {
+	levels := heatUnicode
+	if !Unicode {
+		levels = heatASCII
+	}
+
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	offset := (int(start.Weekday()) - int(firstWeekday) + 7) % 7
+	first := start.AddDate(0, 0, -offset)
+
+	var weeks int
+	var highest float64
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		highest = math.Max(highest, value(day))
+		weeks = int(math.Round(day.Sub(first).Hours()/24))/7 + 1
+	}
+
+	// month names above the week each month starts in
+	header := []rune(strings.Repeat(" ", weeks+8))
+	for week := range weeks {
+		weekStart := first.AddDate(0, 0, 7*week)
+		for d := range 7 {
+			day := weekStart.AddDate(0, 0, d)
+			if day.Day() == 1 && !day.Before(start) && day.Before(end) {
+				copy(header[4+week:], []rune(day.Format("Jan")))
+			}
+		}
+	}
+
+	lines := []string{Muted(strings.TrimRight(string(header), " "))}
+
+	for row := range 7 {
+		weekday := time.Weekday((int(firstWeekday) + row) % 7)
+
+		label := "    "
+		if row%2 == 1 {
+			label = weekday.String()[:3] + " "
+		}
+
+		var b strings.Builder
+		b.WriteString(Muted(label))
+		for week := range weeks {
+			day := first.AddDate(0, 0, 7*week+row)
+			if day.Before(start) || !day.Before(end) {
+				b.WriteString(" ")
+				continue
+			}
+
+			level := 0
+			if v := value(day); v > 0 && highest > 0 {
+				level = max(1, int(math.Ceil(v/highest*float64(len(levels)-1))))
+			}
+
+			if level == 0 {
+				b.WriteString(Muted(levels[0]))
+			} else {
+				b.WriteString(Success(levels[level]))
+			}
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+
+	legend := make([]string, len(levels))
+	for i, level := range levels {
+		if i == 0 {
+			legend[i] = Muted(level)
+		} else {
+			legend[i] = Success(level)
+		}
+	}
+	lines = append(lines, Muted("    Less ")+strings.Join(legend, " ")+Muted(" More"))
+
+	return lines
+}
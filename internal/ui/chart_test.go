@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withUnicode sets Unicode until the end of the test.
+func withUnicode(t *testing.T, unicode bool) {
+	previous := Unicode
+	Unicode = unicode
+	t.Cleanup(func() { Unicode = previous })
+}
+
+func TestSupportsUnicode(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{name: "no locale", env: map[string]string{}, want: true},
+		{name: "utf-8 locale", env: map[string]string{"LANG": "en_US.UTF-8"}, want: true},
+		{name: "C locale", env: map[string]string{"LANG": "C"}, want: false},
+		{name: "LC_ALL wins", env: map[string]string{"LC_ALL": "POSIX", "LANG": "en_US.UTF-8"}, want: false},
+		{name: "dumb terminal", env: map[string]string{"TERM": "dumb"}, want: false},
+		{name: "forced ASCII", env: map[string]string{"TMPO_ASCII": "1", "LANG": "C.utf8"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"TMPO_ASCII", "TERM", "LC_ALL", "LC_CTYPE", "LANG"} {
+				t.Setenv(name, tt.env[name])
+			}
+			assert.Equal(t, tt.want, supportsUnicode())
+		})
+	}
+}
+
+func TestBar(t *testing.T) {
+	withUnicode(t, true)
+	assert.Equal(t, "█████     ", Bar(0.5, 10))
+	assert.Equal(t, "██▌       ", Bar(0.25, 10))
+	assert.Equal(t, "          ", Bar(0, 10))
+	assert.Equal(t, "██████████", Bar(1.5, 10), "bars don't overflow")
+
+	withUnicode(t, false)
+	assert.Equal(t, "###       ", Bar(0.25, 10))
+}
+
+func TestSparkline(t *testing.T) {
+	withUnicode(t, true)
+	assert.Equal(t, "▁▄ █", Sparkline([]float64{1, 4, 0, 8}))
+	assert.Equal(t, "   ", Sparkline([]float64{0, 0, 0}))
+
+	withUnicode(t, false)
+	assert.Equal(t, "_~ #", Sparkline([]float64{1, 4, 0, 8}))
+}
+
+func TestHeatmap(t *testing.T) {
+	withUnicode(t, false)
+
+	// Monday 1 January to Sunday 14 January 2024
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 14)
+	value := func(day time.Time) float64 {
+		if day.Day() == 3 {
+			return 4
+		}
+		if day.Day() == 10 {
+			return 1
+		}
+		return 0
+	}
+
+	lines := Heatmap(start, end, time.Monday, value)
+	require.Len(t, lines, 9, "month labels, seven weekdays and a legend")
+	assert.Contains(t, lines[0], "Jan")
+
+	strip := func(s string) string {
+		for _, code := range []string{ColorGray, ColorGreen, ColorReset} {
+			s = strings.ReplaceAll(s, code, "")
+		}
+		return s
+	}
+
+	assert.Equal(t, "    ..", strip(lines[1]), "Mondays")
+	assert.Equal(t, "Tue ..", strip(lines[2]))
+	assert.Equal(t, "    #-", strip(lines[3]), "Wednesdays, the busiest first")
+	assert.Contains(t, strip(lines[8]), "Less . - + * # More")
+}
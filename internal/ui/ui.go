@@ -2,8 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"strings"
 	"time"
+
+	"github.com/mattn/go-isatty"
 )
 
 // ANSI Color Constants
@@ -41,6 +45,14 @@ const (
 	EmojiInit      = "⚙️"
 	EmojiExport    = "📤"
 	EmojiMilestone = "🎯"
+	EmojiBackup    = "💾"
+	EmojiDoctor    = "🩺"
+	EmojiUndo      = "↩️"
+	EmojiRedo      = "↪️"
+	EmojiHistory   = "📜"
+	EmojiTrash     = "🗑️"
+	EmojiGoal      = "🏁"
+	EmojiTimeOff   = "🌴"
 	EmojiSuccess   = "✅"
 	EmojiError     = "❌"
 	EmojiWarning   = "⚠️"
@@ -157,3 +169,56 @@ func FormatDuration(d time.Duration) string {
 
 	return fmt.Sprintf("%ds", seconds)
 }
+
+// IsInteractive reports whether stdin is a terminal, i.e. whether prompts
+// can be shown.
+func IsInteractive() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// IsTerminalOutput reports whether stdout is a terminal rather than a pipe
+// or file.
+func IsTerminalOutput() bool {
+	fd := os.Stdout.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// ProgressBar renders used (1.0 is 100%) as a bar of the given width, with
+// the percentage after it. It turns yellow from 80% and red past 100%.
+func ProgressBar(used float64, width int) string {
+	used = math.Max(used, 0)
+	label := progressLabel(used, width)
+
+	switch {
+	case used > 1:
+		return Error(label)
+	case used >= 0.8:
+		return Warning(label)
+	default:
+		return Success(label)
+	}
+}
+
+// GoalBar renders progress towards a goal (1.0 is met) like ProgressBar, but
+// turns green once the goal is met rather than red past it.
+func GoalBar(progress float64, width int) string {
+	progress = math.Max(progress, 0)
+	label := progressLabel(progress, width)
+
+	if progress >= 1 {
+		return Success(label)
+	}
+	return Info(label)
+}
+
+func progressLabel(fraction float64, width int) string {
+	full, empty := "█", "░"
+	if !Unicode {
+		full, empty = "#", "-"
+	}
+
+	filled := int(math.Round(math.Min(fraction, 1) * float64(width)))
+	bar := strings.Repeat(full, filled) + strings.Repeat(empty, width-filled)
+	return fmt.Sprintf("%s %d%%", bar, int(math.Round(fraction*100)))
+}
@@ -200,3 +200,40 @@ func TestConstants(t *testing.T) {
 		assert.NotEmpty(t, EmojiInfo)
 	})
 }
+
+func TestProgressBar(t *testing.T) {
+	withUnicode(t, true)
+
+	tests := []struct {
+		name    string
+		used    float64
+		bar     string
+		percent string
+		color   string
+	}{
+		{name: "empty", used: 0, bar: "░░░░░░░░░░", percent: "0%", color: ColorGreen},
+		{name: "half", used: 0.5, bar: "█████░░░░░", percent: "50%", color: ColorGreen},
+		{name: "nearly used up", used: 0.85, bar: "█████████░", percent: "85%", color: ColorYellow},
+		{name: "over budget", used: 1.25, bar: "██████████", percent: "125%", color: ColorRed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ProgressBar(tt.used, 10)
+			assert.Contains(t, result, tt.bar+" "+tt.percent)
+			assert.Contains(t, result, tt.color)
+		})
+	}
+}
+
+func TestGoalBar(t *testing.T) {
+	withUnicode(t, true)
+
+	result := GoalBar(0.5, 10)
+	assert.Contains(t, result, "█████░░░░░ 50%")
+	assert.Contains(t, result, ColorBlue)
+
+	result = GoalBar(1.25, 10)
+	assert.Contains(t, result, "██████████ 125%")
+	assert.Contains(t, result, ColorGreen, "going past a goal is good")
+}